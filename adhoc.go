@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// adhocProject is the pseudo project path of a server given on the command line
+const adhocProject = "(command line)"
+
+// adhocURLConfig builds a config holding only the server at the given URL, for
+// inspecting a server that is not in any config file. The server is named
+// after the URL's host.
+func adhocURLConfig(rawURL, transport string) (*ClaudeConfig, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, "", fmt.Errorf("invalid --url %q, expected an http:// or https:// URL", rawURL)
+	}
+	if transport != "http" && transport != "sse" {
+		return nil, "", fmt.Errorf("invalid --transport %q, expected http or sse", transport)
+	}
+
+	name := u.Host
+	return adhocConfig(name, MCPServer{Type: transport, URL: rawURL}), name, nil
+}
+
+// adhocConfig wraps a single server given on the command line in a ClaudeConfig
+func adhocConfig(name string, server MCPServer) *ClaudeConfig {
+	return &ClaudeConfig{
+		Projects: map[string]ProjectConfig{
+			adhocProject: {MCPServers: map[string]MCPServer{name: server}},
+		},
+	}
+}
@@ -0,0 +1,594 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// Severities of audit findings
+const (
+	auditHigh   = "high"
+	auditMedium = "medium"
+	auditLow    = "low"
+)
+
+// auditSeverityRank orders severities for sorting, most severe first
+var auditSeverityRank = map[string]int{auditHigh: 0, auditMedium: 1, auditLow: 2}
+
+// AuditFinding is text from a server that matched a suspicious pattern
+type AuditFinding struct {
+	Server   string `json:"server"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Location string `json:"location"`
+	Match    string `json:"match"`
+	Message  string `json:"message"`
+
+	// scope locates the server's definition for --output github
+	scope string
+}
+
+// AuditReport is the result of auditing one or more servers
+type AuditReport struct {
+	Findings []AuditFinding         `json:"findings"`
+	Packages map[string]*Provenance `json:"packages,omitempty"`
+	Errors   map[string]string      `json:"errors,omitempty"`
+}
+
+// auditRule is a pattern of text that tries to steer the model rather than describe
+type auditRule struct {
+	name     string
+	severity string
+	message  string
+	pattern  *regexp.Regexp
+}
+
+// hiddenUnicode matches zero-width, bidirectional control and tag characters,
+// which render as nothing but are read by the model
+var hiddenUnicode = regexp.MustCompile(`[\x{200B}-\x{200F}\x{202A}-\x{202E}\x{2060}-\x{2064}\x{2066}-\x{2069}\x{FEFF}\x{E0000}-\x{E007F}]+`)
+
+// auditRules are matched against every text a server puts in the model's context
+var auditRules = []auditRule{
+	{
+		name: "instruction-override", severity: auditHigh,
+		message: "tries to override the model's instructions",
+		pattern: regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding|other|system)\s+(instructions|prompts?|messages|rules|directions)`),
+	},
+	{
+		name: "hidden-unicode", severity: auditHigh,
+		message: "contains invisible characters that can hide text from a reviewer",
+		pattern: hiddenUnicode,
+	},
+	{
+		name: "exfiltration", severity: auditHigh,
+		message: "asks to send sensitive data somewhere",
+		pattern: regexp.MustCompile(`(?i)\b(send|upload|post|forward|transmit|exfiltrate|leak|email)\b[^.\n]{0,80}\b(conversation|chat history|credentials?|secrets?|api[ _-]?keys?|access tokens?|tokens|passwords?|private keys?|ssh keys?|environment variables)\b`),
+	},
+	{
+		name: "concealment", severity: auditHigh,
+		message: "asks the model to hide what it does from the user",
+		pattern: regexp.MustCompile(`(?i)\b(do not|don't|never)\s+(tell|mention|inform|reveal|show|notify|alert)\s+(this\s+to\s+)?(the\s+)?user\b|\bwithout\s+(telling|informing|notifying|alerting)\s+(the\s+)?user\b`),
+	},
+	{
+		name: "sensitive-path", severity: auditMedium,
+		message: "mentions a file holding credentials",
+		pattern: regexp.MustCompile(`~/\.ssh\b|\bid_(rsa|ed25519|ecdsa)\b|\.aws/credentials|/etc/(passwd|shadow)\b|\.netrc\b|\.docker/config\.json`),
+	},
+	{
+		name: "role-markup", severity: auditMedium,
+		message: "uses markup that imitates system or priority instructions",
+		pattern: regexp.MustCompile(`(?i)<\s*/?\s*(system|important|instructions?|admin)\s*>|\[\s*(system|inst)\s*\]|<\|im_start\|>`),
+	},
+	{
+		name: "role-change", severity: auditMedium,
+		message: "tries to give the model a new role or new instructions",
+		pattern: regexp.MustCompile(`(?i)\byou\s+are\s+now\b|\bnew\s+instructions\s*:|\bfrom\s+now\s+on,?\s+you\b`),
+	},
+	{
+		name: "tool-redirect", severity: auditMedium,
+		message: "tells the model how to use other tools, which can shadow them",
+		pattern: regexp.MustCompile(`(?i)\b(instead\s+of|rather\s+than|before|after)\s+(using|calling|invoking)\s+(the\s+|any\s+)?[\w.-]+\s+tool\b`),
+	},
+	{
+		name: "encoded-payload", severity: auditLow,
+		message: "contains a long encoded string that may hide instructions",
+		pattern: regexp.MustCompile(`[A-Za-z0-9+/]{80,}={0,2}`),
+	},
+}
+
+func newAuditCmd() *cobra.Command {
+	var all bool
+	auditCmd := &cobra.Command{
+		Use:   "audit [server-name]",
+		Short: "Scan a server's tools, prompts and resources for prompt injection",
+		Long: `Connect to a server and scan the text it puts in the model's context for
+instructions aimed at the model: its instructions, tool names, descriptions
+and input schemas, prompt descriptions and messages, and resource names, URIs
+and descriptions. Each finding has a severity:
+
+  high    overriding instructions, hidden unicode, asking to send credentials
+          or to keep things from the user
+  medium  credential file paths, fake system markup, role changes and
+          directions about other tools
+  low     long encoded strings
+
+The server's definition is also checked for credentials written into its
+command, args, env, headers and URL, like "config validate" does: a high
+finding in a project's shared .mcp.json and a medium one elsewhere.
+
+For servers run with npx, uvx or pipx, the package is looked up on npm or
+PyPI: a deprecated or yanked version or one published in the last week is a
+medium finding, an unpinned version or fewer than 100 downloads a week a low
+one. The packages are listed after the findings; --no-provenance skips them.
+
+Prompts are rendered when they take no required arguments. Use --all to audit
+every configured server. The command exits with code 7 if any high finding
+is made, unless --fail-on leaves out findings.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either a server name or --all")
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			var names []string
+			if all {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			} else {
+				names = args
+			}
+			// Findings are a result, not a usage error
+			cmd.SilenceUsage = true
+			return auditServers(config, names)
+		},
+	}
+	auditCmd.Flags().BoolVar(&all, "all", false, "audit every configured server")
+	return auditCmd
+}
+
+func auditServers(config *ClaudeConfig, names []string) error {
+	findings := make([][]AuditFinding, len(names))
+	packages := make([]*Provenance, len(names))
+	errs := make([]error, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		findings[i], packages[i], errs[i] = auditServer(config, names[i])
+	})
+
+	report := &AuditReport{Findings: []AuditFinding{}}
+	var unreachable []error
+	for i, name := range names {
+		report.Findings = append(report.Findings, findings[i]...)
+		if packages[i] != nil {
+			if report.Packages == nil {
+				report.Packages = make(map[string]*Provenance)
+			}
+			report.Packages[name] = packages[i]
+		}
+		if errs[i] != nil {
+			unreachable = append(unreachable, errs[i])
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = errs[i].Error()
+		}
+	}
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity != b.Severity {
+			return auditSeverityRank[a.Severity] < auditSeverityRank[b.Severity]
+		}
+		return a.Server < b.Server
+	})
+
+	if err := printAudit(report, len(names)); err != nil {
+		return err
+	}
+	if high := report.count(auditHigh); high > 0 && failsOn(failFindings, true) {
+		return withExitCode(exitFindings, fmt.Errorf("%d high severity findings", high))
+	}
+	if len(unreachable) > 0 && failsOn(failUnreachable, true) {
+		return withExitCode(failureExitCode(unreachable), fmt.Errorf("%d of %d servers unreachable", len(unreachable), len(names)))
+	}
+	return nil
+}
+
+// count returns the number of findings of a severity
+func (r *AuditReport) count(severity string) int {
+	n := 0
+	for _, finding := range r.Findings {
+		if finding.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// auditText is one piece of text a server exposes, with where it came from
+type auditText struct {
+	location string
+	text     string
+}
+
+// auditServer scans a server's definition for credentials and looks up its
+// package, then connects, collects the text it exposes and scans it. The
+// definition's and package's findings are returned even when the server
+// cannot be reached.
+func auditServer(config *ClaudeConfig, serverName string) ([]AuditFinding, *Provenance, error) {
+	server, err := findServer(config, serverName)
+	if err != nil {
+		return nil, nil, err
+	}
+	findings := secretFindings(serverName, *server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	provenance := lookupProvenance(ctx, *server)
+	findings = append(findings, provenanceFindings(serverName, server.Scope, provenance)...)
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return findings, provenance, err
+	}
+	defer conn.Close()
+
+	texts, err := collectAuditTexts(ctx, conn)
+	if err != nil {
+		return findings, provenance, err
+	}
+	for _, finding := range scanTexts(serverName, texts) {
+		finding.scope = server.Scope
+		findings = append(findings, finding)
+	}
+	return findings, provenance, nil
+}
+
+// secretFindings reports the credentials written into a server definition,
+// as high findings in a project's shared config, which is meant to be committed
+func secretFindings(serverName string, server MCPServer) []AuditFinding {
+	var findings []AuditFinding
+	for _, secret := range findSecrets(server) {
+		finding := AuditFinding{
+			Server:   serverName,
+			Severity: auditMedium,
+			Rule:     "secret",
+			Location: "config " + secret.location,
+			Match:    secret.value,
+			Message:  "holds " + secret.kind,
+			scope:    server.Scope,
+		}
+		if server.Scope == scopeProject {
+			finding.Severity = auditHigh
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// collectAuditTexts gathers the text of every surface the server advertises
+func collectAuditTexts(ctx context.Context, conn *Connection) ([]auditText, error) {
+	var texts []auditText
+	add := func(location, text string) {
+		if text != "" {
+			texts = append(texts, auditText{location: location, text: text})
+		}
+	}
+	deref := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+
+	add("instructions", conn.Instructions())
+	capabilities := conn.Capabilities()
+
+	if _, ok := capabilities[surfaceTools]; ok {
+		tools, err := listTools(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+		for _, tool := range tools {
+			prefix := "tool " + tool.Name
+			add(prefix+" name", tool.Name)
+			add(prefix+" description", tool.Description)
+			walkSchemaStrings(tool.InputSchema, "inputSchema", func(path, s string) {
+				add(prefix+" "+path, s)
+			})
+		}
+	}
+
+	if _, ok := capabilities[surfacePrompts]; ok {
+		prompts, err := conn.Client.ListPrompts(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		for _, prompt := range prompts.Prompts {
+			prefix := "prompt " + prompt.Name
+			add(prefix+" name", prompt.Name)
+			add(prefix+" description", deref(prompt.Description))
+			required := false
+			for _, arg := range prompt.Arguments {
+				add(prefix+" argument "+arg.Name, deref(arg.Description))
+				if arg.Required != nil && *arg.Required {
+					required = true
+				}
+			}
+			if required {
+				continue
+			}
+			messages, err := promptMessages(ctx, conn, prompt.Name)
+			if err != nil {
+				logger.Info("prompt not rendered for audit", "server", conn.Name, "prompt", prompt.Name, "error", err)
+				continue
+			}
+			for i, text := range messages {
+				add(fmt.Sprintf("%s message %d", prefix, i+1), text)
+			}
+		}
+	}
+
+	if _, ok := capabilities[surfaceResources]; ok {
+		resources, err := conn.Client.ListResources(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		for _, resource := range resources.Resources {
+			prefix := "resource " + resource.Uri
+			add(prefix+" uri", resource.Uri)
+			add(prefix+" name", resource.Name)
+			add(prefix+" description", deref(resource.Description))
+		}
+		templates, err := fetchResourceTemplates(ctx, conn)
+		if err != nil {
+			logger.Info("resource templates not audited", "server", conn.Name, "error", err)
+		}
+		for _, template := range templates {
+			prefix := "template " + template.URITemplate
+			add(prefix+" uriTemplate", template.URITemplate)
+			add(prefix+" name", template.Name)
+			add(prefix+" description", template.Description)
+		}
+	}
+	return texts, nil
+}
+
+// walkSchemaStrings calls fn with every string in a JSON schema, such as
+// property descriptions, defaults and enum values, and its dotted path
+func walkSchemaStrings(v interface{}, path string, fn func(path, s string)) {
+	switch v := v.(type) {
+	case string:
+		fn(path, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			walkSchemaStrings(v[key], path+"."+key, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkSchemaStrings(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	}
+}
+
+// promptMessages renders a prompt without arguments and returns the text of its messages
+func promptMessages(ctx context.Context, conn *Connection, promptName string) ([]string, error) {
+	raw, err := conn.Session.Request(ctx, "prompts/get", map[string]interface{}{"name": promptName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prompt: %w", err)
+	}
+	var result PromptResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt: %w", err)
+	}
+	var texts []string
+	for _, msg := range result.Messages {
+		texts = append(texts, msg.Content.Text)
+		if msg.Content.Resource != nil {
+			texts = append(texts, msg.Content.Resource.Text)
+		}
+	}
+	return texts, nil
+}
+
+// scanTexts matches every rule against every text, reporting each rule at
+// most once per text
+func scanTexts(serverName string, texts []auditText) []AuditFinding {
+	var findings []AuditFinding
+	for _, t := range texts {
+		for _, rule := range auditRules {
+			loc := rule.pattern.FindStringIndex(t.text)
+			if loc == nil {
+				continue
+			}
+			findings = append(findings, AuditFinding{
+				Server:   serverName,
+				Severity: rule.severity,
+				Rule:     rule.name,
+				Location: revealHidden(t.location),
+				Match:    auditExcerpt(t.text, loc[0], loc[1]),
+				Message:  rule.message,
+			})
+		}
+	}
+	return findings
+}
+
+// auditExcerpt shows a match with a little context, cut to a readable length,
+// with invisible characters spelled out so the excerpt shows what was hidden
+func auditExcerpt(text string, start, end int) string {
+	const around, maxMatch = 20, 60
+	from, to := start, end
+	for i := 0; i < around && from > 0; i++ {
+		_, size := utf8.DecodeLastRuneInString(text[:from])
+		from -= size
+	}
+	for i := 0; i < around && to < len(text); i++ {
+		_, size := utf8.DecodeRuneInString(text[to:])
+		to += size
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("...")
+	}
+	runes := 0
+	for i, r := range text[from:to] {
+		if from+i >= end && runes >= maxMatch {
+			break
+		}
+		runes++
+		switch {
+		case r == '\n' || r == '\t':
+			b.WriteRune(' ')
+		default:
+			b.WriteString(revealHidden(string(r)))
+		}
+	}
+	if to < len(text) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// revealHidden spells out the invisible characters of s as <U+200B>
+func revealHidden(s string) string {
+	return hiddenUnicode.ReplaceAllStringFunc(s, func(hidden string) string {
+		var b strings.Builder
+		for _, r := range hidden {
+			fmt.Fprintf(&b, "<U+%04X>", r)
+		}
+		return b.String()
+	})
+}
+
+func printAudit(report *AuditReport, servers int) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(report)
+	case outputCSV:
+		rows := make([][]string, 0, len(report.Findings))
+		for _, f := range report.Findings {
+			rows = append(rows, []string{f.Server, f.Severity, f.Rule, f.Location, f.Match, f.Message})
+		}
+		return writeCSV([]string{"SERVER", "SEVERITY", "RULE", "LOCATION", "MATCH", "MESSAGE"}, rows)
+	case outputGitHub:
+		return writeAnnotations(auditAnnotations(report))
+	}
+
+	if len(report.Findings) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SEVERITY\tSERVER\tLOCATION\tRULE\tMATCH")
+		for _, f := range report.Findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", paintStatus(f.Severity, f.Severity), f.Server, f.Location, f.Rule, f.Match)
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	if len(report.Packages) > 0 {
+		printPackages(report.Packages)
+		fmt.Println()
+	}
+	names := make([]string, 0, len(report.Errors))
+	for name := range report.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "Warning: %s not audited: %s\n", name, report.Errors[name])
+	}
+
+	// Print summary
+	if len(report.Findings) == 0 {
+		fmt.Printf("No findings | %d servers audited\n", servers-len(report.Errors))
+		return nil
+	}
+	fmt.Printf("%d findings (%d high, %d medium, %d low) | %d servers audited\n", len(report.Findings),
+		report.count(auditHigh), report.count(auditMedium), report.count(auditLow), servers-len(report.Errors))
+	return nil
+}
+
+// provenanceFindings reports what makes a server's package worth a closer
+// look: a deprecated or very recent version, no pinned version or few users
+func provenanceFindings(serverName, scope string, p *Provenance) []AuditFinding {
+	if p == nil || p.Error != "" {
+		return nil
+	}
+	location := fmt.Sprintf("package %s %s", p.Ecosystem, p.Name)
+	finding := func(severity, rule, match, message string) AuditFinding {
+		return AuditFinding{Server: serverName, Severity: severity, Rule: rule, Location: location, Match: match, Message: message, scope: scope}
+	}
+
+	var findings []AuditFinding
+	if p.Deprecated != "" {
+		findings = append(findings, finding(auditMedium, "deprecated-package", p.Version, "runs a deprecated or yanked version: "+p.Deprecated))
+	}
+	if age, ok := p.publishedAge(); ok && age < newReleaseAge {
+		findings = append(findings, finding(auditMedium, "new-release", p.Version,
+			fmt.Sprintf("runs a version published %s, before a compromised release is usually caught", p.Published[:10])))
+	}
+	if !p.Pinned {
+		findings = append(findings, finding(auditLow, "unpinned-package", cmp.Or(p.Requested, "latest"),
+			"runs whichever version is the latest when it starts, so a new release changes it unreviewed"))
+	}
+	if p.WeeklyDownloads != nil && *p.WeeklyDownloads < fewDownloads {
+		findings = append(findings, finding(auditLow, "unpopular-package", fmt.Sprintf("%d downloads", *p.WeeklyDownloads),
+			"has few users to notice a malicious release; check who publishes it"))
+	}
+	return findings
+}
+
+// printPackages lists the packages of the audited servers, with a warning
+// for each lookup that failed
+func printPackages(packages map[string]*Provenance) {
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	var failed []string
+	fmt.Fprintln(w, "SERVER\tPACKAGE\tVERSION\tPUBLISHED\tDOWNLOADS/WEEK\tMAINTAINERS")
+	for _, name := range names {
+		p := packages[name]
+		if p.Error != "" {
+			failed = append(failed, fmt.Sprintf("Warning: package of %s not looked up: %s", name, p.Error))
+		}
+		version, published, downloads, maintainers := cmp.Or(p.Version, p.Requested, "[N/A]"), "[N/A]", "[N/A]", "[N/A]"
+		if p.Published != "" {
+			published = p.Published[:10]
+		}
+		if p.WeeklyDownloads != nil {
+			downloads = strconv.Itoa(*p.WeeklyDownloads)
+		}
+		if len(p.Maintainers) > 0 {
+			maintainers = strings.Join(p.Maintainers, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%s %s\t%s\t%s\t%s\t%s\n", name, p.Ecosystem, p.Name, version, published, downloads, maintainers)
+	}
+	w.Flush()
+	for _, warning := range failed {
+		fmt.Fprintln(os.Stderr, warning)
+	}
+}
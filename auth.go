@@ -1,44 +1,450 @@
 package main
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // MCPCredentials represents the keychain credentials structure
 type MCPCredentials struct {
 	MCPOAuth map[string]MCPOAuthEntry `json:"mcpOAuth"`
+
+	// raw is the whole document as read, so that writing it back keeps the
+	// fields mcpinspect does not know about, such as Claude's own login
+	raw map[string]json.RawMessage
 }
 
 type MCPOAuthEntry struct {
 	ServerName   string `json:"serverName"`
 	ServerURL    string `json:"serverUrl"`
+	ClientID     string `json:"clientId,omitempty"`
+	ClientSecret string `json:"clientSecret,omitempty"`
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
 	ExpiresAt    int64  `json:"expiresAt"`
+	Scope        string `json:"scope,omitempty"`
 }
 
-func getMCPOAuthToken(serverName, serverURL string) (string, error) {
-	// Read from macOS keychain
-	cmd := exec.Command("security", "find-generic-password", "-s", "Claude Code-credentials", "-w")
-	output, err := cmd.Output()
+// tokenExpirySkew treats tokens about to expire as expired, so they are not
+// refreshed in the middle of a command
+const tokenExpirySkew = 30 * time.Second
+
+// Expired reports whether the access token has expired or is about to
+func (e MCPOAuthEntry) Expired() bool {
+	return e.ExpiresAt > 0 && time.Now().Add(tokenExpirySkew).After(time.UnixMilli(e.ExpiresAt))
+}
+
+// credentialStore is a place OAuth credentials are read from and written back to
+type credentialStore struct {
+	name string
+	load func() (*MCPCredentials, error)
+	save func(*MCPCredentials) error
+
+	// shared stores belong to Claude Code, so deleting from them logs it out too
+	shared bool
+}
+
+// credentialStores are searched in order; tokens of `mcpinspect auth login`
+// take precedence over Claude Code's
+var credentialStores = []credentialStore{
+	{name: "mcpinspect", load: loadStoredCredentials, save: saveStoredCredentials},
+	claudeCredentialStore(),
+	{name: "claude-file", load: loadClaudeFileCredentials, save: saveClaudeFileCredentials, shared: true},
+}
+
+// claudeCredentialStore is the secret store Claude Code keeps its
+// credentials in on this platform
+func claudeCredentialStore() credentialStore {
+	switch runtime.GOOS {
+	case "darwin":
+		return credentialStore{name: "keychain", load: loadKeychainCredentials, save: saveKeychainCredentials, shared: true}
+	case "windows":
+		return credentialStore{name: "wincred", load: loadWincredCredentials, save: saveWincredCredentials, shared: true}
+	}
+	return credentialStore{name: "keyring", load: loadKeyringCredentials, save: saveKeyringCredentials, shared: true}
+}
+
+// errNoCredentials is returned by stores that have no item on this machine
+var errNoCredentials = errors.New("no credentials stored")
+
+// storedCredential is an entry together with where it is kept
+type storedCredential struct {
+	store *credentialStore
+	key   string
+	entry MCPOAuthEntry
+}
+
+// refreshMu serializes refreshes, since servers may rotate refresh tokens
+var refreshMu sync.Mutex
+
+// getMCPOAuthToken returns the access token of a server, refreshing it first
+// when it has expired and a refresh token is stored
+func getMCPOAuthToken(ctx context.Context, server *MCPServer, serverName string) (string, error) {
+	cred, err := lookupMCPOAuth(serverName, server.URL)
 	if err != nil {
 		return "", err
 	}
+	if cred.entry.Expired() && cred.entry.RefreshToken != "" {
+		entry, err := refreshMCPOAuth(ctx, server, cred)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to refresh the OAuth token of %s: %v\n", serverName, err)
+			return cred.entry.AccessToken, nil
+		}
+		return entry.AccessToken, nil
+	}
+	return cred.entry.AccessToken, nil
+}
 
-	var creds MCPCredentials
-	if err := json.Unmarshal(output, &creds); err != nil {
-		return "", err
+// lookupMCPOAuth searches the credential stores for a server's entry
+func lookupMCPOAuth(serverName, serverURL string) (*storedCredential, error) {
+	var lastErr error
+	for i := range credentialStores {
+		store := &credentialStores[i]
+		creds, err := store.load()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if key, entry, ok := creds.find(serverName, serverURL); ok {
+			return &storedCredential{store: store, key: key, entry: entry}, nil
+		}
 	}
+	if lastErr != nil {
+		return nil, fmt.Errorf("no token found for server %s: %w", serverName, lastErr)
+	}
+	return nil, fmt.Errorf("no token found for server %s", serverName)
+}
 
-	// Look for matching server
-	for key, entry := range creds.MCPOAuth {
+// find looks up a server by name, URL or key prefix
+func (c *MCPCredentials) find(serverName, serverURL string) (string, MCPOAuthEntry, bool) {
+	for key, entry := range c.MCPOAuth {
 		if entry.ServerName == serverName || entry.ServerURL == serverURL ||
-		   (len(key) > len(serverName) && key[:len(serverName)] == serverName) {
-			return entry.AccessToken, nil
+			(len(key) > len(serverName) && key[:len(serverName)] == serverName) {
+			return key, entry, true
+		}
+	}
+	return "", MCPOAuthEntry{}, false
+}
+
+// matching returns the keys of every entry of a server name, sorted
+func (c *MCPCredentials) matching(serverName string) []string {
+	var keys []string
+	for key, entry := range c.MCPOAuth {
+		if entry.ServerName == serverName || strings.HasPrefix(key, serverName+"|") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// refreshMCPOAuth trades the refresh token of a stored entry for new tokens
+// at the server's token endpoint and writes them back to the entry's store
+func refreshMCPOAuth(ctx context.Context, server *MCPServer, cred *storedCredential) (*MCPOAuthEntry, error) {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	// Another refresh may have stored new tokens while this one waited
+	if creds, err := cred.store.load(); err == nil {
+		if current, ok := creds.MCPOAuth[cred.key]; ok {
+			if current.AccessToken != cred.entry.AccessToken && !current.Expired() {
+				return &current, nil
+			}
+			cred.entry = current
+		}
+	}
+	if cred.entry.RefreshToken == "" {
+		return nil, fmt.Errorf("no refresh token stored")
+	}
+
+	discovered, err := discoverOAuth(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {cred.entry.RefreshToken},
+		"resource":      {discovered.Resource},
+	}
+	if cred.entry.ClientID != "" {
+		form.Set("client_id", cred.entry.ClientID)
+	}
+	if cred.entry.ClientSecret != "" {
+		form.Set("client_secret", cred.entry.ClientSecret)
+	}
+	var tokens oauthTokens
+	if err := postForm(ctx, discovered.client, discovered.Metadata.TokenEndpoint, form, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	}
+	if tokens.AccessToken == "" {
+		return nil, fmt.Errorf("failed to refresh token: no access_token in the response")
+	}
+
+	entry := cred.entry
+	entry.AccessToken = tokens.AccessToken
+	entry.ExpiresAt = 0
+	if tokens.ExpiresIn > 0 {
+		entry.ExpiresAt = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second).UnixMilli()
+	}
+	// Servers that do not rotate refresh tokens omit them from the response
+	if tokens.RefreshToken != "" {
+		entry.RefreshToken = tokens.RefreshToken
+	}
+	if tokens.Scope != "" {
+		entry.Scope = tokens.Scope
+	}
+
+	creds, err := cred.store.load()
+	if err != nil {
+		return nil, err
+	}
+	creds.MCPOAuth[cred.key] = entry
+	if err := cred.store.save(creds); err != nil {
+		return nil, fmt.Errorf("failed to store refreshed token: %w", err)
+	}
+	cred.entry = entry
+	return &entry, nil
+}
+
+// authRefresh renews the stored token of a server whose requests were
+// rejected, for transports to retry with
+func authRefresh(server *MCPServer, serverName string) authRefresher {
+	return func(ctx context.Context) (string, error) {
+		cred, err := lookupMCPOAuth(serverName, server.URL)
+		if err != nil {
+			return "", err
+		}
+		entry, err := refreshMCPOAuth(ctx, server, cred)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + entry.AccessToken, nil
+	}
+}
+
+// parseCredentials decodes a credentials document
+func parseCredentials(data []byte) (*MCPCredentials, error) {
+	creds := &MCPCredentials{}
+	if err := json.Unmarshal(data, &creds.raw); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, creds); err != nil {
+		return nil, err
+	}
+	if creds.raw == nil {
+		creds.raw = make(map[string]json.RawMessage)
+	}
+	if creds.MCPOAuth == nil {
+		creds.MCPOAuth = make(map[string]MCPOAuthEntry)
+	}
+	return creds, nil
+}
+
+// encode renders the credentials with the changed entries merged into the
+// document as read, so unknown fields of entries survive too
+func (c *MCPCredentials) encode() ([]byte, error) {
+	old := make(map[string]map[string]json.RawMessage)
+	if raw := c.raw["mcpOAuth"]; len(raw) > 0 {
+		json.Unmarshal(raw, &old)
+	}
+
+	entries := make(map[string]map[string]json.RawMessage, len(c.MCPOAuth))
+	for key, entry := range c.MCPOAuth {
+		fields := old[key]
+		if fields == nil {
+			fields = make(map[string]json.RawMessage)
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode credentials: %w", err)
 		}
+		var updated map[string]json.RawMessage
+		json.Unmarshal(data, &updated)
+		for field, value := range updated {
+			fields[field] = value
+		}
+		entries[key] = fields
+	}
+
+	doc := make(map[string]json.RawMessage, len(c.raw)+1)
+	for key, value := range c.raw {
+		doc[key] = value
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	doc["mcpOAuth"] = raw
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	return data, nil
+}
+
+// keychainService is the keychain or keyring item Claude Code keeps its credentials in
+const keychainService = "Claude Code-credentials"
+
+// loadKeychainCredentials reads Claude Code's credentials from the macOS keychain
+func loadKeychainCredentials() (*MCPCredentials, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", keychainService, "-w")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseCredentials(output)
+}
+
+// saveKeychainCredentials updates Claude Code's keychain item in place
+func saveKeychainCredentials(creds *MCPCredentials) error {
+	data, err := creds.encode()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("security", "add-generic-password", "-U", "-a", credentialAccount(), "-s", keychainService, "-X", hex.EncodeToString(data))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update keychain: %w: %s", err, strings.TrimSpace(string(output)))
 	}
+	return nil
+}
+
+// credentialAccount is the account name secret stores file Claude Code's item under
+func credentialAccount() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// credentialsPath is where `mcpinspect auth login` keeps its tokens
+func credentialsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mcpinspect", "credentials.json"), nil
+}
+
+// loadStoredCredentials reads mcpinspect's own credentials; a missing file has none
+func loadStoredCredentials() (*MCPCredentials, error) {
+	path, err := credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return parseCredentials([]byte("{}"))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	creds, err := parseCredentials(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// saveStoredCredentials writes mcpinspect's own credentials, readable only by the user
+func saveStoredCredentials(creds *MCPCredentials) error {
+	path, err := credentialsPath()
+	if err != nil {
+		return err
+	}
+	data, err := creds.encode()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+	return writeCredentialsFile(path, data)
+}
+
+// claudeCredentialsPath is the plaintext file Claude Code keeps its
+// credentials in where it has no keychain, as on most Linux hosts and containers
+func claudeCredentialsPath() (string, error) {
+	if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, ".credentials.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	return filepath.Join(home, ".claude", ".credentials.json"), nil
+}
+
+// loadClaudeFileCredentials reads Claude Code's plaintext credentials file
+func loadClaudeFileCredentials() (*MCPCredentials, error) {
+	path, err := claudeCredentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, errNoCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+	creds, err := parseCredentials(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse credentials %s: %w", path, err)
+	}
+	return creds, nil
+}
+
+// saveClaudeFileCredentials writes Claude Code's plaintext credentials file back
+func saveClaudeFileCredentials(creds *MCPCredentials) error {
+	path, err := claudeCredentialsPath()
+	if err != nil {
+		return err
+	}
+	data, err := creds.encode()
+	if err != nil {
+		return err
+	}
+	return writeCredentialsFile(path, data)
+}
+
+// writeCredentialsFile replaces a credentials file atomically, readable only by the user
+func writeCredentialsFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
 
-	return "", fmt.Errorf("no token found for server %s", serverName)
+// credentialKey is the key of a server's entry, its name and a hash of its
+// URL like Claude Code uses, so servers sharing a name stay apart
+func credentialKey(serverName, serverURL string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSuffix(serverURL, "/")))
+	return serverName + "|" + hex.EncodeToString(sum[:8])
 }
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// loginTimeout bounds how long auth login waits for the user to authorize
+const loginTimeout = 5 * time.Minute
+
+func newAuthCmd() *cobra.Command {
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage OAuth credentials of remote servers",
+	}
+	authCmd.AddCommand(newAuthLoginCmd(), newAuthListCmd(), newAuthLogoutCmd())
+	return authCmd
+}
+
+// OAuthEntryInfo describes one stored OAuth entry without its secrets
+type OAuthEntryInfo struct {
+	Server      string   `json:"server"`
+	URL         string   `json:"url"`
+	Scopes      []string `json:"scopes,omitempty"`
+	ExpiresAt   string   `json:"expiresAt,omitempty"`
+	Expired     bool     `json:"expired"`
+	Refreshable bool     `json:"refreshable"`
+	Store       string   `json:"store"`
+	Key         string   `json:"key"`
+}
+
+func newAuthLoginCmd() *cobra.Command {
+	var opts loginOptions
+	var scope string
+	loginCmd := &cobra.Command{
+		Use:   "login <server-name>",
+		Short: "Authorize mcpinspect with a remote server's OAuth flow",
+		Long: `Run the OAuth 2.1 authorization code flow of an HTTP or SSE server, so
+mcpinspect can connect to it without Claude Code having logged in first.
+
+The authorization server is discovered from the server's 401 challenge or its
+/.well-known/oauth-protected-resource document, mcpinspect registers itself
+with dynamic client registration unless --client-id is given, and the browser
+opens on the consent page. The redirect is caught by a callback server on
+127.0.0.1, and the tokens are stored in mcpinspect's credentials file, which
+takes precedence over the keychain.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverName := args[0]
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			server, err := findServer(config, serverName)
+			if err != nil {
+				return err
+			}
+			if server.Type != "http" && server.Type != "sse" {
+				return fmt.Errorf("server %s is a %s server, only HTTP and SSE servers use OAuth", serverName, server.Type)
+			}
+			if server, err = resolveInputs(server); err != nil {
+				return err
+			}
+			opts.Scopes = strings.Fields(scope)
+			// A failed authorization is a result, not a usage error
+			cmd.SilenceUsage = true
+
+			ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+			defer cancel()
+			entry, err := oauthLogin(ctx, server, serverName, opts)
+			if err != nil {
+				return err
+			}
+
+			creds, err := loadStoredCredentials()
+			if err != nil {
+				return err
+			}
+			creds.MCPOAuth[credentialKey(serverName, server.URL)] = *entry
+			if err := saveStoredCredentials(creds); err != nil {
+				return err
+			}
+			path, _ := credentialsPath()
+			fmt.Printf("Logged in to %s, tokens stored in %s\n", serverName, path)
+			if entry.ExpiresAt > 0 {
+				fmt.Printf("Access token valid until %s\n", time.UnixMilli(entry.ExpiresAt).Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+	loginCmd.Flags().StringVar(&opts.ClientID, "client-id", "", "use this pre-registered OAuth client instead of dynamic client registration")
+	loginCmd.Flags().StringVar(&opts.ClientSecret, "client-secret", "", "secret of the --client-id client, for confidential clients")
+	loginCmd.Flags().StringVar(&scope, "scope", "", "space-separated scopes to request (default: those the server advertises)")
+	loginCmd.Flags().IntVar(&opts.Port, "port", 0, "port of the localhost callback server (default: any free port)")
+	loginCmd.Flags().BoolVar(&opts.NoBrowser, "no-browser", false, "only print the authorization URL instead of opening a browser")
+	return loginCmd
+}
+
+func newAuthListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List stored OAuth credentials and when they expire",
+		Long: `List the OAuth entries of every credential store mcpinspect reads: its own
+credentials file and the keychain item of Claude Code. Each entry shows the
+server name and URL, the granted scopes, when the access token expires and
+whether a refresh token is stored. Tokens are never printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return printOAuthEntries(listOAuthEntries())
+		},
+	}
+}
+
+func newAuthLogoutCmd() *cobra.Command {
+	var yes bool
+	logoutCmd := &cobra.Command{
+		Use:   "logout <server-name>",
+		Short: "Delete the stored OAuth credentials of a server",
+		Long: `Delete every stored OAuth entry of a server, to force a fresh login when its
+tokens are corrupted or were granted the wrong scopes.
+
+Entries of mcpinspect's own credentials file are deleted right away. Entries
+in Claude Code's keychain item are shared with Claude Code, which then has to
+log in to the server again, so they are only deleted after confirming, or with
+--yes.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Finding nothing to delete is a result, not a usage error
+			cmd.SilenceUsage = true
+			return logoutServer(args[0], yes)
+		},
+	}
+	logoutCmd.Flags().BoolVarP(&yes, "yes", "y", false, "delete Claude Code's entries without asking")
+	return logoutCmd
+}
+
+// logoutServer deletes the entries of a server from every credential store
+func logoutServer(serverName string, yes bool) error {
+	found, removed := 0, 0
+	for i := range credentialStores {
+		store := &credentialStores[i]
+		creds, err := store.load()
+		if err != nil {
+			if !credentialsUnavailable(err) {
+				fmt.Fprintf(os.Stderr, "Warning: failed to read %s credentials: %v\n", store.name, err)
+			}
+			continue
+		}
+		keys := creds.matching(serverName)
+		if len(keys) == 0 {
+			continue
+		}
+		found += len(keys)
+
+		if store.shared && !yes {
+			if !term.IsTerminal(int(os.Stdin.Fd())) {
+				fmt.Fprintf(os.Stderr, "Warning: keeping %d entries of %s in the %s, Claude Code uses them too (use --yes to delete them)\n", len(keys), serverName, store.name)
+				continue
+			}
+			answer, err := promptLine(fmt.Sprintf("Delete %d entries of %s from the %s? Claude Code will have to log in again [y/N]: ", len(keys), serverName, store.name))
+			if err != nil {
+				return err
+			}
+			if answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+
+		for _, key := range keys {
+			delete(creds.MCPOAuth, key)
+		}
+		if err := store.save(creds); err != nil {
+			return err
+		}
+		for _, key := range keys {
+			fmt.Printf("Removed %s from the %s credentials\n", key, store.name)
+		}
+		removed += len(keys)
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no OAuth credentials stored for %s", serverName)
+	}
+	if removed == 0 {
+		fmt.Println("Nothing removed.")
+	}
+	return nil
+}
+
+// listOAuthEntries collects the entries of every credential store, sorted by
+// server name; stores that do not exist on this machine are skipped
+func listOAuthEntries() []OAuthEntryInfo {
+	var entries []OAuthEntryInfo
+	for _, store := range credentialStores {
+		creds, err := store.load()
+		if err != nil {
+			if !credentialsUnavailable(err) {
+				fmt.Fprintf(os.Stderr, "Warning: failed to read %s credentials: %v\n", store.name, err)
+			}
+			continue
+		}
+		for key, entry := range creds.MCPOAuth {
+			info := OAuthEntryInfo{
+				Server:      entry.ServerName,
+				URL:         entry.ServerURL,
+				Scopes:      strings.Fields(entry.Scope),
+				Expired:     entry.Expired(),
+				Refreshable: entry.RefreshToken != "",
+				Store:       store.name,
+				Key:         key,
+			}
+			if entry.ExpiresAt > 0 {
+				info.ExpiresAt = time.UnixMilli(entry.ExpiresAt).Format(time.RFC3339)
+			}
+			entries = append(entries, info)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Server != entries[j].Server {
+			return entries[i].Server < entries[j].Server
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// credentialsUnavailable tells a store that does not exist here, such as the
+// keychain off macOS or a missing item, from one that failed to read
+func credentialsUnavailable(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.Is(err, exec.ErrNotFound) || errors.Is(err, errNoCredentials) || errors.As(err, &exitErr)
+}
+
+func printOAuthEntries(entries []OAuthEntryInfo) error {
+	switch outputFormat {
+	case outputJSON:
+		if entries == nil {
+			entries = []OAuthEntryInfo{}
+		}
+		return writeJSON(entries)
+	case outputCSV:
+		var rows [][]string
+		for _, e := range entries {
+			rows = append(rows, []string{e.Server, e.URL, strings.Join(e.Scopes, " "), e.ExpiresAt, fmt.Sprint(e.Expired), fmt.Sprint(e.Refreshable), e.Store})
+		}
+		return writeCSV([]string{"SERVER", "URL", "SCOPES", "EXPIRES", "EXPIRED", "REFRESHABLE", "STORE"}, rows)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No OAuth credentials stored.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tURL\tSCOPES\tEXPIRES\tREFRESH\tSTORE")
+	expired := 0
+	for _, e := range entries {
+		expires := e.ExpiresAt
+		switch {
+		case expires == "":
+			expires = "never"
+		case e.Expired:
+			expires += " (expired)"
+			expired++
+		}
+		scopes := strings.Join(e.Scopes, " ")
+		if scopes == "" {
+			scopes = "-"
+		}
+		refresh := "no"
+		if e.Refreshable {
+			refresh = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Server, e.URL, scopes, expires, refresh, e.Store)
+	}
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d entries, %d expired\n", len(entries), expired)
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// LatencyStats summarizes request latencies in milliseconds
+type LatencyStats struct {
+	MinMs float64 `json:"minMs"`
+	AvgMs float64 `json:"avgMs"`
+	P50Ms float64 `json:"p50Ms"`
+	P95Ms float64 `json:"p95Ms"`
+	P99Ms float64 `json:"p99Ms"`
+	MaxMs float64 `json:"maxMs"`
+}
+
+// newLatencyStats computes latency statistics using nearest-rank percentiles
+func newLatencyStats(latencies []time.Duration) LatencyStats {
+	if len(latencies) == 0 {
+		return LatencyStats{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return durationMs(sorted[rank-1])
+	}
+
+	return LatencyStats{
+		MinMs: durationMs(sorted[0]),
+		AvgMs: durationMs(total / time.Duration(len(sorted))),
+		P50Ms: percentile(50),
+		P95Ms: percentile(95),
+		P99Ms: percentile(99),
+		MaxMs: durationMs(sorted[len(sorted)-1]),
+	}
+}
+
+// BenchResult is the outcome of benchmarking one request type against a server
+type BenchResult struct {
+	Server     string  `json:"server"`
+	Method     string  `json:"method"`
+	Tool       string  `json:"tool,omitempty"`
+	Requests   int     `json:"requests"`
+	Errors     int     `json:"errors"`
+	FirstError string  `json:"firstError,omitempty"`
+	TotalMs    float64 `json:"totalMs"`
+	LatencyStats
+}
+
+// benchRequest sends one request, returning an error for failed requests and tool errors
+type benchRequest func(ctx context.Context) error
+
+func newBenchCmd() *cobra.Command {
+	var method, tool, args string
+	var n int
+	benchCmd := &cobra.Command{
+		Use:   "bench <server-name>",
+		Short: "Measure request latency against a server",
+		Long: `Connect to a server once and send the same request repeatedly over the warm
+connection, reporting min/avg/p50/p95/p99/max latency.
+
+By default tools/list is measured. Use --method for another request without
+params, or --tool with --args to measure a tool call.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if n < 1 {
+				return fmt.Errorf("-n must be at least 1")
+			}
+			var arguments map[string]interface{}
+			if args != "" {
+				if tool == "" {
+					return fmt.Errorf("--args requires --tool")
+				}
+				if err := json.Unmarshal([]byte(args), &arguments); err != nil {
+					return fmt.Errorf("invalid --args: %w", err)
+				}
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return runBench(config, cmdArgs[0], method, tool, arguments, n)
+		},
+	}
+	benchCmd.Flags().StringVar(&method, "method", "tools/list", "JSON-RPC method to measure")
+	benchCmd.Flags().StringVar(&tool, "tool", "", "measure calls to this tool instead of --method")
+	benchCmd.Flags().StringVar(&args, "args", "", "tool arguments as a JSON object")
+	benchCmd.Flags().IntVarP(&n, "n", "n", 100, "number of requests")
+	benchCmd.MarkFlagsMutuallyExclusive("method", "tool")
+	return benchCmd
+}
+
+func runBench(config *ClaudeConfig, serverName, method, tool string, arguments map[string]interface{}, n int) error {
+	// The connection is reused for every request, so only requests get a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result := &BenchResult{Server: serverName, Method: method, Tool: tool}
+	request := func(ctx context.Context) error {
+		_, err := conn.Session.Request(ctx, method, nil)
+		return err
+	}
+	if tool != "" {
+		result.Method = "tools/call"
+		request = toolCallRequest(conn, tool, arguments)
+	}
+
+	latencies := make([]time.Duration, 0, n)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		latency, err := timeRequest(ctx, request)
+		latencies = append(latencies, latency)
+		if err != nil {
+			result.Errors++
+			if result.FirstError == "" {
+				result.FirstError = err.Error()
+			}
+		}
+	}
+	result.TotalMs = durationMs(time.Since(start))
+	result.Requests = n
+	result.LatencyStats = newLatencyStats(latencies)
+
+	return printBenchResult(conn, result)
+}
+
+// toolCallRequest returns a benchRequest calling a tool, treating isError results as failures
+func toolCallRequest(conn *Connection, tool string, arguments map[string]interface{}) benchRequest {
+	return func(ctx context.Context) error {
+		result, err := callTool(ctx, conn, tool, arguments)
+		if err != nil {
+			return err
+		}
+		if result.IsError {
+			return fmt.Errorf("tool returned an error")
+		}
+		return nil
+	}
+}
+
+// timeRequest sends one request, bounded by the request timeout, and measures
+// how long it took
+func timeRequest(ctx context.Context, request benchRequest) (time.Duration, error) {
+	start := time.Now()
+	err := request(ctx)
+	return time.Since(start), err
+}
+
+func printBenchResult(conn *Connection, r *BenchResult) error {
+	label := r.Method
+	if r.Tool != "" {
+		label = r.Tool
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(r)
+	case outputCSV:
+		return writeCSV(
+			[]string{"SERVER", "REQUEST", "REQUESTS", "ERRORS", "MIN MS", "AVG MS", "P50 MS", "P95 MS", "P99 MS", "MAX MS"},
+			[][]string{{r.Server, label, strconv.Itoa(r.Requests), strconv.Itoa(r.Errors),
+				formatMs(r.MinMs), formatMs(r.AvgMs), formatMs(r.P50Ms), formatMs(r.P95Ms), formatMs(r.P99Ms), formatMs(r.MaxMs)}},
+		)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REQUEST\tREQUESTS\tERRORS\tMIN\tAVG\tP50\tP95\tP99\tMAX")
+	fmt.Fprintf(w, "%s\t%d\t%d\t%sms\t%sms\t%sms\t%sms\t%sms\t%sms\n", label, r.Requests, r.Errors,
+		formatMs(r.MinMs), formatMs(r.AvgMs), formatMs(r.P50Ms), formatMs(r.P95Ms), formatMs(r.P99Ms), formatMs(r.MaxMs))
+	w.Flush()
+
+	if r.FirstError != "" {
+		fmt.Fprintf(os.Stderr, "First error: %s\n", r.FirstError)
+	}
+
+	// Print summary
+	fmt.Println()
+	rate := float64(r.Requests) / (r.TotalMs / 1000)
+	fmt.Printf("%d requests in %sms (%.1f req/s) | %s | %s\n", r.Requests, formatMs(r.TotalMs), rate, conn.Server.Type, formatServerInfo(conn.Init))
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+func newCallCmd() *cobra.Command {
+	var args string
+	callCmd := &cobra.Command{
+		Use:   "call <server-name> <tool>",
+		Short: "Call a tool and print its result",
+		Long: `Call a tool with the given JSON arguments and print its content blocks and
+structured content. With --output json the raw result is printed.
+
+Progress notifications the server sends during the call are shown on stderr,
+as a live progress bar on a terminal. Ctrl+C cancels the call: the server is
+sent notifications/cancelled for the request before the connection is closed.
+
+Examples:
+  mcpinspect call my-server echo --args '{"text": "hi"}'`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			var arguments map[string]interface{}
+			if args != "" {
+				if err := json.Unmarshal([]byte(args), &arguments); err != nil {
+					return fmt.Errorf("invalid --args: %w", err)
+				}
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Tool errors are a result, not a usage error
+			cmd.SilenceUsage = true
+			return runToolCall(config, cmdArgs[0], cmdArgs[1], arguments)
+		},
+	}
+	callCmd.Flags().StringVar(&args, "args", "", "tool arguments as a JSON object")
+	return callCmd
+}
+
+func runToolCall(config *ClaudeConfig, serverName, tool string, arguments map[string]interface{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Ctrl+C cancels only the call, so the server is sent notifications/cancelled
+	// and the connection is still closed cleanly
+	callCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	progress := NewProgressRenderer()
+	result, err := callToolWithProgress(callCtx, conn, tool, arguments, progress.Update)
+	progress.Done()
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return fmt.Errorf("call to %s interrupted, sent notifications/cancelled", tool)
+		}
+		return err
+	}
+
+	if err := printToolResult(result); err != nil {
+		return err
+	}
+	if result.IsError {
+		return fmt.Errorf("tool %s returned an error", tool)
+	}
+	return nil
+}
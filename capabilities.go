@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// matrixCapabilities are the standard server capabilities shown as matrix columns, in order
+var matrixCapabilities = []string{"tools", "resources", "prompts", "logging", "completions"}
+
+// CapabilitiesResult is the capabilities one server advertised during initialize
+type CapabilitiesResult struct {
+	Name          string                 `json:"name"`
+	Type          string                 `json:"type"`
+	ServerVersion string                 `json:"serverVersion,omitempty"`
+	Capabilities  map[string]interface{} `json:"capabilities,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+func newCapabilitiesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "capabilities [server-name...]",
+		Short: "Show a matrix of the capabilities each server advertises",
+		Long: `Connect to servers and show which capabilities each one advertised in its
+initialize response: tools, resources, prompts, logging, completions and any
+experimental capabilities, along with flags such as listChanged and subscribe.
+
+Without arguments every configured server is contacted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Unreachable servers are a result, not a usage error
+			cmd.SilenceUsage = true
+
+			names := args
+			if len(names) == 0 {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			}
+			return showCapabilities(config, names)
+		},
+	}
+}
+
+func showCapabilities(config *ClaudeConfig, names []string) error {
+	results := make([]CapabilitiesResult, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		results[i] = fetchCapabilities(config, names[i])
+	})
+
+	if err := printCapabilities(results); err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d servers unreachable", failed, len(results))
+	}
+	return nil
+}
+
+// fetchCapabilities initializes a session with a server, recording failures rather than returning them
+func fetchCapabilities(config *ClaudeConfig, serverName string) CapabilitiesResult {
+	result := CapabilitiesResult{Name: serverName}
+	if server, err := findServer(config, serverName); err == nil {
+		result.Type = server.Type
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer conn.Close()
+
+	result.ServerVersion = formatServerInfo(conn.Init)
+	result.Capabilities = conn.Capabilities()
+	return result
+}
+
+// formatCapability renders one capability as "yes", its enabled flags, or "-" when absent
+func formatCapability(capabilities map[string]interface{}, name string) string {
+	value, ok := capabilities[name]
+	if !ok {
+		return "-"
+	}
+	options, _ := value.(map[string]interface{})
+	flags := []string{}
+	for flag, enabled := range options {
+		if enabled == true {
+			flags = append(flags, flag)
+		}
+	}
+	if len(flags) == 0 {
+		return "yes"
+	}
+	sort.Strings(flags)
+	return "yes (" + strings.Join(flags, ", ") + ")"
+}
+
+// experimentalNames returns the sorted names of a server's experimental capabilities
+func experimentalNames(capabilities map[string]interface{}) []string {
+	experimental, _ := capabilities["experimental"].(map[string]interface{})
+	names := make([]string, 0, len(experimental))
+	for name := range experimental {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatCapabilities summarizes advertised capabilities on one line, e.g. "tools (listChanged), logging"
+func formatCapabilities(capabilities map[string]interface{}) string {
+	parts := []string{}
+	for _, name := range matrixCapabilities {
+		cell := formatCapability(capabilities, name)
+		if cell == "-" {
+			continue
+		}
+		parts = append(parts, name+strings.TrimPrefix(cell, "yes"))
+	}
+	for _, name := range experimentalNames(capabilities) {
+		parts = append(parts, "experimental "+name)
+	}
+	if len(parts) == 0 {
+		return "none"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func printCapabilities(results []CapabilitiesResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(results)
+	case outputCSV:
+		header := []string{"NAME", "TYPE"}
+		for _, name := range matrixCapabilities {
+			header = append(header, strings.ToUpper(name))
+		}
+		header = append(header, "EXPERIMENTAL", "ERROR")
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			row := []string{r.Name, r.Type}
+			for _, name := range matrixCapabilities {
+				cell := ""
+				if r.Error == "" {
+					cell = formatCapability(r.Capabilities, name)
+				}
+				row = append(row, cell)
+			}
+			row = append(row, strings.Join(experimentalNames(r.Capabilities), "\n"), r.Error)
+			rows = append(rows, row)
+		}
+		return writeCSV(header, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprint(w, "NAME\tTYPE")
+	for _, name := range matrixCapabilities {
+		fmt.Fprintf(w, "\t%s", strings.ToUpper(name))
+	}
+	fmt.Fprintln(w, "\tEXPERIMENTAL")
+
+	reachable := 0
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%s", r.Name, r.Type)
+		if r.Error != "" {
+			for range matrixCapabilities {
+				fmt.Fprint(w, "\t[N/A]")
+			}
+			fmt.Fprintln(w, "\t[N/A]")
+			continue
+		}
+		reachable++
+		for _, name := range matrixCapabilities {
+			fmt.Fprintf(w, "\t%s", formatCapability(r.Capabilities, name))
+		}
+		experimental := "-"
+		if names := experimentalNames(r.Capabilities); len(names) > 0 {
+			experimental = strings.Join(names, ", ")
+		}
+		fmt.Fprintf(w, "\t%s\n", experimental)
+	}
+	w.Flush()
+
+	first := true
+	for _, r := range results {
+		if r.Error == "" {
+			continue
+		}
+		if first {
+			fmt.Fprintln(os.Stderr)
+			first = false
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", r.Name, r.Error)
+	}
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d/%d servers reachable\n", reachable, len(results))
+	return nil
+}
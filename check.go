@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+// CheckResult compares a live server with its baseline snapshot
+type CheckResult struct {
+	Server   string `json:"server"`
+	Baseline string `json:"baseline"`
+	Drift    bool   `json:"drift"`
+	*DiffResult
+}
+
+func newCheckCmd() *cobra.Command {
+	var baseline string
+	checkCmd := &cobra.Command{
+		Use:   "check <server-name>",
+		Short: "Fail when a server's tools drift from a baseline snapshot",
+		Long: `Inspect a server and compare its tools, descriptions and input schemas with a
+baseline written by "mcpinspect snapshot". Any difference is printed as a diff
+and the command exits with code 5, so CI notices an upstream server changing.
+
+The baseline defaults to .mcpinspect/<server-name>.json, where snapshot writes
+it. After reviewing an intended change, run snapshot again to accept it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if baseline == "" {
+				baseline = filepath.Join(defaultSnapshotDir, args[0]+".json")
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Drift is a result, not a usage error
+			cmd.SilenceUsage = true
+			return checkBaseline(config, args[0], baseline)
+		},
+	}
+	checkCmd.Flags().StringVar(&baseline, "baseline", "", "snapshot to compare with (default: .mcpinspect/<server-name>.json)")
+	return checkCmd
+}
+
+func checkBaseline(config *ClaudeConfig, serverName, baseline string) error {
+	old, err := loadInspectionFile(baseline)
+	if err != nil {
+		return withExitCode(exitConfig, fmt.Errorf("%w; write a baseline with `mcpinspect snapshot %s`", err, serverName))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	live, err := collectSnapshot(ctx, config, conn)
+	if err != nil {
+		return err
+	}
+
+	diff := diffInspections(old, &live.InspectResult)
+	result := &CheckResult{Server: serverName, Baseline: baseline, Drift: !diff.Empty(), DiffResult: diff}
+	if err := printCheck(result, old, &live.InspectResult); err != nil {
+		return err
+	}
+	if result.Drift && failsOn(failDrift, true) {
+		return driftError(diff)
+	}
+	return nil
+}
+
+func printCheck(result *CheckResult, old, live *InspectResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(result)
+	case outputJUnit:
+		return writeJUnit("mcpinspect check", checkJUnit(result, old, live))
+	case outputGitHub:
+		return writeAnnotations(diffAnnotations(result.DiffResult, failsOn(failDrift, true), result.Baseline))
+	}
+
+	if !result.Drift {
+		fmt.Printf("No drift from %s (%d tools)\n", result.Baseline, len(live.Tools))
+		return nil
+	}
+
+	printToolChanges(os.Stdout, result.DiffResult)
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d added | %d removed | %d changed | %s\n", len(result.Added), len(result.Removed), len(result.Changed), result.Baseline)
+	return nil
+}
+
+// checkJUnit reports a test case per tool of the baseline or the live server,
+// failing for tools that were added, removed or changed
+func checkJUnit(result *CheckResult, old, live *InspectResult) []junitTestSuite {
+	failures := make(map[string]*junitFailure)
+	for _, name := range result.Added {
+		failures[name] = &junitFailure{Message: "added", Text: "The server now exposes " + name + ", which is not in " + result.Baseline + "."}
+	}
+	for _, name := range result.Removed {
+		failures[name] = &junitFailure{Message: "removed", Text: "The server no longer exposes " + name + "."}
+	}
+	for _, change := range result.Changed {
+		failure := &junitFailure{Message: "changed"}
+		if change.DescriptionChanged() {
+			failure.Text += fmt.Sprintf("description: %q -> %q\n", change.OldDescription, change.NewDescription)
+		}
+		for _, sc := range change.SchemaChanges {
+			failure.Text += formatSchemaChange(sc) + "\n"
+		}
+		failures[change.Name] = failure
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, tools := range [][]ToolInfo{old.Tools, live.Tools} {
+		for _, tool := range tools {
+			if !seen[tool.Name] {
+				seen[tool.Name] = true
+				names = append(names, tool.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	suite := junitTestSuite{Name: result.Server}
+	for _, name := range names {
+		suite.Cases = append(suite.Cases, junitTestCase{ClassName: "mcpinspect.check." + result.Server, Name: name, Failure: failures[name]})
+	}
+	return []junitTestSuite{suite}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// MCP clients whose configuration can be read with --client
+const (
+	clientClaudeCode    = "claude-code"
+	clientClaudeDesktop = "claude-desktop"
+	clientCursor        = "cursor"
+	clientVSCode        = "vscode"
+	clientZed           = "zed"
+	clientCline         = "cline"
+	clientRoo           = "roo"
+)
+
+// clientNames lists the supported clients in help order
+var clientNames = []string{clientClaudeCode, clientClaudeDesktop, clientCursor, clientVSCode, clientZed, clientCline, clientRoo}
+
+// userScope is the pseudo project path of servers configured for a whole
+// client rather than for one project
+const userScope = "(user)"
+
+var clientName string
+
+// errNoClientConfig is returned by loaders that found none of a client's config files
+var errNoClientConfig = errors.New("no MCP config found")
+
+// mcpServersFile is the layout of client config files that keep a single
+// top-level mcpServers map, such as Claude Desktop's
+type mcpServersFile struct {
+	MCPServers map[string]MCPServer `json:"mcpServers"`
+}
+
+// validateClient checks the --client flag
+func validateClient() error {
+	for _, name := range clientNames {
+		if name == clientName {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --client %q, expected one of: %s", clientName, strings.Join(clientNames, ", "))
+}
+
+// clientConfigPath returns the config file of a client: the first of its
+// candidate locations that exists, or the preferred one when none does
+func clientConfigPath(client string) (string, error) {
+	candidates, err := clientConfigCandidates(client)
+	if err != nil {
+		return "", err
+	}
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return candidates[0], nil
+}
+
+// loadClientConfig reads a client's config file into the ClaudeConfig model,
+// so every command works the same whichever client configured the servers
+func loadClientConfig(client, path string) (*ClaudeConfig, error) {
+	switch client {
+	case clientClaudeCode:
+		return loadClaudeCodeConfig(path)
+	case clientClaudeDesktop:
+		var file mcpServersFile
+		if err := readJSONFile(path, &file); err != nil {
+			return nil, err
+		}
+		return userScopeConfig(file.MCPServers), nil
+	case clientCline, clientRoo:
+		var file mcpServersFile
+		if err := readJSONFile(path, &file); err != nil {
+			return nil, err
+		}
+		// Cline names the Streamable HTTP transport streamableHttp
+		for name, server := range file.MCPServers {
+			if server.Type == "streamableHttp" {
+				server.Type = "http"
+				file.MCPServers[name] = server
+			}
+		}
+		return userScopeConfig(file.MCPServers), nil
+	case clientCursor:
+		return loadCursorConfig(path)
+	case clientVSCode:
+		return loadVSCodeConfig(path)
+	case clientZed:
+		return loadZedConfig(path)
+	default:
+		return nil, fmt.Errorf("unsupported client: %s", client)
+	}
+}
+
+// loadCursorConfig reads Cursor's global mcp.json and the .cursor/mcp.json of
+// the project containing the working directory. Either may be missing.
+func loadCursorConfig(globalPath string) (*ClaudeConfig, error) {
+	config := &ClaudeConfig{Projects: make(map[string]ProjectConfig)}
+
+	var global mcpServersFile
+	err := readJSONFile(globalPath, &global)
+	if err == nil {
+		config.Projects[userScope] = ProjectConfig{MCPServers: withScope(inferServerTypes(global.MCPServers), scopeUser)}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// Below the home directory the search would find the global file itself
+	if projectFile, ok := findUp(filepath.Join(".cursor", "mcp.json")); ok && !sameFile(projectFile, globalPath) {
+		var project mcpServersFile
+		if err := readJSONFile(projectFile, &project); err != nil {
+			return nil, err
+		}
+		projectPath := filepath.Dir(filepath.Dir(projectFile))
+		config.Projects[projectPath] = ProjectConfig{MCPServers: withScope(inferServerTypes(project.MCPServers), scopeProject)}
+	}
+
+	if len(config.Projects) == 0 {
+		return nil, fmt.Errorf("%w: %s does not exist and no .cursor/mcp.json was found above the working directory", errNoClientConfig, globalPath)
+	}
+	return config, nil
+}
+
+// discoverClients loads the config of every supported client found on this
+// machine, keyed by client name. Clients without a config are skipped and
+// configs that fail to load are reported on stderr.
+func discoverClients() map[string]*ClaudeConfig {
+	configs := make(map[string]*ClaudeConfig)
+	for _, client := range clientNames {
+		path, err := clientConfigPath(client)
+		if err != nil {
+			continue
+		}
+		config, err := loadClientConfig(client, path)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errNoClientConfig) {
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", client, err)
+			continue
+		}
+		configs[client] = config
+	}
+	return configs
+}
+
+// aggregateClients lists the servers of every client, merging servers with the
+// same name and the same definition into one entry naming all their clients
+func aggregateClients(configs map[string]*ClaudeConfig) []*ServerInfo {
+	merged := make(map[string]*ServerInfo)
+	var infos []*ServerInfo
+	for _, client := range clientNames {
+		config, ok := configs[client]
+		if !ok {
+			continue
+		}
+		for _, info := range aggregateServers(config) {
+			server, err := findServer(config, info.Name)
+			if err != nil {
+				continue
+			}
+			key := info.Name + "\x00" + serverFingerprint(server)
+			if existing, ok := merged[key]; ok {
+				existing.Clients = append(existing.Clients, client)
+				for _, project := range info.Projects {
+					if !slices.Contains(existing.Projects, project) {
+						existing.Projects = append(existing.Projects, project)
+					}
+				}
+				for _, scope := range info.Scopes {
+					if !slices.Contains(existing.Scopes, scope) {
+						existing.Scopes = append(existing.Scopes, scope)
+					}
+				}
+				continue
+			}
+			info.Clients = []string{client}
+			info.config = config
+			merged[key] = info
+			infos = append(infos, info)
+		}
+	}
+
+	for _, info := range infos {
+		sort.Strings(info.Projects)
+		sort.Strings(info.Scopes)
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// serverFingerprint identifies what a server definition runs, to find the same
+// server configured in several clients
+func serverFingerprint(server *MCPServer) string {
+	data, _ := json.Marshal(struct {
+		Type      string
+		Command   string
+		Args      []string
+		URL       string
+		Env       map[string]string
+		EnvFile   string
+		Cwd       string
+		Headers   map[string]string
+		BasicAuth *BasicAuth
+		TLSCert   string
+		TLSKey    string
+		TLSCA     string
+		Insecure  bool
+		Proxy     string
+	}{server.Type, server.Command, server.Args, server.URL, server.Env, server.EnvFile, server.Cwd, server.Headers, server.BasicAuth, server.TLSCert, server.TLSKey, server.TLSCA, server.InsecureSkipVerify, server.Proxy})
+	return string(data)
+}
+
+// zedContextServer is an entry of Zed's context_servers setting. Older Zed
+// versions nest the command as {"path", "args", "env"}.
+type zedContextServer struct {
+	Command json.RawMessage   `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+}
+
+// zedSettings is the part of Zed's settings.json describing MCP servers
+type zedSettings struct {
+	ContextServers map[string]zedContextServer `json:"context_servers"`
+}
+
+// loadZedConfig reads the context servers of Zed's user settings.json and of
+// the .zed/settings.json of the project containing the working directory
+func loadZedConfig(settingsPath string) (*ClaudeConfig, error) {
+	config := &ClaudeConfig{Projects: make(map[string]ProjectConfig)}
+
+	var user zedSettings
+	found, err := readJSONCFile(settingsPath, &user)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		config.Projects[userScope] = ProjectConfig{MCPServers: withScope(zedServers(user), scopeUser)}
+	}
+
+	if projectFile, ok := findUp(filepath.Join(".zed", "settings.json")); ok && !sameFile(projectFile, settingsPath) {
+		var project zedSettings
+		if _, err := readJSONCFile(projectFile, &project); err != nil {
+			return nil, err
+		}
+		config.Projects[filepath.Dir(filepath.Dir(projectFile))] = ProjectConfig{MCPServers: withScope(zedServers(project), scopeProject)}
+	}
+
+	if len(config.Projects) == 0 {
+		return nil, fmt.Errorf("%w: %s does not exist and no .zed/settings.json was found above the working directory", errNoClientConfig, settingsPath)
+	}
+	return config, nil
+}
+
+// zedServers maps Zed context servers to MCPServer
+func zedServers(settings zedSettings) map[string]MCPServer {
+	servers := make(map[string]MCPServer, len(settings.ContextServers))
+	for name, cs := range settings.ContextServers {
+		server := MCPServer{Args: cs.Args, URL: cs.URL, Env: cs.Env, Headers: cs.Headers}
+		var nested struct {
+			Path string            `json:"path"`
+			Args []string          `json:"args"`
+			Env  map[string]string `json:"env"`
+		}
+		if err := json.Unmarshal(cs.Command, &server.Command); err != nil && json.Unmarshal(cs.Command, &nested) == nil {
+			server.Command, server.Args, server.Env = nested.Path, nested.Args, nested.Env
+		}
+		servers[name] = server
+	}
+	return inferServerTypes(servers)
+}
+
+// findUp looks for a file at the given relative path in the working directory
+// and each of its parents, returning the nearest match
+func findUp(relPath string) (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		candidate := filepath.Join(dir, relPath)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// sameFile reports whether two paths name the same existing file
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// readJSONFile reads and decodes a config file
+func readJSONFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+	return nil
+}
+
+// userScopeConfig wraps servers configured for a whole client in a ClaudeConfig
+func userScopeConfig(servers map[string]MCPServer) *ClaudeConfig {
+	return &ClaudeConfig{
+		Projects: map[string]ProjectConfig{
+			userScope: {MCPServers: withScope(inferServerTypes(servers), scopeUser)},
+		},
+	}
+}
+
+// inferServerTypes fills in the type of servers that leave it implicit, as
+// most clients do: a command means stdio and a URL means HTTP
+func inferServerTypes(servers map[string]MCPServer) map[string]MCPServer {
+	for name, server := range servers {
+		if server.Type != "" {
+			continue
+		}
+		if server.Command != "" {
+			server.Type = "stdio"
+		} else if server.URL != "" {
+			server.Type = "http"
+		}
+		servers[name] = server
+	}
+	return servers
+}
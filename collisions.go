@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Collision is a tool name exposed by more than one server in a project
+type Collision struct {
+	Project string   `json:"project"`
+	Tool    string   `json:"tool"`
+	Servers []string `json:"servers"`
+}
+
+// CollisionReport is the result of checking every project for colliding tool names
+type CollisionReport struct {
+	Collisions []Collision       `json:"collisions"`
+	Errors     map[string]string `json:"errors,omitempty"`
+}
+
+func newCollisionsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "collisions [project-path]",
+		Short: "Find tools with the same name on different servers of a project",
+		Long: `Connect to the servers of each project and report tool names exposed by more
+than one of them. Tools from every server of a project share one namespace in
+the client, so identically named tools shadow each other and the model may
+call the wrong one.
+
+Without arguments every project is checked. The command exits with an error
+if any collision is found, unless --fail-on leaves out collisions.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			projects := config.Projects
+			if len(args) == 1 {
+				project, ok := config.Projects[args[0]]
+				if !ok {
+					return fmt.Errorf("project '%s' not found", args[0])
+				}
+				projects = map[string]ProjectConfig{args[0]: project}
+			}
+			// Collisions are a result, not a usage error
+			cmd.SilenceUsage = true
+			return findCollisions(config, projects)
+		},
+	}
+}
+
+func findCollisions(config *ClaudeConfig, projects map[string]ProjectConfig) error {
+	// Each server is contacted once, however many projects use it
+	nameSet := make(map[string]bool)
+	for _, project := range projects {
+		for name := range project.MCPServers {
+			nameSet[name] = true
+		}
+	}
+	names := make([]string, 0, len(nameSet))
+	for name := range nameSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	toolLists := make([][]ToolInfo, len(names))
+	errs := make([]error, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		toolLists[i], errs[i] = fetchTools(config, names[i])
+	})
+
+	report := &CollisionReport{Collisions: []Collision{}}
+	serverTools := make(map[string][]ToolInfo, len(names))
+	var unreachable []error
+	for i, name := range names {
+		if errs[i] != nil {
+			unreachable = append(unreachable, errs[i])
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = errs[i].Error()
+			continue
+		}
+		serverTools[name] = toolLists[i]
+	}
+
+	projectPaths := make([]string, 0, len(projects))
+	for projectPath := range projects {
+		projectPaths = append(projectPaths, projectPath)
+	}
+	sort.Strings(projectPaths)
+
+	for _, projectPath := range projectPaths {
+		owners := make(map[string][]string)
+		for name := range projects[projectPath].MCPServers {
+			for _, tool := range serverTools[name] {
+				owners[tool.Name] = append(owners[tool.Name], name)
+			}
+		}
+
+		tools := make([]string, 0, len(owners))
+		for tool, servers := range owners {
+			if len(servers) > 1 {
+				tools = append(tools, tool)
+			}
+		}
+		sort.Strings(tools)
+		for _, tool := range tools {
+			servers := owners[tool]
+			sort.Strings(servers)
+			report.Collisions = append(report.Collisions, Collision{Project: projectPath, Tool: tool, Servers: servers})
+		}
+	}
+
+	if err := printCollisions(report, len(projectPaths), len(names)); err != nil {
+		return err
+	}
+	if len(report.Collisions) > 0 && failsOn(failCollisions, true) {
+		return withExitCode(exitCollisions, fmt.Errorf("%d colliding tool names", len(report.Collisions)))
+	}
+	if len(unreachable) > 0 && failsOn(failUnreachable, false) {
+		return withExitCode(failureExitCode(unreachable), fmt.Errorf("%d of %d servers unreachable", len(unreachable), len(names)))
+	}
+	return nil
+}
+
+// fetchTools connects to a server and lists its tools
+func fetchTools(config *ClaudeConfig, serverName string) ([]ToolInfo, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return listTools(ctx, conn)
+}
+
+func printCollisions(report *CollisionReport, projects, servers int) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(report)
+	case outputGitHub:
+		return writeAnnotations(collisionAnnotations(report))
+	case outputCSV:
+		rows := make([][]string, 0, len(report.Collisions))
+		for _, c := range report.Collisions {
+			rows = append(rows, []string{c.Project, c.Tool, strings.Join(c.Servers, "\n")})
+		}
+		return writeCSV([]string{"PROJECT", "TOOL", "SERVERS"}, rows)
+	}
+
+	if len(report.Collisions) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROJECT\tTOOL\tSERVERS")
+		for _, c := range report.Collisions {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", c.Project, c.Tool, strings.Join(c.Servers, ", "))
+		}
+		w.Flush()
+	} else {
+		fmt.Println("No colliding tool names.")
+	}
+
+	if len(report.Errors) > 0 {
+		fmt.Fprintln(os.Stderr)
+		names := make([]string, 0, len(report.Errors))
+		for name := range report.Errors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "%s: %s (tools not checked)\n", name, report.Errors[name])
+		}
+	}
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d collisions | %d projects | %d/%d servers checked\n", len(report.Collisions), projects, servers-len(report.Errors), servers)
+	return nil
+}
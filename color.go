@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Values of --color
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+var colorMode string
+
+// colorEnabled is resolved from --color: auto colors a terminal stdout unless
+// NO_COLOR is set (https://no-color.org) or TERM is dumb
+var colorEnabled bool
+
+// ANSI codes used by paint. They are all five bytes long: tabwriter counts
+// escape codes as width, so a table column only lines up when every cell of
+// it, header included, is painted, with ansiDefault where no color is wanted.
+const (
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiDefault = "\033[39m"
+	ansiReset   = "\033[0m"
+)
+
+func resolveColor() error {
+	switch colorMode {
+	case colorAlways:
+		colorEnabled = true
+	case colorNever:
+		colorEnabled = false
+	case colorAuto:
+		colorEnabled = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && term.IsTerminal(int(os.Stdout.Fd()))
+	default:
+		return fmt.Errorf("invalid --color %q, expected auto, always or never", colorMode)
+	}
+	return nil
+}
+
+// paint wraps s in an ANSI color when color is enabled
+func paint(s, code string) string {
+	if !colorEnabled {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// statusColors maps the statuses of doctor, ping, probes, validate, audit and risk to their
+// colors; probeOK and probeError share the values of doctorOK and severityError
+var statusColors = map[string]string{
+	doctorOK:        ansiGreen,
+	doctorWarn:      ansiYellow,
+	doctorFail:      ansiRed,
+	"unreachable":   ansiRed,
+	severityError:   ansiRed,
+	severityWarning: ansiYellow,
+	auditHigh:       ansiRed,
+	auditMedium:     ansiYellow,
+	riskReadOnly:    ansiGreen,
+	riskWrite:       ansiYellow,
+	riskNetwork:     ansiYellow,
+	riskDestructive: ansiRed,
+	riskExec:        ansiRed,
+}
+
+// paintStatus colors a status green, yellow or red by what it means
+func paintStatus(display, status string) string {
+	code, ok := statusColors[status]
+	if !ok {
+		code = ansiDefault
+	}
+	return paint(display, code)
+}
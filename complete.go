@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CompletionResult is a server's answer to a completion/complete request
+type CompletionResult struct {
+	Values  []string `json:"values"`
+	Total   *int     `json:"total,omitempty"`
+	HasMore bool     `json:"hasMore,omitempty"`
+}
+
+func newCompleteCmd() *cobra.Command {
+	var prompt, resource, argument, value string
+	var contextArgs []string
+	completeCmd := &cobra.Command{
+		Use:   "complete <server-name> (--prompt <name> | --resource <uri-template>) --arg <name>",
+		Short: "Request argument completions from a server",
+		Long: `Send a completion/complete request for an argument of a prompt or a
+variable of a resource template and print the suggested values, to test a
+server's completion handlers.
+
+--value is the partial input typed so far. Values of other arguments that
+were already filled in can be passed with --context key=value (repeatable).
+
+Examples:
+  mcpinspect complete my-server --prompt greet --arg who --value al
+  mcpinspect complete my-server --resource 'file:///{path}' --arg path --value src/`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (prompt == "") == (resource == "") {
+				return fmt.Errorf("specify either --prompt or --resource")
+			}
+			contextValues, err := parseKeyValues(contextArgs)
+			if err != nil {
+				return err
+			}
+
+			ref := map[string]string{"type": "ref/prompt", "name": prompt}
+			if resource != "" {
+				ref = map[string]string{"type": "ref/resource", "uri": resource}
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return requestCompletions(config, args[0], ref, argument, value, contextValues)
+		},
+	}
+	completeCmd.Flags().StringVar(&prompt, "prompt", "", "complete an argument of this prompt")
+	completeCmd.Flags().StringVar(&resource, "resource", "", "complete a variable of this resource URI template")
+	completeCmd.Flags().StringVar(&argument, "arg", "", "name of the argument or template variable to complete")
+	completeCmd.Flags().StringVar(&value, "value", "", "partial value typed so far")
+	completeCmd.Flags().StringArrayVar(&contextArgs, "context", nil, "already-resolved argument as key=value (repeatable)")
+	completeCmd.MarkFlagRequired("arg")
+	return completeCmd
+}
+
+func requestCompletions(config *ClaudeConfig, serverName string, ref map[string]string, argument, value string, contextValues map[string]string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, ok := conn.Capabilities()["completions"]; !ok {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not advertise the completions capability\n", serverName)
+	}
+
+	params := map[string]interface{}{
+		"ref":      ref,
+		"argument": map[string]string{"name": argument, "value": value},
+	}
+	if len(contextValues) > 0 {
+		params["context"] = map[string]interface{}{"arguments": contextValues}
+	}
+
+	raw, err := conn.Session.Request(ctx, "completion/complete", params)
+	if err != nil {
+		return fmt.Errorf("failed to request completions: %w", err)
+	}
+	var result struct {
+		Completion CompletionResult `json:"completion"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("failed to decode completions: %w", err)
+	}
+	completion := result.Completion
+	if completion.Values == nil {
+		completion.Values = []string{}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(completion)
+	case outputCSV:
+		rows := make([][]string, 0, len(completion.Values))
+		for _, v := range completion.Values {
+			rows = append(rows, []string{v})
+		}
+		return writeCSV([]string{"VALUE"}, rows)
+	}
+
+	if len(completion.Values) == 0 {
+		fmt.Println("No completions.")
+	}
+	for _, v := range completion.Values {
+		fmt.Println(v)
+	}
+
+	// Print summary
+	summary := fmt.Sprintf("%d values", len(completion.Values))
+	if completion.Total != nil {
+		summary = fmt.Sprintf("%d of %d values", len(completion.Values), *completion.Total)
+	}
+	if completion.HasMore {
+		summary += " (more available)"
+	}
+	target := "prompt " + ref["name"]
+	if ref["type"] == "ref/resource" {
+		target = "resource " + ref["uri"]
+	}
+	fmt.Println()
+	fmt.Println(strings.Join([]string{summary, target + ", argument " + argument, formatServerInfo(conn.Init)}, " | "))
+	return nil
+}
@@ -4,11 +4,26 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 )
 
+// Scopes a server definition can come from, in increasing precedence
+const (
+	scopeUser    = "user"
+	scopeProject = "project"
+	scopeLocal   = "local"
+)
+
+// scopePrecedence ranks scopes so a local definition wins over a project or user one
+var scopePrecedence = map[string]int{scopeUser: 1, scopeProject: 2, scopeLocal: 3}
+
 // ClaudeConfig represents the structure of .claude.json
 type ClaudeConfig struct {
 	Projects map[string]ProjectConfig `json:"projects"`
+
+	// MCPServers are the user-scoped servers available in every project. After
+	// loading they are listed under the (user) pseudo project like other clients'.
+	MCPServers map[string]MCPServer `json:"mcpServers,omitempty"`
 }
 
 // ProjectConfig represents a project's configuration
@@ -22,9 +37,73 @@ type MCPServer struct {
 	Command string   `json:"command,omitempty"`
 	Args    []string `json:"args,omitempty"`
 	URL     string   `json:"url,omitempty"`
+
+	Env map[string]string `json:"env,omitempty"`
+
+	// EnvFile is a dotenv file loaded into a stdio server's environment
+	EnvFile string `json:"envFile,omitempty"`
+
+	// Cwd is the working directory of a stdio server, relative to its project
+	Cwd string `json:"cwd,omitempty"`
+
+	// Headers are extra HTTP headers for remote servers
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// BasicAuth sends HTTP Basic credentials to a remote server
+	BasicAuth *BasicAuth `json:"basicAuth,omitempty"`
+
+	// TLSCert and TLSKey are the PEM client certificate and key presented to
+	// remote servers that require mutual TLS
+	TLSCert string `json:"tlsCert,omitempty"`
+	TLSKey  string `json:"tlsKey,omitempty"`
+
+	// TLSCA is a PEM bundle of extra CAs trusted for the server's certificate,
+	// and InsecureSkipVerify skips that verification, for dev servers with
+	// self-signed certificates
+	TLSCA              string `json:"tlsCA,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+
+	// Proxy is the URL of the HTTP or SOCKS5 proxy used to reach a remote server
+	// instead of the one of the environment
+	Proxy string `json:"proxy,omitempty"`
+
+	// Disabled and AutoApprove are Cline/Roo settings: whether the client
+	// starts the server and which tools it calls without asking
+	Disabled    bool     `json:"disabled,omitempty"`
+	AutoApprove []string `json:"autoApprove,omitempty"`
+
+	// Scope tells where the server was defined: local, project or user
+	Scope string `json:"-"`
+
+	// project is the directory of the project defining the server, set by findServer
+	project string
+
+	// inputs are the VS Code ${input:id} values to ask for before starting the server
+	inputs []VSCodeInput
+}
+
+// BasicAuth are HTTP Basic credentials, for servers behind basic-auth reverse proxies
+type BasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// withScope marks servers as defined in the given scope
+func withScope(servers map[string]MCPServer, scope string) map[string]MCPServer {
+	for name, server := range servers {
+		server.Scope = scope
+		servers[name] = server
+	}
+	return servers
 }
 
 func loadConfig(path string) (*ClaudeConfig, error) {
+	config, err := loadClientConfig(clientName, path)
+	return config, withExitCode(exitConfig, err)
+}
+
+// loadClaudeCodeConfig reads .claude.json and the project's .mcp.json
+func loadClaudeCodeConfig(path string) (*ClaudeConfig, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -34,6 +113,44 @@ func loadConfig(path string) (*ClaudeConfig, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
+	for projectPath, project := range config.Projects {
+		config.Projects[projectPath] = ProjectConfig{MCPServers: withScope(project.MCPServers, scopeLocal)}
+	}
+	if len(config.MCPServers) > 0 {
+		if config.Projects == nil {
+			config.Projects = make(map[string]ProjectConfig)
+		}
+		config.Projects[userScope] = ProjectConfig{MCPServers: withScope(inferServerTypes(config.MCPServers), scopeUser)}
+		config.MCPServers = nil
+	}
 
+	if err := mergeProjectMCPFile(&config); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
+
+// mergeProjectMCPFile adds the servers of the nearest .mcp.json above the
+// working directory, the project scope checked into repositories. Servers of
+// the same project in .claude.json (local scope) take precedence.
+func mergeProjectMCPFile(config *ClaudeConfig) error {
+	path, ok := findUp(".mcp.json")
+	if !ok {
+		return nil
+	}
+	var file mcpServersFile
+	if err := readJSONFile(path, &file); err != nil {
+		return err
+	}
+
+	if config.Projects == nil {
+		config.Projects = make(map[string]ProjectConfig)
+	}
+	projectPath := filepath.Dir(path)
+	servers := withScope(inferServerTypes(file.MCPServers), scopeProject)
+	for name, server := range config.Projects[projectPath].MCPServers {
+		servers[name] = server
+	}
+	config.Projects[projectPath] = ProjectConfig{MCPServers: servers}
+	return nil
+}
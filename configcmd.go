@@ -0,0 +1,873 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Severities of config issues
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// ConfigIssue is a problem found in one server definition
+type ConfigIssue struct {
+	Project  string `json:"project"`
+	Server   string `json:"server"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Check and edit the MCP server configuration",
+	}
+	configCmd.AddCommand(newConfigValidateCmd(), newConfigAddCmd(), newConfigRemoveCmd(), newConfigRenameCmd(), newConfigMoveCmd(), newConfigDedupeCmd(), newConfigMigrateCmd())
+	return configCmd
+}
+
+func newConfigAddCmd() *cobra.Command {
+	var (
+		serverType string
+		command    string
+		serverURL  string
+		env        []string
+		project    string
+		user       bool
+		force      bool
+	)
+	addCmd := &cobra.Command{
+		Use:   "add <name> [--type stdio] --command cmd [-- args...] | --url url",
+		Short: "Add a server to the Claude Code config",
+		Long: `Write a new server definition into ~/.claude.json (or the file given with
+--config), keeping every other field of the file as it is.
+
+The server is added to the project in the working directory, or the one given
+with --project, or with --user to the user-scoped servers available in every
+project. Arguments after -- are the command's arguments. The type defaults to
+stdio with --command and to http with --url. An existing server of the same
+name is only replaced with --force.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			positional := args
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				positional = args[:dash]
+			}
+			if len(positional) != 1 {
+				return fmt.Errorf("expected exactly one server name before --")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			var commandArgs []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				commandArgs = args[dash:]
+			}
+
+			server := MCPServer{Type: serverType, Command: command, Args: commandArgs, URL: serverURL}
+			if server.Type == "" {
+				server = inferServerTypes(map[string]MCPServer{name: server})[name]
+			}
+			if len(env) > 0 {
+				server.Env = make(map[string]string, len(env))
+				for _, pair := range env {
+					key, value, ok := strings.Cut(pair, "=")
+					if !ok || key == "" {
+						return fmt.Errorf("invalid --env %q, expected KEY=VALUE", pair)
+					}
+					server.Env[key] = value
+				}
+			}
+			for _, issue := range checkServer(server, false) {
+				if issue.Severity == severityError {
+					return fmt.Errorf("invalid server %s: %s", name, issue.Message)
+				}
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", issue.Message)
+			}
+			// The command may be installed later, so it is only worth a warning
+			if server.Type == "stdio" {
+				if _, err := resolveCommand(&server); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+			// Config file problems are not usage errors
+			cmd.SilenceUsage = true
+
+			key, err := projectKey(project, user)
+			if err != nil {
+				return err
+			}
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			servers, err := file.servers(key)
+			if err != nil {
+				return err
+			}
+			if _, exists := servers[name]; exists && !force {
+				return fmt.Errorf("server %s already exists in %s, use --force to replace it", name, key)
+			}
+
+			raw, err := marshalRaw(server)
+			if err != nil {
+				return fmt.Errorf("failed to encode server: %w", err)
+			}
+			servers[name] = raw
+			if err := file.setServers(key, servers); err != nil {
+				return err
+			}
+			if err := file.save(); err != nil {
+				return err
+			}
+			fmt.Printf("Added %s server %s to %s in %s\n", server.Type, name, key, configPath)
+			return nil
+		},
+	}
+	addCmd.Flags().StringVar(&serverType, "type", "", "server type: stdio, http or sse (default inferred from --command or --url)")
+	addCmd.Flags().StringVar(&command, "command", "", "command that starts a stdio server")
+	addCmd.Flags().StringVar(&serverURL, "url", "", "URL of an http or sse server")
+	addCmd.Flags().StringArrayVar(&env, "env", nil, "environment variable for the server as KEY=VALUE (repeatable)")
+	addCmd.Flags().StringVar(&project, "project", "", "project directory to add the server to (default the working directory)")
+	addCmd.Flags().BoolVar(&user, "user", false, "add the server to the user scope, available in every project")
+	addCmd.Flags().BoolVar(&force, "force", false, "replace an existing server with the same name")
+	addCmd.MarkFlagsMutuallyExclusive("command", "url")
+	addCmd.MarkFlagsMutuallyExclusive("project", "user")
+	return addCmd
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check server definitions for structural problems",
+		Long: `Parse the config and check every server definition without starting it:
+unknown or missing types, stdio servers without a command or whose command
+cannot be found or executed, empty arguments, remote servers without a URL
+or with a malformed one, and credentials written into the command, args, env,
+headers or URL. A credential is an error in a project's shared .mcp.json,
+which is meant to be committed, and a warning in private configs.
+
+Each issue is printed with its project and server name. The command fails
+when at least one issue is an error rather than a warning.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Issues are a result, not a usage error
+			cmd.SilenceUsage = true
+			return validateConfig(config)
+		},
+	}
+}
+
+func newConfigRemoveCmd() *cobra.Command {
+	var (
+		project     string
+		user        bool
+		allProjects bool
+		dryRun      bool
+	)
+	removeCmd := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a server from the Claude Code config",
+		Long: `Delete a server definition from ~/.claude.json (or the file given with
+--config), keeping every other field of the file as it is.
+
+The server is removed from the project in the working directory, or the one
+given with --project, or with --user from the user-scoped servers. With
+--all-projects it is removed everywhere it is defined. --dry-run prints the
+resulting diff of the file without writing it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			// A missing server is a result, not a usage error
+			cmd.SilenceUsage = true
+
+			var keys []string
+			if allProjects {
+				if keys, err = file.projectKeys(); err != nil {
+					return err
+				}
+				keys = append(keys, userScope)
+			} else {
+				key, err := projectKey(project, user)
+				if err != nil {
+					return err
+				}
+				keys = []string{key}
+			}
+
+			var removed []string
+			for _, key := range keys {
+				servers, err := file.servers(key)
+				if err != nil {
+					return err
+				}
+				if _, ok := servers[name]; !ok {
+					continue
+				}
+				delete(servers, name)
+				if err := file.setServers(key, servers); err != nil {
+					return err
+				}
+				removed = append(removed, key)
+			}
+			if len(removed) == 0 && allProjects {
+				return fmt.Errorf("server %s is not defined in any project", name)
+			}
+			if len(removed) == 0 {
+				return fmt.Errorf("server %s is not defined in %s", name, keys[0])
+			}
+
+			return saveConfigEdit(file, dryRun, func() {
+				for _, key := range removed {
+					fmt.Printf("Removed server %s from %s in %s\n", name, key, configPath)
+				}
+			})
+		},
+	}
+	removeCmd.Flags().StringVar(&project, "project", "", "project directory to remove the server from (default the working directory)")
+	removeCmd.Flags().BoolVar(&user, "user", false, "remove the server from the user scope")
+	removeCmd.Flags().BoolVar(&allProjects, "all-projects", false, "remove the server from every project and the user scope")
+	removeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff of the config file instead of writing it")
+	removeCmd.MarkFlagsMutuallyExclusive("project", "user", "all-projects")
+	return removeCmd
+}
+
+func newConfigRenameCmd() *cobra.Command {
+	var (
+		project     string
+		user        bool
+		allProjects bool
+		dryRun      bool
+	)
+	renameCmd := &cobra.Command{
+		Use:   "rename <name> <new-name>",
+		Short: "Rename a server in the Claude Code config",
+		Long: `Rename a server of the project in the working directory, or the one given
+with --project, or with --user a user-scoped server. With --all-projects it
+is renamed everywhere it is defined. The new name must not be taken.
+--dry-run prints the resulting diff of the file without writing it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, newName := args[0], args[1]
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			// A missing or taken name is a result, not a usage error
+			cmd.SilenceUsage = true
+
+			var keys []string
+			if allProjects {
+				if keys, err = file.projectKeys(); err != nil {
+					return err
+				}
+				keys = append(keys, userScope)
+			} else {
+				key, err := projectKey(project, user)
+				if err != nil {
+					return err
+				}
+				keys = []string{key}
+			}
+
+			var renamed []string
+			for _, key := range keys {
+				servers, err := file.servers(key)
+				if err != nil {
+					return err
+				}
+				definition, ok := servers[name]
+				if !ok {
+					continue
+				}
+				if _, taken := servers[newName]; taken {
+					return fmt.Errorf("server %s already exists in %s", newName, key)
+				}
+				delete(servers, name)
+				servers[newName] = definition
+				if err := file.setServers(key, servers); err != nil {
+					return err
+				}
+				renamed = append(renamed, key)
+			}
+			if len(renamed) == 0 && allProjects {
+				return fmt.Errorf("server %s is not defined in any project", name)
+			}
+			if len(renamed) == 0 {
+				return fmt.Errorf("server %s is not defined in %s", name, keys[0])
+			}
+
+			return saveConfigEdit(file, dryRun, func() {
+				for _, key := range renamed {
+					fmt.Printf("Renamed server %s to %s in %s\n", name, newName, key)
+				}
+			})
+		},
+	}
+	renameCmd.Flags().StringVar(&project, "project", "", "project directory of the server (default the working directory)")
+	renameCmd.Flags().BoolVar(&user, "user", false, "rename a user-scoped server")
+	renameCmd.Flags().BoolVar(&allProjects, "all-projects", false, "rename the server in every project and the user scope")
+	renameCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff of the config file instead of writing it")
+	renameCmd.MarkFlagsMutuallyExclusive("project", "user", "all-projects")
+	return renameCmd
+}
+
+func newConfigMoveCmd() *cobra.Command {
+	var (
+		from   string
+		to     string
+		keep   bool
+		force  bool
+		dryRun bool
+	)
+	moveCmd := &cobra.Command{
+		Use:   "move <name> --to <project|user> [--from <project|user>]",
+		Short: "Move or copy a server between projects and the user scope",
+		Long: `Move a server definition from one project to another, or between a project
+and the user scope. --from and --to take a project directory or "user";
+--from defaults to the project in the working directory.
+
+With --copy the server is kept where it is. A server of the same name at the
+destination is only replaced with --force. --dry-run prints the resulting
+diff of the file without writing it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			fromKey, err := locationKey(from)
+			if err != nil {
+				return err
+			}
+			toKey, err := locationKey(to)
+			if err != nil {
+				return err
+			}
+			if fromKey == toKey {
+				return fmt.Errorf("--from and --to are both %s", fromKey)
+			}
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			// A missing or taken name is a result, not a usage error
+			cmd.SilenceUsage = true
+
+			source, err := file.servers(fromKey)
+			if err != nil {
+				return err
+			}
+			definition, ok := source[name]
+			if !ok {
+				return fmt.Errorf("server %s is not defined in %s", name, fromKey)
+			}
+			destination, err := file.servers(toKey)
+			if err != nil {
+				return err
+			}
+			if _, taken := destination[name]; taken && !force {
+				return fmt.Errorf("server %s already exists in %s, use --force to replace it", name, toKey)
+			}
+
+			destination[name] = definition
+			if err := file.setServers(toKey, destination); err != nil {
+				return err
+			}
+			verb := "Copied"
+			if !keep {
+				verb = "Moved"
+				delete(source, name)
+				if err := file.setServers(fromKey, source); err != nil {
+					return err
+				}
+			}
+
+			return saveConfigEdit(file, dryRun, func() {
+				fmt.Printf("%s server %s from %s to %s\n", verb, name, fromKey, toKey)
+			})
+		},
+	}
+	moveCmd.Flags().StringVar(&from, "from", "", `project directory or "user" to take the server from (default the working directory)`)
+	moveCmd.Flags().StringVar(&to, "to", "", `project directory or "user" to put the server in`)
+	moveCmd.Flags().BoolVar(&keep, "copy", false, "keep the server at its original location")
+	moveCmd.Flags().BoolVar(&force, "force", false, "replace a server with the same name at the destination")
+	moveCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff of the config file instead of writing it")
+	moveCmd.MarkFlagRequired("to")
+	return moveCmd
+}
+
+// ServerLocation is where a server definition lives in the config
+type ServerLocation struct {
+	Project string `json:"project"`
+	Name    string `json:"name"`
+}
+
+// DuplicateGroup is one server definition repeated in several places
+type DuplicateGroup struct {
+	Server      MCPServer        `json:"server"`
+	Definitions []ServerLocation `json:"definitions"`
+	raw         json.RawMessage
+}
+
+func newConfigDedupeCmd() *cobra.Command {
+	var (
+		rewrite bool
+		dryRun  bool
+	)
+	dedupeCmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Find servers defined identically in several projects or under several names",
+		Long: `Report server definitions of ~/.claude.json that are identical (same type,
+command, arguments, URL and environment) but repeated in several projects or
+under different names.
+
+With --rewrite each repeated definition is replaced by a single user-scoped
+server, available in every project. It keeps the name of an existing
+user-scoped definition, or else the most common name. Projects where that
+name already means a different server keep their own copy. Renamed servers
+expose their tools under the new name. --dry-run prints the resulting diff
+of the file without writing it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			// Config file problems are not usage errors
+			cmd.SilenceUsage = true
+
+			groups, err := findDuplicates(file)
+			if err != nil {
+				return err
+			}
+			if !rewrite {
+				return printDuplicates(groups)
+			}
+			merged := 0
+			for _, group := range groups {
+				ok, err := mergeDuplicates(file, group)
+				if err != nil {
+					return err
+				}
+				if ok {
+					merged++
+				}
+			}
+			return saveConfigEdit(file, dryRun, func() {
+				fmt.Printf("Merged %d repeated servers into the user scope of %s\n", merged, configPath)
+			})
+		},
+	}
+	dedupeCmd.Flags().BoolVar(&rewrite, "rewrite", false, "replace each repeated definition with a single user-scoped server")
+	dedupeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "with --rewrite, print the diff of the config file instead of writing it")
+	return dedupeCmd
+}
+
+// findDuplicates groups the servers of every project and the user scope by
+// what they run, keeping groups with more than one definition
+func findDuplicates(file *configFile) ([]*DuplicateGroup, error) {
+	keys, err := file.projectKeys()
+	if err != nil {
+		return nil, err
+	}
+	keys = append(keys, userScope)
+
+	byFingerprint := make(map[string]*DuplicateGroup)
+	var groups []*DuplicateGroup
+	for _, key := range keys {
+		servers, err := file.servers(key)
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(servers))
+		for name := range servers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			var server MCPServer
+			if err := json.Unmarshal(servers[name], &server); err != nil {
+				return nil, fmt.Errorf("failed to parse server %s of %s: %w", name, key, err)
+			}
+			server = inferServerTypes(map[string]MCPServer{name: server})[name]
+			// Moved to the user scope, it would run something else
+			if key != userScope && projectRelative(&server, key) {
+				continue
+			}
+			fingerprint := serverFingerprint(&server)
+			group, ok := byFingerprint[fingerprint]
+			if !ok {
+				group = &DuplicateGroup{Server: server, raw: servers[name]}
+				byFingerprint[fingerprint] = group
+				groups = append(groups, group)
+			}
+			group.Definitions = append(group.Definitions, ServerLocation{Project: key, Name: name})
+		}
+	}
+
+	duplicates := []*DuplicateGroup{}
+	for _, group := range groups {
+		if len(group.Definitions) > 1 {
+			duplicates = append(duplicates, group)
+		}
+	}
+	return duplicates, nil
+}
+
+// projectRelative reports whether a stdio server depends on the project that
+// defines it: a relative command, cwd or envFile resolves against the project,
+// and without a cwd it runs in the project, where an argument may name one of
+// its files, as in node dist/index.js
+func projectRelative(server *MCPServer, project string) bool {
+	if server.Type != "stdio" {
+		return false
+	}
+	relative := func(path string) bool {
+		return path != "" && !filepath.IsAbs(path) && path != "~" && !strings.HasPrefix(path, "~/")
+	}
+	if relative(server.Cwd) || relative(server.EnvFile) {
+		return true
+	}
+	if strings.ContainsRune(server.Command, '/') && relative(server.Command) {
+		return true
+	}
+	if server.Cwd != "" {
+		return false
+	}
+	for _, arg := range server.Args {
+		if relative(arg) && !strings.HasPrefix(arg, "-") {
+			if _, err := os.Stat(filepath.Join(project, arg)); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mergeDuplicates replaces the definitions of a group with one user-scoped
+// server, reporting whether it did
+func mergeDuplicates(file *configFile, group *DuplicateGroup) (bool, error) {
+	name := canonicalName(group)
+	user, err := file.servers(userScope)
+	if err != nil {
+		return false, err
+	}
+	if _, taken := user[name]; taken && !group.hasDefinition(userScope, name) {
+		fmt.Fprintf(os.Stderr, "Warning: skipping %s, the user scope already has a different server named %s\n", strings.Join(group.names(), ", "), name)
+		return false, nil
+	}
+
+	for _, location := range group.Definitions {
+		servers, err := file.servers(location.Project)
+		if err != nil {
+			return false, err
+		}
+		// A project defining the name as something else would shadow the user server
+		if location.Name != name {
+			if _, taken := servers[name]; taken && !group.hasDefinition(location.Project, name) {
+				continue
+			}
+		}
+		delete(servers, location.Name)
+		if err := file.setServers(location.Project, servers); err != nil {
+			return false, err
+		}
+	}
+
+	user, err = file.servers(userScope)
+	if err != nil {
+		return false, err
+	}
+	user[name] = group.raw
+	return true, file.setServers(userScope, user)
+}
+
+// canonicalName is the name of the user-scoped definition, or else the most
+// common name in the group, alphabetically first on a tie
+func canonicalName(group *DuplicateGroup) string {
+	counts := make(map[string]int)
+	for _, location := range group.Definitions {
+		if location.Project == userScope {
+			return location.Name
+		}
+		counts[location.Name]++
+	}
+	best := ""
+	for name, count := range counts {
+		if best == "" || count > counts[best] || (count == counts[best] && name < best) {
+			best = name
+		}
+	}
+	return best
+}
+
+// hasDefinition reports whether the group includes the server name in project
+func (g *DuplicateGroup) hasDefinition(project, name string) bool {
+	for _, location := range g.Definitions {
+		if location.Project == project && location.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the distinct names the group's server is defined under
+func (g *DuplicateGroup) names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, location := range g.Definitions {
+		if !seen[location.Name] {
+			seen[location.Name] = true
+			names = append(names, location.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// formatDefinitions lists where a group is defined as project: name pairs
+func formatDefinitions(group *DuplicateGroup) string {
+	parts := make([]string, len(group.Definitions))
+	for i, location := range group.Definitions {
+		parts[i] = location.Project + ": " + location.Name
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatTarget shows what a server runs: its command line or URL
+func formatTarget(server MCPServer) string {
+	if server.Type == "stdio" {
+		return strings.TrimSpace(server.Command + " " + strings.Join(server.Args, " "))
+	}
+	return server.URL
+}
+
+func printDuplicates(groups []*DuplicateGroup) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(groups)
+	case outputCSV:
+		rows := make([][]string, 0, len(groups))
+		for _, group := range groups {
+			rows = append(rows, []string{strings.Join(group.names(), ", "), group.Server.Type, formatTarget(group.Server), formatDefinitions(group)})
+		}
+		return writeCSV([]string{"NAMES", "TYPE", "TARGET", "DEFINITIONS"}, rows)
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No repeated server definitions found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAMES\tTYPE\tTARGET\tDEFINITIONS")
+	definitions := 0
+	for _, group := range groups {
+		definitions += len(group.Definitions)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", strings.Join(group.names(), ", "), group.Server.Type, formatTarget(group.Server), formatDefinitions(group))
+	}
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d definitions of %d servers | run with --rewrite to merge them into the user scope\n", definitions, len(groups))
+	return nil
+}
+
+// saveConfigEdit writes an edited config and reports what changed, or with
+// dryRun only prints the diff the edit would make
+func saveConfigEdit(file *configFile, dryRun bool, report func()) error {
+	if dryRun {
+		diff, err := file.diff()
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		return nil
+	}
+	if err := file.save(); err != nil {
+		return err
+	}
+	report()
+	return nil
+}
+
+func validateConfig(config *ClaudeConfig) error {
+	issues := []ConfigIssue{}
+	servers := 0
+	projectPaths := make([]string, 0, len(config.Projects))
+	for projectPath := range config.Projects {
+		projectPaths = append(projectPaths, projectPath)
+	}
+	sort.Strings(projectPaths)
+
+	for _, projectPath := range projectPaths {
+		project := config.Projects[projectPath]
+		names := make([]string, 0, len(project.MCPServers))
+		for name := range project.MCPServers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			servers++
+			server := project.MCPServers[name]
+			if projectPath != userScope {
+				server.project = projectPath
+			}
+			for _, problem := range append(checkServer(server, true), checkSecrets(server)...) {
+				problem.Project = projectPath
+				problem.Server = name
+				issues = append(issues, problem)
+			}
+		}
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		if issue.Severity == severityError {
+			errorCount++
+		}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		if err := writeJSON(issues); err != nil {
+			return err
+		}
+	case outputGitHub:
+		if err := writeAnnotations(configIssueAnnotations(config, issues)); err != nil {
+			return err
+		}
+	case outputCSV:
+		rows := make([][]string, 0, len(issues))
+		for _, issue := range issues {
+			rows = append(rows, []string{issue.Project, issue.Server, issue.Severity, issue.Message})
+		}
+		if err := writeCSV([]string{"PROJECT", "SERVER", "SEVERITY", "MESSAGE"}, rows); err != nil {
+			return err
+		}
+	default:
+		if len(issues) == 0 {
+			fmt.Println("No issues found.")
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "PROJECT\tSERVER\t%s\tMESSAGE\n", paint("SEVERITY", ansiDefault))
+			for _, issue := range issues {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Project, issue.Server, paintStatus(issue.Severity, issue.Severity), issue.Message)
+			}
+			w.Flush()
+		}
+
+		// Print summary
+		fmt.Println()
+		fmt.Printf("%d servers | %d errors | %d warnings | %s\n", servers, errorCount, len(issues)-errorCount, configPath)
+	}
+
+	if errorCount > 0 {
+		return withExitCode(exitConfig, fmt.Errorf("config has %d errors", errorCount))
+	}
+	return nil
+}
+
+// checkServer returns the structural problems of one server definition. With
+// lookup set, a stdio server's command must also exist and be executable.
+func checkServer(server MCPServer, lookup bool) []ConfigIssue {
+	var issues []ConfigIssue
+	add := func(severity, format string, args ...interface{}) {
+		issues = append(issues, ConfigIssue{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch server.Type {
+	case "stdio":
+		if server.Command == "" {
+			add(severityError, "stdio server has no command")
+		} else if _, err := resolveCommand(&server); lookup && err != nil {
+			add(severityError, "%v", err)
+		}
+		for i, arg := range server.Args {
+			if strings.TrimSpace(arg) == "" {
+				add(severityWarning, "argument %d is empty", i+1)
+			}
+		}
+		if server.URL != "" {
+			add(severityWarning, "url is ignored for stdio servers")
+		}
+		if server.EnvFile != "" {
+			if _, err := os.Stat(serverPath(&server, server.EnvFile)); err != nil {
+				add(severityError, "env file %q does not exist: %s", server.EnvFile, serverPath(&server, server.EnvFile))
+			}
+		}
+		if server.Cwd != "" && lookup {
+			if _, err := serverDir(&server); err != nil {
+				add(severityError, "%v", err)
+			}
+		}
+		if server.BasicAuth != nil {
+			add(severityWarning, "basicAuth is ignored for stdio servers")
+		}
+		if server.TLSCert != "" || server.TLSKey != "" || server.TLSCA != "" || server.InsecureSkipVerify {
+			add(severityWarning, "TLS settings are ignored for stdio servers")
+		}
+		if server.Proxy != "" {
+			add(severityWarning, "proxy is ignored for stdio servers")
+		}
+	case "http", "sse":
+		if server.URL == "" {
+			add(severityError, "%s server has no url", server.Type)
+		} else if u, err := url.Parse(server.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			add(severityError, "malformed url %q, expected http:// or https://", server.URL)
+		}
+		if server.Command != "" {
+			add(severityWarning, "command is ignored for %s servers", server.Type)
+		}
+		if server.BasicAuth != nil {
+			if server.BasicAuth.Username == "" {
+				add(severityError, "basicAuth has no username")
+			}
+			for key := range server.Headers {
+				if http.CanonicalHeaderKey(key) == "Authorization" {
+					add(severityWarning, "basicAuth replaces the Authorization header")
+				}
+			}
+		}
+		if server.TLSKey != "" && server.TLSCert == "" {
+			add(severityError, "tlsKey has no tlsCert")
+		} else if (server.TLSCert != "" || server.TLSCA != "") && lookup {
+			if _, err := serverTLSConfig(&server); err != nil {
+				add(severityError, "%v", err)
+			}
+		}
+		if server.InsecureSkipVerify {
+			add(severityWarning, "insecureSkipVerify disables TLS certificate verification")
+		}
+		if server.Proxy != "" {
+			if _, err := parseProxyURL(server.Proxy); err != nil {
+				add(severityError, "%v", err)
+			}
+		}
+	case "":
+		add(severityError, "missing type, expected stdio, http or sse")
+	default:
+		add(severityError, "unknown type %q, expected stdio, http or sse", server.Type)
+	}
+	return issues
+}
@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configFile is a client config decoded only down to the server maps, so
+// that saving it keeps every field mcpinspect does not know about
+type configFile struct {
+	path string
+	mode os.FileMode
+	top  map[string]json.RawMessage
+
+	// serversKey is the top-level key of the client-wide servers
+	serversKey string
+
+	// exists tells whether the file was there when read, hasComments
+	// whether it was JSONC whose comments saving drops
+	exists      bool
+	hasComments bool
+
+	// backup makes save keep a copy of the original file
+	backup   bool
+	original []byte
+
+	// before is the file as it was read, in the encoding save writes, so
+	// that diff only shows real changes
+	before []byte
+}
+
+// openConfigFile reads a Claude config for editing
+func openConfigFile(path string) (*configFile, error) {
+	if clientName != clientClaudeCode {
+		return nil, fmt.Errorf("only Claude Code's config can be edited, not %s's", clientName)
+	}
+	f, err := readConfigFile(path, "mcpServers")
+	if err != nil {
+		return nil, err
+	}
+	if !f.exists {
+		return nil, fmt.Errorf("failed to read config file: %w", fs.ErrNotExist)
+	}
+	return f, nil
+}
+
+// readConfigFile reads any client's JSON or JSONC config for editing. A
+// missing file reads as empty and is created by save.
+func readConfigFile(path, serversKey string) (*configFile, error) {
+	f := &configFile{path: path, mode: 0600, serversKey: serversKey, top: make(map[string]json.RawMessage)}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	default:
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		f.mode = info.Mode().Perm()
+		f.exists = true
+		f.original = data
+
+		stripped := stripJSONC(data)
+		f.hasComments = !bytes.Equal(stripped, data)
+		if err := json.Unmarshal(stripped, &f.top); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if f.top == nil {
+			f.top = make(map[string]json.RawMessage)
+		}
+	}
+
+	if f.exists {
+		if f.before, err = f.encode(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// servers returns the raw server definitions of a project, or of the user
+// scope for the (user) pseudo project
+func (f *configFile) servers(project string) (map[string]json.RawMessage, error) {
+	servers := make(map[string]json.RawMessage)
+	var raw json.RawMessage
+	if project == userScope {
+		raw = f.top[f.serversKey]
+	} else {
+		entry, err := f.project(project)
+		if err != nil {
+			return nil, err
+		}
+		raw = entry["mcpServers"]
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &servers); err != nil {
+			return nil, fmt.Errorf("failed to parse servers of %s: %w", project, err)
+		}
+	}
+	if servers == nil {
+		servers = make(map[string]json.RawMessage)
+	}
+	return servers, nil
+}
+
+// setServers replaces the server definitions of a project, creating the
+// project entry when it does not exist yet
+func (f *configFile) setServers(project string, servers map[string]json.RawMessage) error {
+	raw, err := marshalRaw(servers)
+	if err != nil {
+		return fmt.Errorf("failed to encode servers: %w", err)
+	}
+	if project == userScope {
+		f.top[f.serversKey] = raw
+		return nil
+	}
+
+	entry, err := f.project(project)
+	if err != nil {
+		return err
+	}
+	entry["mcpServers"] = raw
+	projects, err := f.projects()
+	if err != nil {
+		return err
+	}
+	if projects[project], err = marshalRaw(entry); err != nil {
+		return fmt.Errorf("failed to encode project %s: %w", project, err)
+	}
+	if f.top["projects"], err = marshalRaw(projects); err != nil {
+		return fmt.Errorf("failed to encode projects: %w", err)
+	}
+	return nil
+}
+
+// projects returns the raw entries of the "projects" object
+func (f *configFile) projects() (map[string]json.RawMessage, error) {
+	projects := make(map[string]json.RawMessage)
+	if raw := f.top["projects"]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &projects); err != nil {
+			return nil, fmt.Errorf("failed to parse projects: %w", err)
+		}
+	}
+	if projects == nil {
+		projects = make(map[string]json.RawMessage)
+	}
+	return projects, nil
+}
+
+// project returns the raw fields of one project, empty when it does not exist
+func (f *configFile) project(project string) (map[string]json.RawMessage, error) {
+	projects, err := f.projects()
+	if err != nil {
+		return nil, err
+	}
+	entry := make(map[string]json.RawMessage)
+	if raw := projects[project]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse project %s: %w", project, err)
+		}
+	}
+	if entry == nil {
+		entry = make(map[string]json.RawMessage)
+	}
+	return entry, nil
+}
+
+// backupPath is where save keeps the original file when backup is set
+func (f *configFile) backupPath() string {
+	return f.path + ".bak"
+}
+
+// projectKeys returns the keys of every project, sorted
+func (f *configFile) projectKeys() ([]string, error) {
+	projects, err := f.projects()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(projects))
+	for key := range projects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// encode renders the config the way save writes it
+func (f *configFile) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(f.top); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// marshalRaw encodes part of a config without escaping <, > and &, like
+// encode, so rebuilding one project leaves values elsewhere as they were
+func marshalRaw(v interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// diff returns a unified diff of the changes made since the file was read
+func (f *configFile) diff() (string, error) {
+	after, err := f.encode()
+	if err != nil {
+		return "", err
+	}
+	return unifiedDiff(f.path, string(f.before), string(after)), nil
+}
+
+// save writes the config back atomically, so a crash never leaves a
+// truncated file behind
+func (f *configFile) save() error {
+	data, err := f.encode()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if f.backup && f.exists {
+		if err := os.WriteFile(f.backupPath(), f.original, f.mode); err != nil {
+			return fmt.Errorf("failed to back up config file: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), f.mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), f.path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// locationKey maps a --from/--to value, "user" or a project directory, to a project key
+func locationKey(location string) (string, error) {
+	if location == "user" || location == userScope {
+		return userScope, nil
+	}
+	return projectKey(location, false)
+}
+
+// projectKey returns the key of a project in the config: the (user) pseudo
+// project with user set, otherwise the absolute path, defaulting to the
+// working directory like Claude Code does
+func projectKey(project string, user bool) (string, error) {
+	if user {
+		return userScope, nil
+	}
+	if project == "" {
+		project = "."
+	}
+	path, err := filepath.Abs(project)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project path: %w", err)
+	}
+	return path, nil
+}
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// unifiedDiff compares two texts line by line. Config edits are local, so the
+// common head and tail are trimmed before the quadratic LCS on what is left.
+func unifiedDiff(name, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	a, b := diffLines(oldText), diffLines(newText)
+
+	head := 0
+	for head < len(a) && head < len(b) && a[head] == b[head] {
+		head++
+	}
+	tail := 0
+	for tail < len(a)-head && tail < len(b)-head && a[len(a)-1-tail] == b[len(b)-1-tail] {
+		tail++
+	}
+	midA, midB := a[head:len(a)-tail], b[head:len(b)-tail]
+
+	// lcs[i][j] is the length of the longest common subsequence of midA[i:] and midB[j:]
+	lcs := make([][]int, len(midA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(midB)+1)
+	}
+	for i := len(midA) - 1; i >= 0; i-- {
+		for j := len(midB) - 1; j >= 0; j-- {
+			if midA[i] == midB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// Edit script over the whole texts: ' ' kept, '-' removed, '+' added
+	type edit struct {
+		op   byte
+		line string
+	}
+	var edits []edit
+	for _, line := range a[:head] {
+		edits = append(edits, edit{' ', line})
+	}
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && j < len(midB) && midA[i] == midB[j]:
+			edits = append(edits, edit{' ', midA[i]})
+			i++
+			j++
+		case j == len(midB) || (i < len(midA) && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', midA[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', midB[j]})
+			j++
+		}
+	}
+	for _, line := range a[len(a)-tail:] {
+		edits = append(edits, edit{' ', line})
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", name, name)
+	oldLine, newLine := 1, 1
+	for start := 0; start < len(edits); {
+		// Find the next change and extend the hunk while changes are close together
+		first := start
+		for first < len(edits) && edits[first].op == ' ' {
+			first++
+		}
+		if first == len(edits) {
+			break
+		}
+		from := first - diffContext
+		if from < start {
+			from = start
+		}
+		to := first
+		for k := first; k < len(edits) && k <= to+2*diffContext; k++ {
+			if edits[k].op != ' ' {
+				to = k
+			}
+		}
+		end := to + diffContext + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		// Line numbers at the start of the hunk
+		for _, e := range edits[start:from] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		oldCount, newCount := 0, 0
+		var body strings.Builder
+		for _, e := range edits[from:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+			body.WriteByte(e.op)
+			body.WriteString(e.line)
+			body.WriteByte('\n')
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n%s", oldLine, oldCount, newLine, newCount, body.String())
+		oldLine += oldCount
+		newLine += newCount
+		start = end
+	}
+	return out.String()
+}
+
+// diffLines splits a text into lines, an empty text having none
+func diffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+)
+
+// Statuses of config files shown by --list-config-paths
+const (
+	pathUsed    = "used"
+	pathIgnored = "found, ignored"
+	pathMissing = "missing"
+)
+
+// ConfigPath is a file a client's loader consults
+type ConfigPath struct {
+	Client string `json:"client"`
+	Path   string `json:"path"`
+	Status string `json:"status"`
+}
+
+// clientConfigCandidates lists where a client's config file may be, in the
+// order they are tried
+func clientConfigCandidates(client string) ([]string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate home directory: %w", err)
+	}
+	dirs, err := userConfigDirs(home)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	switch client {
+	case clientClaudeDesktop:
+		// ~/Library/Application Support on macOS, %AppData% on Windows, ~/.config on Linux
+		for _, dir := range dirs {
+			candidates = append(candidates, filepath.Join(dir, "Claude", "claude_desktop_config.json"))
+		}
+	case clientVSCode:
+		for _, dir := range dirs {
+			candidates = append(candidates, filepath.Join(dir, "Code", "User", "settings.json"))
+		}
+	case clientCline, clientRoo:
+		// Both are VS Code extensions keeping their settings in its global storage
+		for _, dir := range dirs {
+			storage := filepath.Join(dir, "Code", "User", "globalStorage")
+			if client == clientRoo {
+				candidates = append(candidates, filepath.Join(storage, "rooveterinaryinc.roo-cline", "settings", "mcp_settings.json"))
+			} else {
+				candidates = append(candidates, filepath.Join(storage, "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json"))
+			}
+		}
+	case clientZed:
+		switch runtime.GOOS {
+		case "darwin":
+			// Zed keeps its settings in ~/.config/zed on macOS as well
+			candidates = append(candidates, filepath.Join(home, ".config", "zed", "settings.json"))
+		case "windows":
+			candidates = append(candidates, filepath.Join(dirs[0], "Zed", "settings.json"))
+		default:
+			for _, dir := range dirs {
+				candidates = append(candidates, filepath.Join(dir, "zed", "settings.json"))
+			}
+		}
+	case clientCursor:
+		candidates = append(candidates, filepath.Join(home, ".cursor", "mcp.json"))
+	default:
+		// CLAUDE_CONFIG_DIR moves Claude Code's config, which otherwise lives in
+		// the home directory (%USERPROFILE% on Windows)
+		if dir := os.Getenv("CLAUDE_CONFIG_DIR"); dir != "" {
+			candidates = append(candidates, filepath.Join(dir, ".claude.json"))
+		}
+		candidates = append(candidates, filepath.Join(home, ".claude.json"), filepath.Join(home, ".claude", ".claude.json"))
+		if runtime.GOOS != "darwin" && runtime.GOOS != "windows" {
+			for _, dir := range dirs {
+				candidates = append(candidates, filepath.Join(dir, "claude", ".claude.json"))
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// userConfigDirs returns the user configuration directory, followed on Linux
+// by ~/.config when $XDG_CONFIG_HOME points elsewhere
+func userConfigDirs(home string) ([]string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	dirs := []string{dir}
+	if fallback := filepath.Join(home, ".config"); runtime.GOOS == "linux" && fallback != dir {
+		dirs = append(dirs, fallback)
+	}
+	return dirs, nil
+}
+
+// clientProjectFiles lists the project config files a client reads from the
+// nearest directory above the working directory that has them
+var clientProjectFiles = map[string]string{
+	clientClaudeCode: ".mcp.json",
+	clientCursor:     filepath.Join(".cursor", "mcp.json"),
+	clientVSCode:     filepath.Join(".vscode", "mcp.json"),
+	clientZed:        filepath.Join(".zed", "settings.json"),
+}
+
+// listConfigPaths reports every file the loaders of all clients consult and
+// whether it exists and is used
+func listConfigPaths() ([]ConfigPath, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+
+	var paths []ConfigPath
+	for _, client := range clientNames {
+		candidates, err := clientConfigCandidates(client)
+		if err != nil {
+			return nil, err
+		}
+		used := false
+		for _, path := range candidates {
+			status := pathMissing
+			if _, err := os.Stat(path); err == nil {
+				status = pathIgnored
+				if !used {
+					status, used = pathUsed, true
+				}
+			}
+			paths = append(paths, ConfigPath{Client: client, Path: path, Status: status})
+		}
+
+		if client == clientVSCode {
+			// The user mcp.json sits next to whichever settings.json is used
+			settings, err := clientConfigPath(client)
+			if err != nil {
+				return nil, err
+			}
+			userFile := filepath.Join(filepath.Dir(settings), "mcp.json")
+			status := pathMissing
+			if _, err := os.Stat(userFile); err == nil {
+				status = pathUsed
+			}
+			paths = append(paths, ConfigPath{Client: client, Path: userFile, Status: status})
+		}
+
+		if projectFile, ok := clientProjectFiles[client]; ok {
+			if found, ok := findUp(projectFile); ok {
+				paths = append(paths, ConfigPath{Client: client, Path: found, Status: pathUsed})
+			} else {
+				paths = append(paths, ConfigPath{Client: client, Path: filepath.Join(cwd, "...", projectFile), Status: pathMissing})
+			}
+		}
+	}
+	return paths, nil
+}
+
+func printConfigPaths() error {
+	paths, err := listConfigPaths()
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(paths)
+	case outputCSV:
+		rows := make([][]string, 0, len(paths))
+		for _, p := range paths {
+			rows = append(rows, []string{p.Client, p.Path, p.Status})
+		}
+		return writeCSV([]string{"CLIENT", "PATH", "STATUS"}, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLIENT\tPATH\tSTATUS")
+	for _, p := range paths {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Client, p.Path, p.Status)
+	}
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Println("Project files are looked up in the working directory and each of its parents.")
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ContentBlock is a single content item from a prompt message or tool result.
+// It is decoded directly rather than through mcp-golang, which only understands text content.
+type ContentBlock struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Data     string            `json:"data,omitempty"`
+	MimeType string            `json:"mimeType,omitempty"`
+	URI      string            `json:"uri,omitempty"`
+	Name     string            `json:"name,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// ResourceContents is the body of an embedded resource
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+	Blob     string `json:"blob,omitempty"`
+}
+
+// ToolCallResult is the result of a tools/call request
+type ToolCallResult struct {
+	Content           []ContentBlock `json:"content"`
+	StructuredContent interface{}    `json:"structuredContent,omitempty"`
+	IsError           bool           `json:"isError,omitempty"`
+}
+
+// callTool invokes a tool with a raw request so every content type is decoded
+func callTool(ctx context.Context, conn *Connection, name string, arguments map[string]interface{}) (*ToolCallResult, error) {
+	return callToolWithProgress(ctx, conn, name, arguments, nil)
+}
+
+// callToolWithProgress invokes a tool like callTool. When onProgress is set, the
+// request carries a progress token and the server's progress notifications
+// for it are passed to onProgress until the call returns.
+func callToolWithProgress(ctx context.Context, conn *Connection, name string, arguments map[string]interface{}, onProgress func(ProgressUpdate)) (*ToolCallResult, error) {
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	params := map[string]interface{}{
+		"name":      name,
+		"arguments": arguments,
+	}
+	if onProgress != nil {
+		token := newProgressToken()
+		params["_meta"] = map[string]interface{}{"progressToken": token}
+		stop := watchProgress(conn, token, onProgress)
+		defer stop()
+	}
+
+	raw, err := conn.Session.Request(ctx, "tools/call", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call tool: %w", err)
+	}
+
+	var result ToolCallResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse tool result: %w", err)
+	}
+	return &result, nil
+}
+
+// printToolResult prints a tool result's content blocks and structured content
+func printToolResult(result *ToolCallResult) error {
+	if outputFormat == outputJSON {
+		return writeJSON(result)
+	}
+
+	if result.IsError {
+		fmt.Println("Tool returned an error:")
+	}
+	for _, content := range result.Content {
+		fmt.Println(formatContent(content))
+	}
+	if result.StructuredContent != nil {
+		fmt.Printf("Structured content: %s\n", compactJSON(result.StructuredContent))
+	}
+	return nil
+}
+
+// formatContent renders a content block as readable text, summarizing binary payloads
+func formatContent(c ContentBlock) string {
+	switch c.Type {
+	case "text":
+		return c.Text
+	case "image", "audio":
+		return fmt.Sprintf("[%s: %s, %d bytes]", c.Type, c.MimeType, base64.StdEncoding.DecodedLen(len(c.Data)))
+	case "resource":
+		if c.Resource == nil {
+			return "[resource]"
+		}
+		if c.Resource.Blob != "" {
+			return fmt.Sprintf("[resource %s: %s, %d bytes]", c.Resource.URI, c.Resource.MimeType, base64.StdEncoding.DecodedLen(len(c.Resource.Blob)))
+		}
+		return fmt.Sprintf("[resource %s]\n%s", c.Resource.URI, c.Resource.Text)
+	case "resource_link":
+		return fmt.Sprintf("[resource link %s %s]", c.URI, c.Name)
+	default:
+		return fmt.Sprintf("[unsupported content type %q]", c.Type)
+	}
+}
+
+// parseKeyValues parses repeated key=value flag values into a map
+func parseKeyValues(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid argument %q, expected key=value", pair)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// Tokenizer estimates how many model tokens a piece of text takes up
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// charsTokenizer assumes about four characters per token, the usual rule of thumb for English and JSON
+type charsTokenizer struct{}
+
+func (charsTokenizer) Count(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// wordPieces splits text roughly the way BPE tokenizers do: words, numbers,
+// and runs of punctuation each become at least one token
+var wordPieces = regexp.MustCompile(`[A-Za-z]+|[0-9]+|[^\sA-Za-z0-9]+`)
+
+// wordsTokenizer counts word pieces, charging long words one token per six characters
+type wordsTokenizer struct{}
+
+func (wordsTokenizer) Count(text string) int {
+	count := 0
+	for _, piece := range wordPieces.FindAllString(text, -1) {
+		count += (len(piece) + 5) / 6
+	}
+	return count
+}
+
+// tokenizers are the available --tokenizer implementations
+var tokenizers = map[string]Tokenizer{
+	"chars": charsTokenizer{},
+	"words": wordsTokenizer{},
+}
+
+// ToolCost is the estimated context cost of one tool definition
+type ToolCost struct {
+	Name   string `json:"name"`
+	Tokens int    `json:"tokens"`
+}
+
+// ServerCost is the estimated context cost of all of a server's tool definitions
+type ServerCost struct {
+	Name   string     `json:"name"`
+	Tokens int        `json:"tokens"`
+	Tools  []ToolCost `json:"tools"`
+	Error  string     `json:"error,omitempty"`
+}
+
+// ProjectCost totals the servers configured for a project
+type ProjectCost struct {
+	Project string   `json:"project"`
+	Servers []string `json:"servers"`
+	Tokens  int      `json:"tokens"`
+	// Unchecked lists servers whose tools could not be listed and are missing from Tokens
+	Unchecked []string `json:"unchecked,omitempty"`
+}
+
+// CostReport is the estimated context cost of every server and project
+type CostReport struct {
+	Tokenizer string        `json:"tokenizer"`
+	Servers   []ServerCost  `json:"servers"`
+	Projects  []ProjectCost `json:"projects,omitempty"`
+}
+
+func newCostCmd() *cobra.Command {
+	var tokenizerName string
+	costCmd := &cobra.Command{
+		Use:   "cost [server-name]",
+		Short: "Estimate how many context tokens each server's tools consume",
+		Long: `Connect to servers, list their tools and estimate how many tokens the tool
+definitions (names, descriptions and input schemas) take up in the model's
+context, with totals for every project.
+
+Without arguments every server and project is reported; with a server name
+the cost of each of its tools is shown. Counts are estimates: --tokenizer
+chooses between "chars" (about four characters per token) and "words" (word
+and punctuation pieces, closer to BPE tokenizers for prose-heavy tools).`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokenizer, ok := tokenizers[tokenizerName]
+			if !ok {
+				return fmt.Errorf("unknown tokenizer %q (expected chars or words)", tokenizerName)
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cmd.SilenceUsage = true
+
+			if len(args) == 1 {
+				return showServerCost(config, args[0], tokenizerName, tokenizer)
+			}
+			return showCosts(config, tokenizerName, tokenizer)
+		},
+	}
+	costCmd.Flags().StringVar(&tokenizerName, "tokenizer", "chars", "token estimator: chars or words")
+	return costCmd
+}
+
+// toolTokens estimates the tokens of a tool definition as it is sent to the model
+func toolTokens(tool ToolInfo, tokenizer Tokenizer) int {
+	definition, err := json.Marshal(map[string]interface{}{
+		"name":         tool.Name,
+		"description":  tool.Description,
+		"input_schema": tool.InputSchema,
+	})
+	if err != nil {
+		return tokenizer.Count(tool.Name + tool.Description)
+	}
+	return tokenizer.Count(string(definition))
+}
+
+// serverCost lists a server's tools and estimates their cost, recording failures in the result
+func serverCost(config *ClaudeConfig, serverName string, tokenizer Tokenizer) ServerCost {
+	cost := ServerCost{Name: serverName, Tools: []ToolCost{}}
+	tools, err := fetchTools(config, serverName)
+	if err != nil {
+		cost.Error = err.Error()
+		return cost
+	}
+	for _, tool := range tools {
+		tokens := toolTokens(tool, tokenizer)
+		cost.Tools = append(cost.Tools, ToolCost{Name: tool.Name, Tokens: tokens})
+		cost.Tokens += tokens
+	}
+	return cost
+}
+
+func showServerCost(config *ClaudeConfig, serverName, tokenizerName string, tokenizer Tokenizer) error {
+	cost := serverCost(config, serverName, tokenizer)
+	if cost.Error != "" {
+		return fmt.Errorf("%s", cost.Error)
+	}
+	// Most expensive first, since that is what is worth trimming
+	sort.SliceStable(cost.Tools, func(i, j int) bool {
+		return cost.Tools[i].Tokens > cost.Tools[j].Tokens
+	})
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(&CostReport{Tokenizer: tokenizerName, Servers: []ServerCost{cost}})
+	case outputCSV:
+		rows := make([][]string, 0, len(cost.Tools))
+		for _, tool := range cost.Tools {
+			rows = append(rows, []string{cost.Name, tool.Name, strconv.Itoa(tool.Tokens)})
+		}
+		return writeCSV([]string{"SERVER", "TOOL", "TOKENS"}, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tTOKENS\tSHARE")
+	for _, tool := range cost.Tools {
+		fmt.Fprintf(w, "%s\t%d\t%s\n", tool.Name, tool.Tokens, formatShare(tool.Tokens, cost.Tokens))
+	}
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("~%d tokens for %d tools | %s tokenizer\n", cost.Tokens, len(cost.Tools), tokenizerName)
+	return nil
+}
+
+func showCosts(config *ClaudeConfig, tokenizerName string, tokenizer Tokenizer) error {
+	servers := aggregateServers(config)
+	report := &CostReport{Tokenizer: tokenizerName, Servers: make([]ServerCost, len(servers)), Projects: []ProjectCost{}}
+	runPool(len(servers), concurrency, func(i int) {
+		report.Servers[i] = serverCost(config, servers[i].Name, tokenizer)
+	})
+
+	byName := make(map[string]ServerCost, len(report.Servers))
+	for _, cost := range report.Servers {
+		byName[cost.Name] = cost
+	}
+
+	projectPaths := make([]string, 0, len(config.Projects))
+	for projectPath := range config.Projects {
+		projectPaths = append(projectPaths, projectPath)
+	}
+	sort.Strings(projectPaths)
+	for _, projectPath := range projectPaths {
+		project := ProjectCost{Project: projectPath, Servers: []string{}}
+		for name := range config.Projects[projectPath].MCPServers {
+			project.Servers = append(project.Servers, name)
+			if cost := byName[name]; cost.Error != "" {
+				project.Unchecked = append(project.Unchecked, name)
+			} else {
+				project.Tokens += cost.Tokens
+			}
+		}
+		sort.Strings(project.Servers)
+		sort.Strings(project.Unchecked)
+		report.Projects = append(report.Projects, project)
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(report)
+	case outputCSV:
+		rows := make([][]string, 0, len(report.Servers))
+		for _, cost := range report.Servers {
+			tokens := ""
+			if cost.Error == "" {
+				tokens = strconv.Itoa(cost.Tokens)
+			}
+			rows = append(rows, []string{cost.Name, strconv.Itoa(len(cost.Tools)), tokens, cost.Error})
+		}
+		return writeCSV([]string{"SERVER", "TOOLS", "TOKENS", "ERROR"}, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tTOOLS\tTOKENS")
+	for _, cost := range report.Servers {
+		if cost.Error != "" {
+			fmt.Fprintf(w, "%s\t[N/A]\t[N/A]\n", cost.Name)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\n", cost.Name, len(cost.Tools), cost.Tokens)
+	}
+	w.Flush()
+
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tSERVERS\tTOKENS\tUNCHECKED")
+	for _, project := range report.Projects {
+		unchecked := "[N/A]"
+		if len(project.Unchecked) > 0 {
+			unchecked = strings.Join(project.Unchecked, ", ")
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", project.Project, len(project.Servers), project.Tokens, unchecked)
+	}
+	w.Flush()
+
+	first := true
+	for _, cost := range report.Servers {
+		if cost.Error == "" {
+			continue
+		}
+		if first {
+			fmt.Fprintln(os.Stderr)
+			first = false
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", cost.Name, cost.Error)
+	}
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d servers | %d projects | %s tokenizer\n", len(report.Servers), len(report.Projects), tokenizerName)
+	return nil
+}
+
+// formatShare renders part as a percentage of total
+func formatShare(part, total int) string {
+	if total == 0 {
+		return "[N/A]"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
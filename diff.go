@@ -0,0 +1,354 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// Schema change kinds
+const (
+	changeAdded   = "added"
+	changeRemoved = "removed"
+	changeChanged = "changed"
+)
+
+// SchemaChange is a single structural difference between two input schemas
+type SchemaChange struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// ToolChange describes how a tool present on both sides differs
+type ToolChange struct {
+	Name           string         `json:"name"`
+	OldDescription string         `json:"oldDescription,omitempty"`
+	NewDescription string         `json:"newDescription,omitempty"`
+	SchemaChanges  []SchemaChange `json:"schemaChanges,omitempty"`
+}
+
+// DescriptionChanged reports whether the tool's description differs
+func (c ToolChange) DescriptionChanged() bool {
+	return c.OldDescription != c.NewDescription
+}
+
+// DiffResult lists the tool differences between two inspections
+type DiffResult struct {
+	Added   []string     `json:"added"`
+	Removed []string     `json:"removed"`
+	Changed []ToolChange `json:"changed"`
+}
+
+// Empty reports whether the two inspections expose identical tools
+func (d *DiffResult) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func newDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare the tools of two inspection snapshots or live servers",
+		Long: `Compare two sets of tools and report added, removed and changed tools,
+including description changes and structural input schema differences.
+
+Each argument is either a JSON file written by "mcpinspect <server> --output json"
+or the name of a configured server, which is inspected live.
+
+With --fail-on drift the command exits with code 5 when the tools differ,
+for gating a pipeline on a committed snapshot.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldResult, err := resolveInspection(args[0])
+			if err != nil {
+				return err
+			}
+			newResult, err := resolveInspection(args[1])
+			if err != nil {
+				return err
+			}
+			// Drift is a result, not a usage error
+			cmd.SilenceUsage = true
+			diff := diffInspections(oldResult, newResult)
+			if err := printDiff(diff); err != nil {
+				return err
+			}
+			if !diff.Empty() && failsOn(failDrift, false) {
+				return driftError(diff)
+			}
+			return nil
+		},
+	}
+}
+
+// driftError fails a command for the differences a diff found
+func driftError(diff *DiffResult) error {
+	return withExitCode(exitDrift, fmt.Errorf("drift detected: %d added, %d removed, %d changed", len(diff.Added), len(diff.Removed), len(diff.Changed)))
+}
+
+// resolveInspection loads an inspection from a JSON file, or inspects the
+// configured server of that name when no such file exists
+func resolveInspection(arg string) (*InspectResult, error) {
+	if info, err := os.Stat(arg); err == nil && info.Mode().IsRegular() {
+		return loadInspectionFile(arg)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return collectInspection(ctx, config, conn)
+}
+
+func loadInspectionFile(path string) (*InspectResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var result InspectResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// diffInspections compares the tools of two inspections by name
+func diffInspections(oldResult, newResult *InspectResult) *DiffResult {
+	diff := &DiffResult{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: []ToolChange{},
+	}
+
+	oldTools := make(map[string]ToolInfo, len(oldResult.Tools))
+	for _, tool := range oldResult.Tools {
+		oldTools[tool.Name] = tool
+	}
+	newTools := make(map[string]ToolInfo, len(newResult.Tools))
+	for _, tool := range newResult.Tools {
+		newTools[tool.Name] = tool
+	}
+
+	for name := range oldTools {
+		if _, ok := newTools[name]; !ok {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+
+	for name, newTool := range newTools {
+		oldTool, ok := oldTools[name]
+		if !ok {
+			diff.Added = append(diff.Added, name)
+			continue
+		}
+
+		change := ToolChange{Name: name}
+		if oldTool.Description != newTool.Description {
+			change.OldDescription = oldTool.Description
+			change.NewDescription = newTool.Description
+		}
+		diffSchemas("", oldTool.InputSchema, newTool.InputSchema, &change.SchemaChanges)
+
+		if change.DescriptionChanged() || len(change.SchemaChanges) > 0 {
+			diff.Changed = append(diff.Changed, change)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return diff.Changed[i].Name < diff.Changed[j].Name
+	})
+
+	return diff
+}
+
+// diffSchemas walks two JSON values and records structural differences.
+// Objects are compared key by key, arrays of scalars (such as "required" and
+// "enum") as sets, and other arrays element by element.
+func diffSchemas(path string, oldValue, newValue interface{}, changes *[]SchemaChange) {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]interface{})
+	newMap, newIsMap := newValue.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := make(map[string]bool)
+		for k := range oldMap {
+			keys[k] = true
+		}
+		for k := range newMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			childPath := joinSchemaPath(path, k)
+			oldChild, inOld := oldMap[k]
+			newChild, inNew := newMap[k]
+			switch {
+			case !inOld:
+				*changes = append(*changes, SchemaChange{Path: childPath, Kind: changeAdded, New: newChild})
+			case !inNew:
+				*changes = append(*changes, SchemaChange{Path: childPath, Kind: changeRemoved, Old: oldChild})
+			default:
+				diffSchemas(childPath, oldChild, newChild, changes)
+			}
+		}
+		return
+	}
+
+	oldList, oldIsList := oldValue.([]interface{})
+	newList, newIsList := newValue.([]interface{})
+	if oldIsList && newIsList {
+		if isScalarList(oldList) && isScalarList(newList) {
+			for _, v := range oldList {
+				if !containsValue(newList, v) {
+					*changes = append(*changes, SchemaChange{Path: path, Kind: changeRemoved, Old: v})
+				}
+			}
+			for _, v := range newList {
+				if !containsValue(oldList, v) {
+					*changes = append(*changes, SchemaChange{Path: path, Kind: changeAdded, New: v})
+				}
+			}
+			return
+		}
+
+		for i := 0; i < len(oldList) || i < len(newList); i++ {
+			childPath := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(oldList):
+				*changes = append(*changes, SchemaChange{Path: childPath, Kind: changeAdded, New: newList[i]})
+			case i >= len(newList):
+				*changes = append(*changes, SchemaChange{Path: childPath, Kind: changeRemoved, Old: oldList[i]})
+			default:
+				diffSchemas(childPath, oldList[i], newList[i], changes)
+			}
+		}
+		return
+	}
+
+	*changes = append(*changes, SchemaChange{Path: path, Kind: changeChanged, Old: oldValue, New: newValue})
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func isScalarList(list []interface{}) bool {
+	for _, v := range list {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return false
+		}
+	}
+	return true
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, v := range list {
+		if reflect.DeepEqual(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func printDiff(diff *DiffResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(diff)
+	case outputGitHub:
+		return writeAnnotations(diffAnnotations(diff, failsOn(failDrift, false), ""))
+	}
+
+	if diff.Empty() {
+		fmt.Println("No differences.")
+		return nil
+	}
+
+	printToolChanges(os.Stdout, diff)
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d added | %d removed | %d changed\n", len(diff.Added), len(diff.Removed), len(diff.Changed))
+	return nil
+}
+
+// printToolChanges prints one +/-/~ line per added, removed and changed tool
+func printToolChanges(w io.Writer, diff *DiffResult) {
+	for _, name := range diff.Added {
+		fmt.Fprintln(w, paint("+ "+name, ansiGreen))
+	}
+	for _, name := range diff.Removed {
+		fmt.Fprintln(w, paint("- "+name, ansiRed))
+	}
+	for _, change := range diff.Changed {
+		fmt.Fprintln(w, paint("~ "+change.Name, ansiYellow))
+		if change.DescriptionChanged() {
+			fmt.Fprintf(w, "    description: %q -> %q\n", change.OldDescription, change.NewDescription)
+		}
+		for _, sc := range change.SchemaChanges {
+			fmt.Fprintf(w, "    %s\n", paint(formatSchemaChange(sc), schemaChangeColors[sc.Kind]))
+		}
+	}
+}
+
+// schemaChangeColors highlights schema changes like the tool lines above them
+var schemaChangeColors = map[string]string{
+	changeAdded:   ansiGreen,
+	changeRemoved: ansiRed,
+	changeChanged: ansiYellow,
+}
+
+func formatSchemaChange(sc SchemaChange) string {
+	path := sc.Path
+	if path == "" {
+		path = "(root)"
+	}
+	switch sc.Kind {
+	case changeAdded:
+		return fmt.Sprintf("schema %s: + %s", path, compactJSON(sc.New))
+	case changeRemoved:
+		return fmt.Sprintf("schema %s: - %s", path, compactJSON(sc.Old))
+	default:
+		return fmt.Sprintf("schema %s: %s -> %s", path, compactJSON(sc.Old), compactJSON(sc.New))
+	}
+}
+
+// compactJSON renders a value as single-line JSON for diff output
+func compactJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/spf13/cobra"
+)
+
+// ServerDocs is everything needed to document a server
+type ServerDocs struct {
+	Inspection *InspectResult
+	Resources  []*mcp.ResourceSchema
+	Prompts    []*mcp.PromptSchema
+}
+
+func newDocsCmd() *cobra.Command {
+	var outDir string
+	docsCmd := &cobra.Command{
+		Use:   "docs <server-name>",
+		Short: "Generate Markdown documentation for a server",
+		Long: `Connect to a server and generate Markdown documentation for its tools,
+resources and prompts, including parameter tables derived from each tool's
+input schema and example arguments.
+
+The Markdown is printed to stdout, or written to <dir>/<server-name>.md with --out.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return generateDocs(config, args[0], outDir)
+		},
+	}
+	docsCmd.Flags().StringVarP(&outDir, "out", "o", "", "directory to write the Markdown file into")
+	return docsCmd
+}
+
+func generateDocs(config *ClaudeConfig, serverName, outDir string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	docs, err := collectDocs(ctx, config, conn)
+	if err != nil {
+		return err
+	}
+
+	if outDir == "" {
+		return renderMarkdownDocs(os.Stdout, docs)
+	}
+
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", outDir, err)
+	}
+	path := filepath.Join(outDir, serverName+".md")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if err := renderMarkdownDocs(f, docs); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", path)
+	return nil
+}
+
+// collectDocs gathers tools, and resources and prompts when the server advertises them
+func collectDocs(ctx context.Context, config *ClaudeConfig, conn *Connection) (*ServerDocs, error) {
+	inspection, err := collectInspection(ctx, config, conn)
+	if err != nil {
+		return nil, err
+	}
+	docs := &ServerDocs{Inspection: inspection}
+
+	if conn.Init.Capabilities.Resources != nil {
+		resources, err := conn.Client.ListResources(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		docs.Resources = resources.Resources
+		sort.Slice(docs.Resources, func(i, j int) bool {
+			return docs.Resources[i].Uri < docs.Resources[j].Uri
+		})
+	}
+
+	if conn.Init.Capabilities.Prompts != nil {
+		prompts, err := conn.Client.ListPrompts(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		docs.Prompts = prompts.Prompts
+		sort.Slice(docs.Prompts, func(i, j int) bool {
+			return docs.Prompts[i].Name < docs.Prompts[j].Name
+		})
+	}
+
+	return docs, nil
+}
+
+func renderMarkdownDocs(w io.Writer, docs *ServerDocs) error {
+	inspection := docs.Inspection
+
+	fmt.Fprintf(w, "# %s\n\n", inspection.Name)
+	fmt.Fprintf(w, "%s | %s | protocol %s\n", inspection.ServerInfo, inspection.Type, inspection.ProtocolVersion)
+
+	fmt.Fprintf(w, "\n## Tools\n")
+	if len(inspection.Tools) == 0 {
+		fmt.Fprintf(w, "\nThis server exposes no tools.\n")
+	}
+	for _, tool := range inspection.Tools {
+		fmt.Fprintf(w, "\n### `%s`\n\n", tool.Name)
+		if tool.Description != "" {
+			fmt.Fprintf(w, "%s\n\n", tool.Description)
+		}
+
+		params := schemaParams(tool.InputSchema)
+		if len(params) == 0 {
+			fmt.Fprintf(w, "This tool takes no parameters.\n")
+			continue
+		}
+
+		fmt.Fprintf(w, "| Parameter | Type | Required | Description |\n")
+		fmt.Fprintf(w, "|-----------|------|----------|-------------|\n")
+		for _, param := range params {
+			required := "no"
+			if param.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", param.Name, markdownCell(param.Type), required, markdownCell(describeParam(param)))
+		}
+
+		if fields := schemaParams(tool.OutputSchema); len(fields) > 0 {
+			fmt.Fprintf(w, "\n**Structured output**\n\n")
+			fmt.Fprintf(w, "| Field | Type | Required | Description |\n")
+			fmt.Fprintf(w, "|-------|------|----------|-------------|\n")
+			for _, field := range fields {
+				required := "no"
+				if field.Required {
+					required = "yes"
+				}
+				fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", field.Name, markdownCell(field.Type), required, markdownCell(describeParam(field)))
+			}
+		}
+
+		// Render without HTML escaping so <placeholders> stay readable
+		var example bytes.Buffer
+		enc := json.NewEncoder(&example)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(exampleValue("value", asSchemaMap(tool.InputSchema))); err != nil {
+			return fmt.Errorf("failed to render example for %s: %w", tool.Name, err)
+		}
+		fmt.Fprintf(w, "\n**Example arguments**\n\n```json\n%s```\n", example.String())
+	}
+
+	if len(docs.Resources) > 0 {
+		fmt.Fprintf(w, "\n## Resources\n\n")
+		fmt.Fprintf(w, "| URI | Name | MIME Type | Description |\n")
+		fmt.Fprintf(w, "|-----|------|-----------|-------------|\n")
+		for _, resource := range docs.Resources {
+			desc := ""
+			if resource.Description != nil {
+				desc = *resource.Description
+			}
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", resource.Uri, markdownCell(resource.Name), mimeTypeOrDefault(resource.MimeType, ""), markdownCell(desc))
+		}
+	}
+
+	if len(docs.Prompts) > 0 {
+		fmt.Fprintf(w, "\n## Prompts\n")
+		for _, prompt := range docs.Prompts {
+			fmt.Fprintf(w, "\n### `%s`\n\n", prompt.Name)
+			if prompt.Description != nil && *prompt.Description != "" {
+				fmt.Fprintf(w, "%s\n\n", *prompt.Description)
+			}
+			if len(prompt.Arguments) == 0 {
+				fmt.Fprintf(w, "This prompt takes no arguments.\n")
+				continue
+			}
+			fmt.Fprintf(w, "| Argument | Required | Description |\n")
+			fmt.Fprintf(w, "|----------|----------|-------------|\n")
+			for _, arg := range prompt.Arguments {
+				required := "no"
+				if arg.Required != nil && *arg.Required {
+					required = "yes"
+				}
+				desc := ""
+				if arg.Description != nil {
+					desc = *arg.Description
+				}
+				fmt.Fprintf(w, "| `%s` | %s | %s |\n", arg.Name, required, markdownCell(desc))
+			}
+		}
+	}
+
+	return nil
+}
+
+// describeParam combines a parameter's description with its allowed and default values
+func describeParam(param SchemaParam) string {
+	parts := []string{}
+	if param.Description != "" {
+		parts = append(parts, param.Description)
+	}
+	if len(param.Enum) > 0 {
+		values := make([]string, 0, len(param.Enum))
+		for _, v := range param.Enum {
+			values = append(values, fmt.Sprintf("`%v`", v))
+		}
+		parts = append(parts, "One of: "+strings.Join(values, ", "))
+	}
+	if param.Default != nil {
+		parts = append(parts, fmt.Sprintf("Default: `%v`", param.Default))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// markdownCell escapes text for use inside a Markdown table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Doctor check statuses
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorDialTimeout bounds the network and TLS checks
+const doctorDialTimeout = 5 * time.Second
+
+// DoctorCheck is the outcome of one diagnostic step
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Hint   string `json:"hint,omitempty"`
+
+	// err is the failure behind Detail where it carries more than the text,
+	// kept for the exit code
+	err error
+}
+
+// DoctorReport is the checklist of one server
+type DoctorReport struct {
+	Server string        `json:"server"`
+	Type   string        `json:"type,omitempty"`
+	Checks []DoctorCheck `json:"checks"`
+
+	// scope locates the server's definition for --output github
+	scope string
+}
+
+// Failed reports whether any check of the report failed
+func (r DoctorReport) Failed() bool {
+	for _, check := range r.Checks {
+		if check.Status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+// failure returns the error behind the failed check, tagged with the exit
+// code of a config or auth check; nil when no check failed
+func (r DoctorReport) failure() error {
+	for _, check := range r.Checks {
+		if check.Status != doctorFail {
+			continue
+		}
+		switch {
+		case check.Name == "config":
+			return withExitCode(exitConfig, errors.New(check.Detail))
+		case check.Name == "auth":
+			return withExitCode(exitAuth, errors.New(check.Detail))
+		case check.err != nil:
+			return check.err
+		}
+		return errors.New(check.Detail)
+	}
+	return nil
+}
+
+// doctorRun collects checks, skipping every step after the first failure
+type doctorRun struct {
+	report *DoctorReport
+}
+
+// check runs one step unless an earlier one failed
+func (d *doctorRun) check(name string, step func() DoctorCheck) bool {
+	if d.report.Failed() {
+		d.report.Checks = append(d.report.Checks, DoctorCheck{Name: name, Status: doctorSkip})
+		return false
+	}
+	result := step()
+	result.Name = name
+	d.report.Checks = append(d.report.Checks, result)
+	return result.Status != doctorFail
+}
+
+func newDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor [server-name]",
+		Short: "Diagnose why a server does not work",
+		Long: `Run layered checks against a server, or every configured server, and print
+a checklist: config parsing and the server definition, the command on PATH
+(stdio) or network reachability, TLS handshake and stored OAuth token
+(HTTP/SSE), the initialize handshake, and tools/list.
+
+Checks after the first failure are skipped, and the failure comes with a hint
+on how to fix it. The command exits with an error if any server fails.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Failed checks are a result, not a usage error
+			cmd.SilenceUsage = true
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				report := DoctorReport{Server: configPath, Checks: []DoctorCheck{{
+					Name:   "config",
+					Status: doctorFail,
+					Detail: err.Error(),
+					Hint:   "fix the file so it is valid JSON, then run `mcpinspect config validate`",
+				}}}
+				return printDoctorReports([]DoctorReport{report})
+			}
+
+			names := args
+			if len(names) == 0 {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			}
+			reports := make([]DoctorReport, len(names))
+			runPool(len(names), concurrency, func(i int) {
+				reports[i] = diagnoseServer(config, names[i])
+			})
+			return printDoctorReports(reports)
+		},
+	}
+}
+
+// diagnoseServer runs every check that applies to the server's type
+func diagnoseServer(config *ClaudeConfig, serverName string) DoctorReport {
+	report := DoctorReport{Server: serverName}
+	d := &doctorRun{report: &report}
+
+	var server *MCPServer
+	d.check("config", func() DoctorCheck {
+		var err error
+		server, err = findServer(config, serverName)
+		if err != nil {
+			return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "run `mcpinspect` to list the configured server names"}
+		}
+		report.Type, report.scope = server.Type, server.Scope
+		return checkDefinition(*server)
+	})
+
+	if server != nil {
+		switch server.Type {
+		case "stdio":
+			d.check("command", func() DoctorCheck { return checkCommand(server) })
+		case "http", "sse":
+			u, _ := url.Parse(server.URL)
+			d.check("network", func() DoctorCheck { return checkReachable(u, server) })
+			if u != nil && u.Scheme == "https" {
+				d.check("tls", func() DoctorCheck { return checkTLS(u, server) })
+			}
+			d.check("auth", func() DoctorCheck { return checkAuthToken(server, serverName) })
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var conn *Connection
+	d.check("initialize", func() DoctorCheck {
+		var err error
+		conn, err = openConnection(ctx, config, serverName)
+		if err != nil {
+			hint := "rerun with --trace to see the JSON-RPC exchange"
+			if server.Type == "stdio" {
+				hint = "run the command by hand to see what it prints on stderr, or rerun with --trace"
+			}
+			return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: hint, err: err}
+		}
+		return DoctorCheck{Status: doctorOK, Detail: fmt.Sprintf("%s, protocol %s", formatServerInfo(conn.Init), conn.Init.ProtocolVersion)}
+	})
+	if conn != nil {
+		defer conn.Close()
+	}
+
+	d.check("tools/list", func() DoctorCheck {
+		tools, err := listTools(ctx, conn)
+		if err != nil {
+			return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "the server started but cannot list its tools; check its logs with `mcpinspect logs`", err: err}
+		}
+		return DoctorCheck{Status: doctorOK, Detail: fmt.Sprintf("%d tools", len(tools))}
+	})
+
+	return report
+}
+
+// checkDefinition turns the structural issues of a server into a check
+func checkDefinition(server MCPServer) DoctorCheck {
+	var errors, warnings []string
+	// The command is looked up by its own check, with its own hint
+	for _, issue := range checkServer(server, false) {
+		if issue.Severity == severityError {
+			errors = append(errors, issue.Message)
+		} else {
+			warnings = append(warnings, issue.Message)
+		}
+	}
+	switch {
+	case len(errors) > 0:
+		return DoctorCheck{Status: doctorFail, Detail: strings.Join(errors, "; "), Hint: "fix the server definition; `mcpinspect config validate` lists every problem"}
+	case len(warnings) > 0:
+		return DoctorCheck{Status: doctorWarn, Detail: strings.Join(warnings, "; ")}
+	}
+	scope := server.Scope
+	if scope == "" {
+		return DoctorCheck{Status: doctorOK, Detail: configPath}
+	}
+	return DoctorCheck{Status: doctorOK, Detail: scope + " scope, " + configPath}
+}
+
+// checkCommand looks up a stdio server's command
+func checkCommand(server *MCPServer) DoctorCheck {
+	path, err := resolveCommand(server)
+	if err != nil {
+		return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "install the command or use its absolute path in the config; clients may start servers with a different PATH than your shell"}
+	}
+	return DoctorCheck{Status: doctorOK, Detail: path}
+}
+
+// checkReachable opens a TCP connection to a server's host and port, or to
+// its proxy when it has one
+func checkReachable(u *url.URL, server *MCPServer) DoctorCheck {
+	proxy, err := proxyFor(server, u)
+	if err != nil {
+		return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "fix the proxy URL of --proxy, --socks5, the config or HTTPS_PROXY/HTTP_PROXY"}
+	}
+	if proxy != nil {
+		address := hostPort(proxy)
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", address, doctorDialTimeout)
+		if err != nil {
+			return DoctorCheck{Status: doctorFail, Detail: "proxy unreachable: " + err.Error(), Hint: "check that the proxy of --proxy, --socks5, the config or HTTPS_PROXY/HTTP_PROXY is running; NO_PROXY bypasses the environment's proxy"}
+		}
+		conn.Close()
+		return DoctorCheck{Status: doctorOK, Detail: fmt.Sprintf("proxy %s reachable in %sms", address, formatMs(durationMs(time.Since(start))))}
+	}
+
+	address := hostPort(u)
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", address, doctorDialTimeout)
+	if err != nil {
+		return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "check that the server is running and the URL is right; VPNs, proxies and firewalls can block the connection"}
+	}
+	conn.Close()
+	return DoctorCheck{Status: doctorOK, Detail: fmt.Sprintf("%s reachable in %sms", address, formatMs(durationMs(time.Since(start))))}
+}
+
+// checkTLS performs a TLS handshake, with the server's client certificate if
+// any, and reports the certificate's expiry
+func checkTLS(u *url.URL, server *MCPServer) DoctorCheck {
+	config, err := serverTLSConfig(server)
+	if err != nil {
+		return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "check the TLS files of the flags or config: tlsCA must be a PEM bundle, tlsCert and tlsKey a matching PEM certificate and key"}
+	}
+	state, err := tlsHandshake(u, server, config)
+	if err != nil {
+		return DoctorCheck{Status: doctorFail, Detail: err.Error(), Hint: "the certificate is not trusted or does not match the host; check the system clock, or trust a private CA with --tls-ca"}
+	}
+
+	detail := tls.VersionName(state.Version)
+	if len(state.PeerCertificates) > 0 {
+		expiry := state.PeerCertificates[0].NotAfter
+		detail += ", certificate valid until " + expiry.Format("2006-01-02")
+		if time.Until(expiry) < 14*24*time.Hour {
+			return DoctorCheck{Status: doctorWarn, Detail: detail + " (expires soon)"}
+		}
+	}
+	if config.InsecureSkipVerify {
+		return DoctorCheck{Status: doctorWarn, Detail: detail + " (not verified)"}
+	}
+	return DoctorCheck{Status: doctorOK, Detail: detail}
+}
+
+// tlsHandshake connects to a server's host and returns the negotiated TLS
+// state. Through a proxy the handshake happens within a HEAD request, which
+// tunnels the connection.
+func tlsHandshake(u *url.URL, server *MCPServer, config *tls.Config) (tls.ConnectionState, error) {
+	proxy, err := proxyFor(server, u)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	if proxy == nil {
+		config.ServerName = u.Hostname()
+		dialer := &net.Dialer{Timeout: doctorDialTimeout}
+		conn, err := tls.DialWithDialer(dialer, "tcp", hostPort(u), config)
+		if err != nil {
+			return tls.ConnectionState{}, err
+		}
+		defer conn.Close()
+		return conn.ConnectionState(), nil
+	}
+
+	client, err := httpClient(server)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), doctorDialTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	resp.Body.Close()
+	if resp.TLS == nil {
+		return tls.ConnectionState{}, fmt.Errorf("no TLS connection to %s", u.Host)
+	}
+	return *resp.TLS, nil
+}
+
+// checkAuthToken looks for a stored OAuth token and whether it has expired,
+// refreshing an expired one. A missing token is only a warning since many
+// servers need none.
+func checkAuthToken(server *MCPServer, serverName string) DoctorCheck {
+	if hasAuthHeader(server) {
+		return DoctorCheck{Status: doctorOK, Detail: "Authorization set explicitly (config, --header, --bearer-token or " + tokenEnv + ")"}
+	}
+	cred, err := lookupMCPOAuth(serverName, server.URL)
+	if err != nil {
+		return DoctorCheck{Status: doctorWarn, Detail: "no OAuth token stored", Hint: "if the server uses OAuth, run `mcpinspect auth login " + serverName + "`"}
+	}
+	entry := &cred.entry
+	if entry.Expired() {
+		expiry := time.UnixMilli(entry.ExpiresAt)
+		hint := "run `mcpinspect auth login " + serverName + "`, or re-authenticate the server in Claude Code with /mcp"
+		if entry.RefreshToken == "" {
+			return DoctorCheck{Status: doctorFail, Detail: "OAuth token expired at " + expiry.Format(time.RFC3339), Hint: hint}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		refreshed, err := refreshMCPOAuth(ctx, server, cred)
+		if err != nil {
+			return DoctorCheck{Status: doctorFail, Detail: "OAuth token expired at " + expiry.Format(time.RFC3339) + ", refresh failed: " + err.Error(), Hint: hint}
+		}
+		entry = refreshed
+		if entry.ExpiresAt == 0 {
+			return DoctorCheck{Status: doctorOK, Detail: "OAuth token refreshed"}
+		}
+		return DoctorCheck{Status: doctorOK, Detail: "OAuth token refreshed, valid until " + time.UnixMilli(entry.ExpiresAt).Format(time.RFC3339)}
+	}
+	if entry.ExpiresAt > 0 {
+		return DoctorCheck{Status: doctorOK, Detail: "OAuth token valid until " + time.UnixMilli(entry.ExpiresAt).Format(time.RFC3339)}
+	}
+	return DoctorCheck{Status: doctorOK, Detail: "OAuth token stored"}
+}
+
+// hostPort returns the address to dial for a URL, defaulting the port by scheme
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+func printDoctorReports(reports []DoctorReport) error {
+	var errs []error
+	for _, report := range reports {
+		if err := report.failure(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		if err := writeJSON(reports); err != nil {
+			return err
+		}
+	case outputJUnit:
+		if err := writeJUnit("mcpinspect doctor", doctorJUnit(reports)); err != nil {
+			return err
+		}
+	case outputGitHub:
+		if err := writeAnnotations(doctorAnnotations(reports)); err != nil {
+			return err
+		}
+	case outputCSV:
+		var rows [][]string
+		for _, report := range reports {
+			for _, check := range report.Checks {
+				rows = append(rows, []string{report.Server, check.Name, check.Status, check.Detail, check.Hint})
+			}
+		}
+		if err := writeCSV([]string{"SERVER", "CHECK", "STATUS", "DETAIL", "HINT"}, rows); err != nil {
+			return err
+		}
+	default:
+		for i, report := range reports {
+			if i > 0 {
+				fmt.Println()
+			}
+			if report.Type != "" {
+				fmt.Printf("%s (%s)\n", report.Server, report.Type)
+			} else {
+				fmt.Println(report.Server)
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			for _, check := range report.Checks {
+				fmt.Fprintf(w, "  %s\t%s\t%s\n", paintStatus("["+check.Status+"]", check.Status), check.Name, check.Detail)
+				if check.Hint != "" {
+					// Painted empty so the column keeps the width of the status escape codes
+					fmt.Fprintf(w, "  %s\t\thint: %s\n", paint("", ansiDefault), check.Hint)
+				}
+			}
+			w.Flush()
+		}
+
+		// Print summary
+		fmt.Println()
+		fmt.Printf("%d/%d servers healthy\n", len(reports)-len(errs), len(reports))
+	}
+
+	if len(errs) > 0 {
+		return withExitCode(failureExitCode(errs), fmt.Errorf("%d of %d servers failed", len(errs), len(reports)))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Elicitation modes selected with --elicitation
+const (
+	elicitationOff         = "off"
+	elicitationInteractive = "interactive"
+	elicitationDecline     = "decline"
+)
+
+var elicitationMode string
+
+// ElicitationRequest is the params of an elicitation/create request
+type ElicitationRequest struct {
+	Message         string                 `json:"message"`
+	RequestedSchema map[string]interface{} `json:"requestedSchema"`
+}
+
+// ElicitationResult is the client's answer to an elicitation/create request
+type ElicitationResult struct {
+	Action  string                 `json:"action"`
+	Content map[string]interface{} `json:"content,omitempty"`
+}
+
+// validateElicitation checks the --elicitation flag
+func validateElicitation() error {
+	switch elicitationMode {
+	case elicitationOff, elicitationDecline:
+		return nil
+	case elicitationInteractive:
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("--elicitation interactive needs a terminal on stdin, use --elicitation decline instead")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --elicitation %q, expected off, interactive or decline", elicitationMode)
+	}
+}
+
+// enableElicitation advertises the elicitation capability and answers the
+// server's elicitation/create requests according to --elicitation
+func enableElicitation(session *SessionTransport, serverName string) {
+	session.SetCapability("elicitation", map[string]interface{}{})
+	session.HandleRequest("elicitation/create", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var request ElicitationRequest
+		if err := json.Unmarshal(params, &request); err != nil {
+			return nil, &RPCError{Code: -32602, Message: fmt.Sprintf("invalid elicitation request: %v", err)}
+		}
+
+		if elicitationMode == elicitationDecline {
+			fmt.Fprintf(os.Stderr, "Declined elicitation/create from %s: %s\n", serverName, request.Message)
+			return ElicitationResult{Action: "decline"}, nil
+		}
+		return askElicitation(serverName, request)
+	})
+}
+
+// askElicitation shows an elicitation request on stderr and prompts for each
+// field of its requested schema
+func askElicitation(serverName string, request ElicitationRequest) (*ElicitationResult, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "\nElicitation request from %s:\n%s\n", serverName, indentBlock(request.Message))
+	action, err := promptLine("Action ([a]ccept, [d]ecline, [c]ancel) [a]: ")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(action) {
+	case "", "a", "accept":
+	case "d", "decline":
+		return &ElicitationResult{Action: "decline"}, nil
+	case "c", "cancel":
+		return &ElicitationResult{Action: "cancel"}, nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+
+	// Elicitation schemas are flat objects of primitive properties
+	content := make(map[string]interface{})
+	for _, param := range schemaParams(request.RequestedSchema) {
+		if param.Depth > 0 {
+			continue
+		}
+		for {
+			input, err := promptLine(elicitationQuestion(param))
+			if err != nil {
+				return nil, err
+			}
+			if input == "" {
+				if param.Default != nil {
+					content[param.Name] = param.Default
+					break
+				}
+				if !param.Required {
+					break
+				}
+				fmt.Fprintln(os.Stderr, "  a value is required")
+				continue
+			}
+			value, err := parseElicitationValue(param, input)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  %v\n", err)
+				continue
+			}
+			content[param.Name] = value
+			break
+		}
+	}
+	return &ElicitationResult{Action: "accept", Content: content}, nil
+}
+
+// elicitationQuestion renders the prompt for one field, e.g.
+// "env (string, staging|prod, required): "
+func elicitationQuestion(param SchemaParam) string {
+	details := []string{param.Type}
+	if len(param.Enum) > 0 {
+		details = append(details, formatEnum(param.Enum))
+	}
+	if param.Required {
+		details = append(details, "required")
+	}
+	question := fmt.Sprintf("  %s (%s)", param.Name, strings.Join(details, ", "))
+	if param.Description != "" {
+		question += " " + param.Description
+	}
+	if param.Default != nil {
+		question += fmt.Sprintf(" [%s]", formatSchemaValue(param.Default))
+	}
+	return question + ": "
+}
+
+// parseElicitationValue converts typed input to the field's schema type
+func parseElicitationValue(param SchemaParam, input string) (interface{}, error) {
+	if len(param.Enum) > 0 {
+		for _, v := range param.Enum {
+			if fmt.Sprint(v) == input {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("expected one of %s", formatEnum(param.Enum))
+	}
+
+	switch param.Type {
+	case "integer":
+		n, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return n, nil
+	case "number":
+		f, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return f, nil
+	case "boolean":
+		switch strings.ToLower(input) {
+		case "y", "yes", "true":
+			return true, nil
+		case "n", "no", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("expected yes or no")
+	default:
+		return input, nil
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// envFiles are dotenv files given with --env-file, loaded into every stdio server
+var envFiles []string
+
+// serverEnv is the environment of a stdio server: ours, or only PATH and the
+// locale with --sandbox, then its envFile, relative to the owning project,
+// then its env map, then --env-file files, each overriding the previous
+func serverEnv(server *MCPServer) ([]string, error) {
+	env := os.Environ()
+	if sandbox {
+		env = sandboxBaseEnv()
+	}
+	if server.EnvFile != "" {
+		vars, err := readEnvFile(serverPath(server, server.EnvFile))
+		if err != nil {
+			return nil, err
+		}
+		env = appendEnv(env, vars)
+	}
+	env = appendEnv(env, server.Env)
+	for _, path := range envFiles {
+		vars, err := readEnvFile(path)
+		if err != nil {
+			return nil, err
+		}
+		env = appendEnv(env, vars)
+	}
+	return env, nil
+}
+
+// appendEnv adds variables in a stable order; later entries win in exec
+func appendEnv(env []string, vars map[string]string) []string {
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+vars[key])
+	}
+	return env
+}
+
+// readEnvFile parses a dotenv file: KEY=VALUE lines with optional "export",
+// # comments, and single- or double-quoted values
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("failed to parse env file %s: line %d is not KEY=VALUE", path, lineNo)
+		}
+		vars[key] = parseEnvValue(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read env file: %w", err)
+	}
+	return vars, nil
+}
+
+// parseEnvValue unquotes a dotenv value. Double quotes support \n, \t, \" and
+// \\ escapes, single quotes are literal, and unquoted values end at " #".
+func parseEnvValue(value string) string {
+	if len(value) >= 2 && value[0] == '\'' {
+		if end := strings.IndexByte(value[1:], '\''); end >= 0 {
+			return value[1 : end+1]
+		}
+	}
+	if len(value) >= 2 && value[0] == '"' {
+		var b strings.Builder
+		for i := 1; i < len(value); i++ {
+			c := value[i]
+			if c == '"' {
+				return b.String()
+			}
+			if c == '\\' && i+1 < len(value) {
+				i++
+				switch value[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				case 'r':
+					b.WriteByte('\r')
+				default:
+					b.WriteByte(value[i])
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+		return b.String()
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
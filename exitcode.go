@@ -0,0 +1,107 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Exit codes, kept stable so CI pipelines can tell failures apart
+const (
+	exitOK         = 0
+	exitFailure    = 1 // any failure not listed below
+	exitConfig     = 2 // invalid flags, a config that cannot be loaded or is invalid, or an unknown server
+	exitConnection = 3 // a server could not be started, reached or initialized
+	exitAuth       = 4 // a server rejected the credentials with 401 or 403
+	exitDrift      = 5 // check found drift from the baseline, or diff with --fail-on drift
+	exitCollisions = 6 // collisions found tool names shared by several servers
+	exitFindings   = 7 // audit or registry verify found high severity findings
+)
+
+// exitError is an error that ends the program with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode tags err with the exit code it should end the program with
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode picks the exit code for an error returned by a command. A 401 or
+// 403 anywhere in the chain is an auth failure, whatever else it was tagged.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	var status *statusError
+	if errors.As(err, &status) && (status.status == http.StatusUnauthorized || status.status == http.StatusForbidden) {
+		return exitAuth
+	}
+	var exit *exitError
+	if errors.As(err, &exit) {
+		return exit.code
+	}
+	return exitFailure
+}
+
+// failureExitCode picks one exit code for the failures of several servers: a
+// config error wins over an auth failure, which wins over a connection failure
+func failureExitCode(errs []error) int {
+	code := exitConnection
+	for _, err := range errs {
+		switch exitCode(err) {
+		case exitConfig:
+			return exitConfig
+		case exitAuth:
+			code = exitAuth
+		}
+	}
+	return code
+}
+
+// Conditions of --fail-on
+const (
+	failUnreachable = "unreachable"
+	failDrift       = "drift"
+	failCollisions  = "collisions"
+	failFindings    = "findings"
+)
+
+var failConditions = []string{failUnreachable, failDrift, failCollisions, failFindings}
+
+// failOn holds the --fail-on conditions; nil when the flag is not given
+var failOn []string
+
+func validateFailOn() error {
+	for _, condition := range failOn {
+		if !slices.Contains(failConditions, condition) {
+			return fmt.Errorf("invalid --fail-on %q, expected one of: %s", condition, strings.Join(failConditions, ", "))
+		}
+	}
+	return nil
+}
+
+// failsOn reports whether condition should fail the command. --fail-on
+// replaces the defaults of every command, so only the conditions it lists
+// fail; without it, byDefault decides.
+func failsOn(condition string, byDefault bool) bool {
+	if failOn == nil {
+		return byDefault
+	}
+	return slices.Contains(failOn, condition)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+)
+
+// ToolFilter restricts tools by name with a glob or a regular expression
+type ToolFilter struct {
+	glob  string
+	regex *regexp.Regexp
+}
+
+// NewToolFilter validates the patterns; both empty yields a filter matching everything
+func NewToolFilter(glob, pattern string) (*ToolFilter, error) {
+	f := &ToolFilter{glob: glob}
+	if glob != "" {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid --filter %q: %w", glob, err)
+		}
+	}
+	if pattern != "" {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --filter-regex: %w", err)
+		}
+		f.regex = regex
+	}
+	return f, nil
+}
+
+// Active reports whether the filter excludes anything
+func (f *ToolFilter) Active() bool {
+	return f.glob != "" || f.regex != nil
+}
+
+// Match reports whether a tool name passes the filter. Globs must match the
+// whole name; regular expressions may match any part of it.
+func (f *ToolFilter) Match(name string) bool {
+	if f.glob != "" {
+		if ok, _ := path.Match(f.glob, name); !ok {
+			return false
+		}
+	}
+	if f.regex != nil && !f.regex.MatchString(name) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the tools whose names pass the filter
+func (f *ToolFilter) Apply(tools []ToolInfo) []ToolInfo {
+	if !f.Active() {
+		return tools
+	}
+	matched := []ToolInfo{}
+	for _, tool := range tools {
+		if f.Match(tool.Name) {
+			matched = append(matched, tool)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Fuzz case outcomes. Tool and protocol errors are graceful rejections; the
+// others are findings.
+const (
+	fuzzOK        = "ok"
+	fuzzToolError = "tool error"
+	fuzzRPCError  = "rpc error"
+	fuzzMalformed = "malformed"
+	fuzzTimeout   = "timeout"
+	fuzzCrash     = "crash"
+)
+
+// hugeStringSize is the length of generated oversized strings
+const hugeStringSize = 1 << 20
+
+// FuzzCase is one generated set of arguments for a tool
+type FuzzCase struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"-"`
+}
+
+// FuzzResult is the outcome of calling a tool with one fuzz case
+type FuzzResult struct {
+	Tool    string  `json:"tool"`
+	Case    string  `json:"case"`
+	Outcome string  `json:"outcome"`
+	Detail  string  `json:"detail,omitempty"`
+	TimeMs  float64 `json:"timeMs"`
+}
+
+// Finding reports whether the outcome points at a server bug
+func (r FuzzResult) Finding() bool {
+	switch r.Outcome {
+	case fuzzMalformed, fuzzTimeout, fuzzCrash:
+		return true
+	}
+	return false
+}
+
+func newFuzzCmd() *cobra.Command {
+	var all, dryRun bool
+	var caseTimeout time.Duration
+	fuzzCmd := &cobra.Command{
+		Use:   "fuzz <server-name> [tool]",
+		Short: "Call tools with generated valid and boundary-case inputs",
+		Long: `Generate arguments from each tool's input schema and call the tool with them:
+valid minimal and full inputs, empty arguments, each required property
+missing, wrong types, nulls, values outside enum and numeric bounds, huge
+strings and unknown properties.
+
+Rejections (tool errors and JSON-RPC errors) are expected for invalid input.
+Calls that crash the server, time out or return a response that is not a
+valid tool result are reported as findings, and the command exits with an
+error if there are any. After a crash the server is reconnected.
+
+Fuzzing really calls the tools, so only point it at servers whose tools are
+safe to invoke with arbitrary arguments. Use --dry-run to list the cases.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			tool := ""
+			if len(args) == 2 {
+				tool = args[1]
+			}
+			// Findings are a result, not a usage error
+			cmd.SilenceUsage = true
+			return runFuzz(config, args[0], tool, caseTimeout, all, dryRun)
+		},
+	}
+	fuzzCmd.Flags().BoolVar(&all, "all", false, "show every case, not only findings")
+	fuzzCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the generated cases without calling any tool")
+	fuzzCmd.Flags().DurationVar(&caseTimeout, "case-timeout", 10*time.Second, "how long to wait for each call")
+	return fuzzCmd
+}
+
+func runFuzz(config *ClaudeConfig, serverName, toolName string, caseTimeout time.Duration, all, dryRun bool) error {
+	// Connections outlive individual calls, which get caseTimeout each
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer func() { conn.Close() }()
+
+	inspection, err := collectInspection(ctx, config, conn)
+	if err != nil {
+		return err
+	}
+
+	tools := inspection.Tools
+	if toolName != "" {
+		tools = nil
+		for _, tool := range inspection.Tools {
+			if tool.Name == toolName {
+				tools = append(tools, tool)
+			}
+		}
+		if len(tools) == 0 {
+			return fmt.Errorf("tool '%s' not found on %s", toolName, serverName)
+		}
+	}
+
+	if dryRun {
+		return printFuzzCases(tools)
+	}
+
+	results := []FuzzResult{}
+	for _, tool := range tools {
+		for _, c := range fuzzCases(tool.InputSchema) {
+			result := runFuzzCase(ctx, conn, tool.Name, c, caseTimeout)
+			results = append(results, result)
+
+			if result.Outcome == fuzzCrash || result.Outcome == fuzzTimeout {
+				// Start from a fresh session so one finding doesn't mask the rest
+				conn.Close()
+				if conn, err = openConnection(ctx, config, serverName); err != nil {
+					printFuzzResults(results, all)
+					return fmt.Errorf("failed to reconnect after %s: %w", result.Outcome, err)
+				}
+			}
+		}
+	}
+
+	if err := printFuzzResults(results, all); err != nil {
+		return err
+	}
+
+	findings := 0
+	for _, r := range results {
+		if r.Finding() {
+			findings++
+		}
+	}
+	if findings > 0 {
+		return fmt.Errorf("%d findings in %d calls", findings, len(results))
+	}
+	return nil
+}
+
+// runFuzzCase calls a tool with one case and classifies the response
+func runFuzzCase(ctx context.Context, conn *Connection, tool string, c FuzzCase, timeout time.Duration) FuzzResult {
+	result := FuzzResult{Tool: tool, Case: c.Name}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	arguments := c.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	start := time.Now()
+	raw, err := conn.Session.Request(callCtx, "tools/call", map[string]interface{}{
+		"name":      tool,
+		"arguments": arguments,
+	})
+	result.TimeMs = durationMs(time.Since(start))
+
+	var rpcErr *RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		result.Outcome = fuzzRPCError
+		result.Detail = rpcErr.Error()
+	case errors.Is(err, context.DeadlineExceeded):
+		result.Outcome = fuzzTimeout
+		result.Detail = fmt.Sprintf("no response after %s", timeout)
+	case err != nil:
+		result.Outcome = fuzzCrash
+		result.Detail = err.Error()
+	default:
+		result.Outcome, result.Detail = classifyToolResult(raw)
+	}
+
+	// A server that exits after answering has still crashed
+	select {
+	case <-conn.Session.Done():
+		if result.Outcome != fuzzCrash {
+			result.Detail = strings.TrimSpace(result.Outcome + ", then the connection closed")
+			result.Outcome = fuzzCrash
+		}
+	default:
+	}
+
+	return result
+}
+
+// classifyToolResult checks that a tools/call result has the shape the protocol requires
+func classifyToolResult(raw json.RawMessage) (string, string) {
+	var result map[string]interface{}
+	if err := json.Unmarshal(raw, &result); err != nil || result == nil {
+		return fuzzMalformed, "result is not a JSON object"
+	}
+
+	content, ok := result["content"].([]interface{})
+	if !ok {
+		return fuzzMalformed, "result has no content array"
+	}
+	for i, item := range content {
+		block, ok := item.(map[string]interface{})
+		if !ok {
+			return fuzzMalformed, fmt.Sprintf("content[%d] is not an object", i)
+		}
+		if _, ok := block["type"].(string); !ok {
+			return fuzzMalformed, fmt.Sprintf("content[%d] has no type", i)
+		}
+	}
+
+	isError, present := result["isError"]
+	if !present || isError == false {
+		return fuzzOK, ""
+	}
+	if isError != true {
+		return fuzzMalformed, "isError is not a boolean"
+	}
+
+	detail := ""
+	if len(content) > 0 {
+		if text, ok := content[0].(map[string]interface{})["text"].(string); ok {
+			detail = text
+		}
+	}
+	return fuzzToolError, detail
+}
+
+// fuzzCases generates the argument sets to try for an input schema. Only
+// top-level properties are mutated; nested values come from validValue.
+func fuzzCases(inputSchema interface{}) []FuzzCase {
+	schema := asSchemaMap(inputSchema)
+	properties := asSchemaMap(schema["properties"])
+	required := requiredSet(schema)
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	minimal := func() map[string]interface{} {
+		args, _ := validValue(schema, false).(map[string]interface{})
+		if args == nil {
+			args = map[string]interface{}{}
+		}
+		return args
+	}
+	with := func(name string, value interface{}) map[string]interface{} {
+		args := minimal()
+		args[name] = value
+		return args
+	}
+
+	cases := []FuzzCase{{Name: "valid (required only)", Arguments: minimal()}}
+	if full, ok := validValue(schema, true).(map[string]interface{}); ok && len(full) > len(minimal()) {
+		cases = append(cases, FuzzCase{Name: "valid (all properties)", Arguments: full})
+	}
+	if len(required) > 0 {
+		cases = append(cases, FuzzCase{Name: "empty arguments", Arguments: map[string]interface{}{}})
+	}
+
+	for _, name := range names {
+		prop := asSchemaMap(properties[name])
+		typ := strings.SplitN(schemaType(prop), "|", 2)[0]
+
+		if required[name] {
+			args := minimal()
+			delete(args, name)
+			cases = append(cases, FuzzCase{Name: "missing " + name, Arguments: args})
+		}
+		cases = append(cases, FuzzCase{Name: "wrong type " + name, Arguments: with(name, wrongTypeValue(typ))})
+		cases = append(cases, FuzzCase{Name: "null " + name, Arguments: with(name, nil)})
+
+		if enum, ok := prop["enum"].([]interface{}); ok && len(enum) > 0 {
+			cases = append(cases, FuzzCase{Name: "not in enum " + name, Arguments: with(name, "__mcpinspect_fuzz__")})
+		}
+
+		switch {
+		case typ == "string":
+			cases = append(cases, FuzzCase{Name: "empty string " + name, Arguments: with(name, "")})
+			cases = append(cases, FuzzCase{Name: "huge string " + name, Arguments: with(name, strings.Repeat("A", hugeStringSize))})
+		case typ == "integer" || typ == "number":
+			if min, ok := prop["minimum"].(float64); ok {
+				cases = append(cases, FuzzCase{Name: "below minimum " + name, Arguments: with(name, min-1)})
+			}
+			if max, ok := prop["maximum"].(float64); ok {
+				cases = append(cases, FuzzCase{Name: "above maximum " + name, Arguments: with(name, max+1)})
+			}
+			cases = append(cases, FuzzCase{Name: "huge number " + name, Arguments: with(name, 1e300)})
+			cases = append(cases, FuzzCase{Name: "negative " + name, Arguments: with(name, -1)})
+		case strings.HasPrefix(typ, "array"):
+			items := validValue(asSchemaMap(prop["items"]), false)
+			huge := make([]interface{}, 10000)
+			for i := range huge {
+				huge[i] = items
+			}
+			cases = append(cases, FuzzCase{Name: "huge array " + name, Arguments: with(name, huge)})
+		}
+	}
+
+	unknown := minimal()
+	unknown["__mcpinspect_fuzz__"] = true
+	cases = append(cases, FuzzCase{Name: "unknown property", Arguments: unknown})
+
+	return cases
+}
+
+// validValue builds a value satisfying a schema node's type, enum and bounds.
+// Optional object properties are only included when includeOptional is set.
+func validValue(schema map[string]interface{}, includeOptional bool) interface{} {
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if c, ok := schema["const"]; ok {
+		return c
+	}
+
+	typ := strings.SplitN(schemaType(schema), "|", 2)[0]
+	switch {
+	case typ == "string":
+		s := "fuzz"
+		if min, ok := schema["minLength"].(float64); ok && len(s) < int(min) {
+			s += strings.Repeat("z", int(min)-len(s))
+		}
+		if max, ok := schema["maxLength"].(float64); ok && len(s) > int(max) {
+			s = s[:int(max)]
+		}
+		return s
+	case typ == "integer" || typ == "number":
+		if min, ok := schema["minimum"].(float64); ok {
+			return min
+		}
+		if max, ok := schema["maximum"].(float64); ok && max < 1 {
+			return max
+		}
+		return 1
+	case typ == "boolean":
+		return true
+	case strings.HasPrefix(typ, "array"):
+		items := asSchemaMap(schema["items"])
+		if min, ok := schema["minItems"].(float64); ok && min > 0 && items != nil {
+			list := make([]interface{}, int(min))
+			for i := range list {
+				list[i] = validValue(items, includeOptional)
+			}
+			return list
+		}
+		return []interface{}{}
+	case typ == "object":
+		properties := asSchemaMap(schema["properties"])
+		required := requiredSet(schema)
+		value := make(map[string]interface{}, len(properties))
+		for name, prop := range properties {
+			if includeOptional || required[name] {
+				value[name] = validValue(asSchemaMap(prop), includeOptional)
+			}
+		}
+		return value
+	default:
+		return "fuzz"
+	}
+}
+
+// wrongTypeValue returns a value that does not match the given schema type
+func wrongTypeValue(typ string) interface{} {
+	switch {
+	case typ == "string":
+		return 12345
+	case typ == "integer" || typ == "number" || typ == "boolean":
+		return "not-a-" + typ
+	case strings.HasPrefix(typ, "array"):
+		return map[string]interface{}{"not": "an array"}
+	default:
+		return []interface{}{"not", "an", "object"}
+	}
+}
+
+func printFuzzCases(tools []ToolInfo) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOOL\tCASE\tARGUMENTS")
+	total := 0
+	for _, tool := range tools {
+		for _, c := range fuzzCases(tool.InputSchema) {
+			args := compactJSON(c.Arguments)
+			if len(args) > 80 {
+				args = args[:77] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", tool.Name, c.Name, args)
+			total++
+		}
+	}
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d cases for %d tools\n", total, len(tools))
+	return nil
+}
+
+func printFuzzResults(results []FuzzResult, all bool) error {
+	counts := make(map[string]int)
+	shown := []FuzzResult{}
+	for _, r := range results {
+		counts[r.Outcome]++
+		if all || r.Finding() {
+			shown = append(shown, r)
+		}
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(shown)
+	case outputCSV:
+		rows := make([][]string, 0, len(shown))
+		for _, r := range shown {
+			rows = append(rows, []string{r.Tool, r.Case, r.Outcome, r.Detail, formatMs(r.TimeMs)})
+		}
+		return writeCSV([]string{"TOOL", "CASE", "OUTCOME", "DETAIL", "TIME MS"}, rows)
+	}
+
+	if len(shown) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TOOL\tCASE\tOUTCOME\tTIME\tDETAIL")
+		for _, r := range shown {
+			detail := strings.ReplaceAll(r.Detail, "\n", " ")
+			if len(detail) > 80 {
+				detail = detail[:77] + "..."
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%sms\t%s\n", r.Tool, r.Case, r.Outcome, formatMs(r.TimeMs), detail)
+		}
+		w.Flush()
+		fmt.Println()
+	} else if len(results) > 0 {
+		fmt.Println("No findings.")
+		fmt.Println()
+	}
+
+	// Print summary
+	parts := []string{}
+	for _, outcome := range []string{fuzzOK, fuzzToolError, fuzzRPCError, fuzzMalformed, fuzzTimeout, fuzzCrash} {
+		if counts[outcome] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[outcome], outcome))
+		}
+	}
+	fmt.Printf("%d calls | %s\n", len(results), strings.Join(parts, " | "))
+	return nil
+}
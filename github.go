@@ -0,0 +1,276 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Levels of GitHub Actions annotations
+const (
+	annotationError   = "error"
+	annotationWarning = "warning"
+)
+
+// annotation is a GitHub Actions workflow command of --output github. It shows
+// on the run summary, and inline on the pull request diff when it has a file.
+type annotation struct {
+	level   string
+	file    string
+	line    int
+	title   string
+	message string
+}
+
+// writeAnnotations prints annotations to stdout as ::error and ::warning
+// workflow commands
+func writeAnnotations(annotations []annotation) error {
+	for _, a := range annotations {
+		command := "::" + a.level
+		var props []string
+		if a.file != "" {
+			props = append(props, "file="+escapeAnnotationProperty(a.file))
+			if a.line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", a.line))
+			}
+		}
+		if a.title != "" {
+			props = append(props, "title="+escapeAnnotationProperty(a.title))
+		}
+		if len(props) > 0 {
+			command += " " + strings.Join(props, ",")
+		}
+		if _, err := fmt.Fprintf(os.Stdout, "%s::%s\n", command, escapeAnnotationData(a.message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// escapeAnnotationData escapes a message the way the Actions toolkit does
+func escapeAnnotationData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeAnnotationProperty escapes a property value, which also ends at : and ,
+func escapeAnnotationProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// annotationLevel is error for findings that fail the command and warning for the rest
+func annotationLevel(fails bool) string {
+	if fails {
+		return annotationError
+	}
+	return annotationWarning
+}
+
+// serverLocation finds the line defining a server in the client's project
+// config, such as .mcp.json, so its annotations show inline on pull requests
+// changing that file. Servers of other scopes live outside the repository
+// and get no location.
+func serverLocation(scope, serverName string) (string, int) {
+	projectFile, ok := clientProjectFiles[clientName]
+	if scope != scopeProject || !ok {
+		return "", 0
+	}
+	path, ok := findUp(projectFile)
+	if !ok {
+		return "", 0
+	}
+
+	data, _ := os.ReadFile(path)
+	return repoPath(path), keyLine(data, `"`+regexp.QuoteMeta(serverName)+`"\s*:`)
+}
+
+// repoPath makes a path relative to the repository root, as annotations take them
+func repoPath(path string) string {
+	root := os.Getenv("GITHUB_WORKSPACE")
+	if root == "" {
+		root, _ = os.Getwd()
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// keyLine returns the line of the first match of pattern in data, or 0
+func keyLine(data []byte, pattern string) int {
+	loc := regexp.MustCompile(pattern).FindIndex(data)
+	if loc == nil {
+		return 0
+	}
+	return strings.Count(string(data[:loc[0]]), "\n") + 1
+}
+
+// pingAnnotations reports each unreachable server
+func pingAnnotations(results []PingResult) []annotation {
+	var annotations []annotation
+	for _, r := range results {
+		if r.Reachable {
+			continue
+		}
+		file, line := serverLocation(r.scope, r.Name)
+		annotations = append(annotations, annotation{
+			level: annotationLevel(failsOn(failUnreachable, true)), file: file, line: line,
+			title: "MCP server " + r.Name + " unreachable", message: r.Error,
+		})
+	}
+	return annotations
+}
+
+// probeAnnotations reports each server --probe could not reach
+func probeAnnotations(servers []*ServerInfo) []annotation {
+	var annotations []annotation
+	for _, info := range servers {
+		if info.Probe == nil || info.Probe.Status == probeOK {
+			continue
+		}
+		var file string
+		var line int
+		for _, scope := range info.Scopes {
+			if file, line = serverLocation(scope, info.Name); file != "" {
+				break
+			}
+		}
+		annotations = append(annotations, annotation{
+			level: annotationLevel(failsOn(failUnreachable, false)), file: file, line: line,
+			title: "MCP server " + info.Name + " unreachable", message: info.Probe.Error,
+		})
+	}
+	return annotations
+}
+
+// doctorAnnotations reports each failed check as an error and each warning as a warning
+func doctorAnnotations(reports []DoctorReport) []annotation {
+	var annotations []annotation
+	for _, report := range reports {
+		file, line := serverLocation(report.scope, report.Server)
+		for _, check := range report.Checks {
+			var level string
+			switch check.Status {
+			case doctorFail:
+				level = annotationError
+			case doctorWarn:
+				level = annotationWarning
+			default:
+				continue
+			}
+			message := check.Detail
+			if check.Hint != "" {
+				message += "\nhint: " + check.Hint
+			}
+			annotations = append(annotations, annotation{
+				level: level, file: file, line: line,
+				title: fmt.Sprintf("MCP server %s: %s check", report.Server, check.Name), message: message,
+			})
+		}
+	}
+	return annotations
+}
+
+// diffAnnotations reports each added, removed and changed tool, as errors when
+// the drift fails the command. Removed and changed tools are annotated at their
+// entry in baseline, the committed snapshot, when one is given.
+func diffAnnotations(diff *DiffResult, fails bool, baseline string) []annotation {
+	level := annotationLevel(fails)
+	file, data := "", []byte(nil)
+	if baseline != "" {
+		file = repoPath(baseline)
+		data, _ = os.ReadFile(baseline)
+	}
+	toolLine := func(name string) int {
+		return keyLine(data, `"name"\s*:\s*"`+regexp.QuoteMeta(name)+`"`)
+	}
+
+	var annotations []annotation
+	for _, name := range diff.Added {
+		annotations = append(annotations, annotation{level: level, file: file, title: "Tool added: " + name, message: "The server now exposes " + name + "."})
+	}
+	for _, name := range diff.Removed {
+		annotations = append(annotations, annotation{level: level, file: file, line: toolLine(name), title: "Tool removed: " + name, message: "The server no longer exposes " + name + "."})
+	}
+	for _, change := range diff.Changed {
+		var lines []string
+		if change.DescriptionChanged() {
+			lines = append(lines, fmt.Sprintf("description: %q -> %q", change.OldDescription, change.NewDescription))
+		}
+		for _, sc := range change.SchemaChanges {
+			lines = append(lines, formatSchemaChange(sc))
+		}
+		annotations = append(annotations, annotation{level: level, file: file, line: toolLine(change.Name), title: "Tool changed: " + change.Name, message: strings.Join(lines, "\n")})
+	}
+	return annotations
+}
+
+// collisionAnnotations reports each colliding tool name and each server that could not be listed
+func collisionAnnotations(report *CollisionReport) []annotation {
+	var annotations []annotation
+	for _, c := range report.Collisions {
+		annotations = append(annotations, annotation{
+			level: annotationLevel(failsOn(failCollisions, true)),
+			title: "Tool name collision: " + c.Tool,
+			message: fmt.Sprintf("%s is exposed by %s in %s; the servers shadow each other's tool.",
+				c.Tool, strings.Join(c.Servers, ", "), c.Project),
+		})
+	}
+	names := make([]string, 0, len(report.Errors))
+	for name := range report.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		annotations = append(annotations, annotation{
+			level: annotationLevel(failsOn(failUnreachable, false)),
+			title: "MCP server " + name + " unreachable", message: report.Errors[name],
+		})
+	}
+	return annotations
+}
+
+// configIssueAnnotations reports each config validate finding at its server's definition
+func configIssueAnnotations(config *ClaudeConfig, issues []ConfigIssue) []annotation {
+	annotations := make([]annotation, 0, len(issues))
+	for _, issue := range issues {
+		file, line := serverLocation(config.Projects[issue.Project].MCPServers[issue.Server].Scope, issue.Server)
+		annotations = append(annotations, annotation{
+			level: issue.Severity, file: file, line: line,
+			title: "MCP server " + issue.Server, message: issue.Message,
+		})
+	}
+	return annotations
+}
+
+// auditAnnotations reports each finding at its server's definition, as errors
+// for the high findings that fail the command, and each server not audited
+func auditAnnotations(report *AuditReport) []annotation {
+	var annotations []annotation
+	for _, f := range report.Findings {
+		file, line := serverLocation(f.scope, f.Server)
+		annotations = append(annotations, annotation{
+			level: annotationLevel(f.Severity == auditHigh && failsOn(failFindings, true)), file: file, line: line,
+			title:   fmt.Sprintf("MCP server %s: %s (%s)", f.Server, f.Rule, f.Severity),
+			message: fmt.Sprintf("%s %s: %s", f.Location, f.Message, f.Match),
+		})
+	}
+	names := make([]string, 0, len(report.Errors))
+	for name := range report.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		annotations = append(annotations, annotation{
+			level: annotationLevel(failsOn(failUnreachable, true)),
+			title: "MCP server " + name + " unreachable", message: report.Errors[name],
+		})
+	}
+	return annotations
+}
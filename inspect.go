@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+func newInspectCmd() *cobra.Command {
+	var command, serverURL, transportType, filterGlob, filterRegex string
+	var schemas bool
+	inspectCmd := &cobra.Command{
+		Use:   "inspect [server-name] [tool] | --command <cmd> [tool] [-- args...] | --url <url> [tool]",
+		Short: "Inspect a configured server, or one given by command or URL",
+		Long: `Inspect a server's tools like "mcpinspect <server-name> [tool]".
+
+With --command the server is started from that command instead of a config
+file, so a server can be tried before adding it to one. Everything after --
+is passed to the command as arguments. With --url the server at that address
+is inspected. In both cases a single argument before -- is a tool name.
+
+Examples:
+  mcpinspect inspect linear-server
+  mcpinspect inspect --command npx -- -y @modelcontextprotocol/server-everything
+  mcpinspect inspect --command ./bin/server get_issue -- --verbose
+  mcpinspect inspect --url http://localhost:3000/mcp`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter, err := NewToolFilter(filterGlob, filterRegex)
+			if err != nil {
+				return err
+			}
+
+			// Arguments after -- belong to --command
+			positional, commandArgs := args, []string(nil)
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				positional, commandArgs = args[:dash], args[dash:]
+			}
+			if command == "" && commandArgs != nil {
+				return fmt.Errorf("arguments after -- are only used with --command")
+			}
+
+			var config *ClaudeConfig
+			switch {
+			case command != "":
+				name := filepath.Base(command)
+				config = adhocConfig(name, MCPServer{Type: "stdio", Command: command, Args: commandArgs})
+				positional = append([]string{name}, positional...)
+			case serverURL != "":
+				adhoc, name, err := adhocURLConfig(serverURL, transportType)
+				if err != nil {
+					return err
+				}
+				config = adhoc
+				positional = append([]string{name}, positional...)
+			default:
+				if cmd.Flags().Changed("transport") {
+					return fmt.Errorf("--transport only applies with --url")
+				}
+				config, err = loadConfig(configPath)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
+			}
+
+			switch len(positional) {
+			case 0:
+				return fmt.Errorf("specify a server name, --command or --url")
+			case 1:
+				// Connection failures are a result, not a usage error
+				cmd.SilenceUsage = true
+				return inspectServer(config, positional[0], filter, schemas)
+			case 2:
+				if filter.Active() || schemas {
+					return fmt.Errorf("--filter and --schemas do not apply when inspecting a single tool")
+				}
+				cmd.SilenceUsage = true
+				return inspectTool(config, positional[0], positional[1])
+			default:
+				return fmt.Errorf("too many arguments; pass server arguments after --")
+			}
+		},
+	}
+	inspectCmd.Flags().StringVar(&command, "command", "", "start the stdio server with this command instead of a configured one")
+	inspectCmd.Flags().StringVar(&serverURL, "url", "", "inspect the MCP server at this URL instead of a configured one")
+	inspectCmd.Flags().StringVar(&transportType, "transport", "http", "transport for --url: http (Streamable HTTP) or sse")
+	inspectCmd.Flags().BoolVar(&schemas, "schemas", false, "show each tool's input schema as a parameter table")
+	inspectCmd.Flags().StringVar(&filterGlob, "filter", "", "only show tools whose names match this glob, e.g. 'git_*'")
+	inspectCmd.Flags().StringVar(&filterRegex, "filter-regex", "", "only show tools whose names match this regular expression")
+	inspectCmd.MarkFlagsMutuallyExclusive("command", "url")
+	inspectCmd.MarkFlagsMutuallyExclusive("filter", "filter-regex")
+	return inspectCmd
+}
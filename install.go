@@ -0,0 +1,360 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// serverNameAffixes are stripped from package names to name installed servers
+var serverNameAffixes = regexp.MustCompile(`^(mcp-server-|server-)|(-mcp-server|-mcp)$`)
+
+func newInstallCmd() *cobra.Command {
+	var (
+		name     string
+		env      []string
+		project  string
+		user     bool
+		force    bool
+		remote   bool
+		noVerify bool
+	)
+	installCmd := &cobra.Command{
+		Use:   "install <registry-name[@version]|npm-package[@version]>",
+		Short: "Add a server from the MCP registry or npm to the config",
+		Long: `Resolve a server published in the MCP registry, such as
+io.github.modelcontextprotocol/everything, or an npm package, such as
+@modelcontextprotocol/server-everything, and add it to the Claude Code config.
+
+The definition runs the package pinned to its current version: npx for npm,
+uvx for PyPI, docker run for OCI images and dnx for NuGet. --remote uses the
+server's hosted URL instead. Values the server requires are given with --env
+for environment variables and --header for the headers of remote servers.
+
+Before writing, the server is started and inspected, which also pins its
+tools; a server that cannot be reached is not added unless --no-verify is
+given. Like "config add", the server goes to the project in the working
+directory, the one given with --project, or with --user to the user scope.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			values := make(map[string]string, len(env))
+			for _, pair := range env {
+				key, value, ok := strings.Cut(pair, "=")
+				if !ok || key == "" {
+					return fmt.Errorf("invalid --env %q, expected KEY=VALUE", pair)
+				}
+				values[key] = value
+			}
+			key, err := projectKey(project, user)
+			if err != nil {
+				return err
+			}
+			// Registry, connection and config file problems are not usage errors
+			cmd.SilenceUsage = true
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			resolved, server, err := resolveInstall(ctx, args[0], remote, values)
+			if err != nil {
+				return err
+			}
+			if name == "" {
+				name = resolved
+			}
+			for _, issue := range checkServer(server, false) {
+				if issue.Severity == severityError {
+					return fmt.Errorf("invalid server %s: %s", name, issue.Message)
+				}
+				fmt.Fprintf(os.Stderr, "Warning: %s\n", issue.Message)
+			}
+
+			file, err := openConfigFile(configPath)
+			if err != nil {
+				return err
+			}
+			servers, err := file.servers(key)
+			if err != nil {
+				return err
+			}
+			if _, exists := servers[name]; exists && !force {
+				return fmt.Errorf("server %s already exists in %s, use --force to replace it or --name to pick another name", name, key)
+			}
+
+			if !noVerify {
+				if err := verifyInstall(ctx, name, server); err != nil {
+					return fmt.Errorf("server %s not added: %w; use --no-verify to add it anyway", name, err)
+				}
+			}
+
+			raw, err := marshalRaw(server)
+			if err != nil {
+				return fmt.Errorf("failed to encode server: %w", err)
+			}
+			servers[name] = raw
+			if err := file.setServers(key, servers); err != nil {
+				return err
+			}
+			if err := file.save(); err != nil {
+				return err
+			}
+			fmt.Printf("Added %s server %s to %s in %s\n", server.Type, name, key, configPath)
+			return nil
+		},
+	}
+	installCmd.Flags().StringVar(&name, "name", "", "name of the server in the config (default derived from the registry name or package)")
+	installCmd.Flags().StringArrayVar(&env, "env", nil, "environment variable for the server as KEY=VALUE (repeatable)")
+	installCmd.Flags().StringVar(&project, "project", "", "project directory to add the server to (default the working directory)")
+	installCmd.Flags().BoolVar(&user, "user", false, "add the server to the user scope, available in every project")
+	installCmd.Flags().BoolVar(&force, "force", false, "replace an existing server with the same name")
+	installCmd.Flags().BoolVar(&remote, "remote", false, "use the server's hosted URL rather than running its package")
+	installCmd.Flags().BoolVar(&noVerify, "no-verify", false, "add the server without starting and inspecting it first")
+	installCmd.Flags().StringVar(&registryURL, "registry", defaultRegistryURL, "base URL of the MCP registry or a compatible subregistry")
+	installCmd.MarkFlagsMutuallyExclusive("project", "user")
+	return installCmd
+}
+
+// resolveInstall turns an install target into a server definition and the
+// name it is added as. A target with a slash that is not a scoped npm package
+// is a registry name, as registry names are namespaced like io.github.user/server.
+func resolveInstall(ctx context.Context, target string, remote bool, env map[string]string) (string, MCPServer, error) {
+	if strings.Contains(target, "/") && !strings.HasPrefix(target, "@") {
+		name, version := target, "latest"
+		if i := strings.LastIndex(target, "@"); i > 0 {
+			name, version = target[:i], target[i+1:]
+		}
+		entry, err := fetchRegistryServer(ctx, name, version)
+		if err != nil {
+			return "", MCPServer{}, err
+		}
+		switch entry.Meta.Official.Status {
+		case "deleted":
+			return "", MCPServer{}, fmt.Errorf("%s %s was deleted from the registry", name, entry.Server.Version)
+		case "deprecated":
+			fmt.Fprintf(os.Stderr, "Warning: %s %s is deprecated in the registry\n", name, entry.Server.Version)
+		}
+		server, err := registryServerDefinition(entry.Server, remote, env)
+		return installName(name[strings.LastIndex(name, "/")+1:]), server, err
+	}
+
+	if remote {
+		return "", MCPServer{}, fmt.Errorf("--remote needs a registry name: npm packages run locally")
+	}
+	ref, ok := npmPackage(target)
+	if !ok {
+		return "", MCPServer{}, fmt.Errorf("invalid target %q, expected a registry name such as io.github.user/server or an npm package", target)
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	provenance := &Provenance{PackageRef: ref}
+	if err := npmProvenance(lookupCtx, provenance); err != nil {
+		return "", MCPServer{}, withExitCode(exitConnection, err)
+	}
+	if provenance.Version == "" {
+		return "", MCPServer{}, fmt.Errorf("npm package %s has no version or tag %s; give an exact version", ref.Name, ref.Requested)
+	}
+	pkg := RegistryPackage{RegistryType: ecosystemNpm, Identifier: ref.Name, Version: provenance.Version}
+	pkg.Transport.Type = "stdio"
+	server, err := packageDefinition(pkg, env)
+	return installName(ref.Name[strings.LastIndex(ref.Name, "/")+1:]), server, err
+}
+
+// installName names a server after its package, without affixes such as mcp-server-
+func installName(name string) string {
+	if stripped := serverNameAffixes.ReplaceAllString(name, ""); stripped != "" {
+		return stripped
+	}
+	return name
+}
+
+// fetchRegistryServer returns a version of a registry server, "latest" for the current one
+func fetchRegistryServer(ctx context.Context, name, version string) (*RegistryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var entry RegistryEntry
+	target := fmt.Sprintf("%s/v0/servers/%s/versions/%s", strings.TrimSuffix(registryURL, "/"), url.PathEscape(name), url.PathEscape(version))
+	if err := getJSON(ctx, http.DefaultClient, target, &entry); err != nil {
+		return nil, withExitCode(exitConnection, fmt.Errorf("failed to look up %s in the registry: %w", name, err))
+	}
+	return &entry, nil
+}
+
+// registryServerDefinition picks how to run a registry server: its first stdio
+// package with a known runtime, or its first remote with --remote or when it
+// has no such package
+func registryServerDefinition(server RegistryServer, remote bool, env map[string]string) (MCPServer, error) {
+	if !remote {
+		for _, pkg := range server.Packages {
+			if pkg.Transport.Type == "stdio" && packageRuntime(pkg) != "" {
+				return packageDefinition(pkg, env)
+			}
+		}
+	}
+	for _, r := range server.Remotes {
+		if r.Type == "streamable-http" || r.Type == "sse" {
+			return remoteDefinition(r)
+		}
+	}
+	if remote {
+		return MCPServer{}, fmt.Errorf("%s has no remote URL", server.Name)
+	}
+	return MCPServer{}, fmt.Errorf("%s has no stdio package or remote URL mcpinspect can configure", server.Name)
+}
+
+// packageRuntime is the command running packages of a registry type
+func packageRuntime(pkg RegistryPackage) string {
+	switch pkg.RegistryType {
+	case ecosystemNpm:
+		return "npx"
+	case ecosystemPyPI:
+		return "uvx"
+	case "oci":
+		return "docker"
+	case "nuget":
+		return "dnx"
+	}
+	return ""
+}
+
+// packageDefinition builds the stdio definition running a package pinned to
+// its version. Environment variables come from env, then their defaults.
+func packageDefinition(pkg RegistryPackage, env map[string]string) (MCPServer, error) {
+	server := MCPServer{Type: "stdio", Command: packageRuntime(pkg)}
+	var missing []string
+
+	values := make(map[string]string)
+	for _, variable := range pkg.EnvironmentVariables {
+		switch value := cmp.Or(env[variable.Name], variable.Value, variable.Default); {
+		case value != "":
+			values[variable.Name] = value
+		case variable.IsRequired:
+			missing = append(missing, "--env "+variable.Name+"=...")
+		}
+	}
+	// Variables the registry does not list are passed on as given
+	for key, value := range env {
+		values[key] = value
+	}
+	if len(values) > 0 {
+		server.Env = values
+	}
+
+	runtimeArgs, err := registryArguments(pkg.RuntimeArguments)
+	if err != nil {
+		return MCPServer{}, err
+	}
+	packageArgs, err := registryArguments(pkg.PackageArguments)
+	if err != nil {
+		return MCPServer{}, err
+	}
+
+	switch pkg.RegistryType {
+	case ecosystemNpm:
+		server.Args = append(append(runtimeArgs, "-y", withVersion(pkg.Identifier, "@", pkg.Version)), packageArgs...)
+	case ecosystemPyPI:
+		server.Args = append(append(runtimeArgs, withVersion(pkg.Identifier, "==", pkg.Version)), packageArgs...)
+	case "oci":
+		image := pkg.Identifier
+		if tag := image[strings.LastIndex(image, "/")+1:]; pkg.Version != "" && !strings.ContainsAny(tag, ":@") {
+			image += ":" + pkg.Version
+		}
+		// The container gets the server's variables from docker's environment
+		server.Args = append([]string{"run", "-i", "--rm"}, runtimeArgs...)
+		for _, key := range sortedStringKeys(values) {
+			server.Args = append(server.Args, "-e", key)
+		}
+		server.Args = append(append(server.Args, image), packageArgs...)
+	case "nuget":
+		server.Args = append(append(runtimeArgs, withVersion(pkg.Identifier, "@", pkg.Version), "--yes"), packageArgs...)
+	}
+
+	if len(missing) > 0 {
+		return MCPServer{}, fmt.Errorf("%s needs %s", pkg.Identifier, strings.Join(missing, ", "))
+	}
+	return server, nil
+}
+
+// registryArguments renders a package's arguments: a positional value, or a
+// named flag followed by its value. An argument the user must fill in, with no
+// value or default, cannot be installed.
+func registryArguments(args []RegistryArgument) ([]string, error) {
+	var rendered []string
+	for _, arg := range args {
+		value := cmp.Or(arg.Value, arg.Default)
+		switch {
+		case arg.Type == "named" && value != "":
+			rendered = append(rendered, arg.Name, value)
+		case arg.Type == "named":
+			rendered = append(rendered, arg.Name)
+		case value != "":
+			rendered = append(rendered, value)
+		case arg.IsRequired:
+			return nil, fmt.Errorf("the package needs the argument %s, which install cannot fill in; add it with \"config add\"", cmp.Or(arg.ValueHint, arg.Name, "value"))
+		}
+	}
+	return rendered, nil
+}
+
+// remoteDefinition builds the definition of a hosted server. Only the headers
+// the registry declares are saved, with their value from --header; other
+// headers, such as a one-off Authorization, are sent while verifying only.
+func remoteDefinition(remote RegistryRemote) (MCPServer, error) {
+	server := MCPServer{Type: "http", URL: remote.URL}
+	if remote.Type == "sse" {
+		server.Type = "sse"
+	}
+	var missing, secrets []string
+	for _, header := range remote.Headers {
+		value, ok := extraHeaders[http.CanonicalHeaderKey(header.Name)]
+		if !ok {
+			value = header.Value
+		}
+		if value == "" {
+			if header.IsRequired {
+				missing = append(missing, fmt.Sprintf("--header '%s: ...'", header.Name))
+			}
+			continue
+		}
+		if ok && header.IsSecret && !isVariableReference(value) {
+			secrets = append(secrets, header.Name)
+		}
+		if server.Headers == nil {
+			server.Headers = make(map[string]string)
+		}
+		server.Headers[header.Name] = value
+	}
+	if len(missing) > 0 {
+		return MCPServer{}, fmt.Errorf("%s needs %s", remote.URL, strings.Join(missing, ", "))
+	}
+	for _, name := range secrets {
+		fmt.Fprintf(os.Stderr, "Warning: header %s is a secret and is saved in plain text in %s\n", name, configPath)
+	}
+	return server, nil
+}
+
+// verifyInstall starts a server before it is added and prints what it offers,
+// pinning its tools like a first inspection does
+func verifyInstall(ctx context.Context, name string, server MCPServer) error {
+	conn, err := openConnection(ctx, adhocConfig(name, server), name)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tools, err := listTools(ctx, conn)
+	if err != nil {
+		return err
+	}
+	verifyPin(conn, tools)
+	printProvenance(lookupProvenance(ctx, server))
+	fmt.Printf("Verified %s: %d tools | %s | %s\n", name, len(tools), server.Type, formatServerInfo(conn.Init))
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// JUnit XML report of --output junit, in the dialect Jenkins and GitLab read:
+// a suite per command or server and a test case per server or check
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit prints suites to stdout as a JUnit XML report, filling in the counts
+func writeJUnit(name string, suites []junitTestSuite) error {
+	report := junitTestSuites{Name: name, Suites: suites}
+	timestamp := time.Now().UTC().Format("2006-01-02T15:04:05")
+	for i := range report.Suites {
+		suite := &report.Suites[i]
+		suite.Timestamp = timestamp
+		suite.Tests = len(suite.Cases)
+		for _, testCase := range suite.Cases {
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s%s\n", xml.Header, data)
+	return err
+}
+
+// junitSeconds renders milliseconds as the seconds of a test case's time attribute
+func junitSeconds(ms float64) string {
+	return strconv.FormatFloat(ms/1000, 'f', 3, 64)
+}
+
+// pingJUnit reports each server as a test case that fails when it is unreachable
+func pingJUnit(results []PingResult) []junitTestSuite {
+	suite := junitTestSuite{Name: "ping"}
+	for _, r := range results {
+		testCase := junitTestCase{ClassName: "mcpinspect.ping", Name: r.Name, Time: junitSeconds(r.ConnectMs + r.RTTMs)}
+		if !r.Reachable {
+			testCase.Failure = &junitFailure{Message: "unreachable", Text: r.Error}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	return []junitTestSuite{suite}
+}
+
+// probeJUnit reports each probed server as a test case that fails when it
+// could not be initialized or list its tools
+func probeJUnit(servers []*ServerInfo) []junitTestSuite {
+	suite := junitTestSuite{Name: "probe"}
+	for _, info := range servers {
+		testCase := junitTestCase{ClassName: "mcpinspect.probe", Name: info.Name}
+		if info.Probe.Status != probeOK {
+			testCase.Failure = &junitFailure{Message: info.Probe.Error, Text: info.Probe.Error}
+		} else {
+			testCase.SystemOut = fmt.Sprintf("%d tools | %s", info.Probe.Tools, info.Probe.ServerVersion)
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	return []junitTestSuite{suite}
+}
+
+// doctorJUnit reports a suite per server with a test case per check. Warnings
+// pass with their detail in system-out; checks skipped after a failure are skipped.
+func doctorJUnit(reports []DoctorReport) []junitTestSuite {
+	suites := make([]junitTestSuite, 0, len(reports))
+	for _, report := range reports {
+		suite := junitTestSuite{Name: report.Server}
+		for _, check := range report.Checks {
+			testCase := junitTestCase{ClassName: "mcpinspect.doctor." + report.Server, Name: check.Name}
+			text := check.Detail
+			if check.Hint != "" {
+				text += "\nhint: " + check.Hint
+			}
+			switch check.Status {
+			case doctorFail:
+				testCase.Failure = &junitFailure{Message: check.Detail, Text: text}
+			case doctorSkip:
+				testCase.Skipped = &struct{}{}
+			case doctorWarn:
+				testCase.SystemOut = "warning: " + text
+			default:
+				testCase.SystemOut = text
+			}
+			suite.Cases = append(suite.Cases, testCase)
+		}
+		suites = append(suites, suite)
+	}
+	return suites
+}
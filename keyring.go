@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// loadKeyringCredentials reads Claude Code's credentials from the Secret
+// Service (GNOME Keyring, KWallet) with libsecret's secret-tool
+func loadKeyringCredentials() (*MCPCredentials, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", keychainService)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseCredentials(bytes.TrimSpace(output))
+}
+
+// saveKeyringCredentials replaces Claude Code's Secret Service item. The
+// secret goes through stdin so it never shows up in the process list.
+func saveKeyringCredentials(creds *MCPCredentials) error {
+	data, err := creds.encode()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("secret-tool", "store", "--label", keychainService, "service", keychainService, "account", credentialAccount())
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to update keyring: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
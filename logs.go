@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// logLevels are the MCP (syslog) log levels from least to most severe
+var logLevels = []string{"debug", "info", "notice", "warning", "error", "critical", "alert", "emergency"}
+
+// logLevelColors are the ANSI colors used for each level on a terminal
+var logLevelColors = map[string]string{
+	"debug":     "\033[90m",
+	"info":      "\033[36m",
+	"notice":    "\033[34m",
+	"warning":   "\033[33m",
+	"error":     "\033[31m",
+	"critical":  "\033[1;31m",
+	"alert":     "\033[1;31m",
+	"emergency": "\033[1;41m",
+}
+
+// LogEntry is one notifications/message log entry sent by a server
+type LogEntry struct {
+	Time   time.Time       `json:"time"`
+	Level  string          `json:"level"`
+	Logger string          `json:"logger,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+func newLogsCmd() *cobra.Command {
+	var level string
+	logsCmd := &cobra.Command{
+		Use:   "logs <server-name>",
+		Short: "Set a server's log level and stream its log messages",
+		Long: `Send logging/setLevel to a server and print every notifications/message log
+entry it sends afterwards, colored by level when writing to a terminal.
+With --output json each entry is written as a JSON object.
+
+Levels, from least to most severe: ` + strings.Join(logLevels, ", ") + `.
+
+Press Ctrl+C to stop.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !isLogLevel(level) {
+				return fmt.Errorf("invalid --level %q, expected one of: %s", level, strings.Join(logLevels, ", "))
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// A dropped connection is a result, not a usage error
+			cmd.SilenceUsage = true
+			return streamLogs(config, args[0], level)
+		},
+	}
+	logsCmd.Flags().StringVar(&level, "level", "debug", "minimum level the server should send")
+	return logsCmd
+}
+
+func isLogLevel(level string) bool {
+	for _, l := range logLevels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func streamLogs(config *ClaudeConfig, serverName, level string) error {
+	// The connection lives until interrupted, so only individual requests get a timeout
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, ok := conn.Capabilities()["logging"]; !ok {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not advertise the logging capability\n", serverName)
+	}
+
+	// Listen before setting the level so entries sent right away are not missed
+	entries := make(chan LogEntry, 256)
+	conn.Session.OnNotification("notifications/message", func(params json.RawMessage) {
+		var entry LogEntry
+		json.Unmarshal(params, &entry)
+		entry.Time = time.Now()
+		select {
+		case entries <- entry:
+		default:
+		}
+	})
+
+	_, err = conn.Session.Request(ctx, "logging/setLevel", map[string]string{"level": level})
+	if err != nil {
+		return fmt.Errorf("failed to set log level: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Streaming %s logs from %s. Press Ctrl+C to stop.\n", level, serverName)
+
+	color := outputFormat != outputJSON && colorEnabled
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Session.Done():
+			return fmt.Errorf("connection to %s closed", serverName)
+		case entry := <-entries:
+			if outputFormat == outputJSON {
+				if err := writeJSON(entry); err != nil {
+					return err
+				}
+				continue
+			}
+			fmt.Println(formatLogEntry(entry, color))
+		}
+	}
+}
+
+// formatLogEntry renders a log entry as "[time] LEVEL logger: data"
+func formatLogEntry(entry LogEntry, color bool) string {
+	level := fmt.Sprintf("%-9s", strings.ToUpper(entry.Level))
+	if code, ok := logLevelColors[entry.Level]; ok && color {
+		level = code + level + "\033[0m"
+	}
+
+	// Show string data as is and anything else as JSON
+	var text string
+	if err := json.Unmarshal(entry.Data, &text); err != nil {
+		text = compactJSON(entry.Data)
+	}
+	if entry.Logger != "" {
+		text = entry.Logger + ": " + text
+	}
+	return fmt.Sprintf("[%s] %s %s", entry.Time.Format("15:04:05.000"), level, text)
+}
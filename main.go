@@ -2,78 +2,311 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
 
 	mcp "github.com/metoro-io/mcp-golang"
-	"github.com/metoro-io/mcp-golang/transport/stdio"
+	"github.com/metoro-io/mcp-golang/transport"
 	"github.com/spf13/cobra"
 )
 
 var configPath string
 
+// protocolVersion is offered during initialize when set; otherwise the client library's default is sent
+var protocolVersion string
+
+// maxPages bounds how many pages of a paginated list are fetched
+var maxPages int
+
+// defaultTimeout is the default of --timeout
+const defaultTimeout = 30 * time.Second
+
+// commandTimeout is the --timeout every phase of talking to a server
+// defaults to
+var commandTimeout time.Duration
+
+// connectTimeout bounds opening a remote server's transport, initTimeout the
+// initialize handshake (including a stdio server's start-up) and
+// requestTimeout each request after it
+var connectTimeout, initTimeout, requestTimeout time.Duration
+
 func main() {
+	var probe, schemas, allClients, listPaths bool
+	var filterGlob, filterRegex, serverURL, transportType string
 	rootCmd := &cobra.Command{
-		Use:   "mcpinspect [server-name]",
+		Use:   "mcpinspect [server-name | --url <url>] [tool]",
 		Short: "Inspect MCP servers configured in Claude",
 		Long: `mcpinspect is a tool to inspect MCP servers configured for Claude Code.
 It reads the Claude configuration file and displays information about configured MCP servers.
 
 Without arguments, it lists all MCP servers across all projects.
-With a server name argument, it shows detailed information about that specific server.`,
-		Args: cobra.MaximumNArgs(1),
+With a server name argument, it shows detailed information about that specific server.
+With a server and a tool name, it shows the tool's full description, input and output
+schemas and annotations.
+
+With --url, the server at that address is inspected without any config file;
+a single argument is then a tool name.
+
+Exit codes: 1 for any other failure, 2 for invalid flags or config, 3 when a
+server cannot be reached, 4 when it rejects the credentials, 5 for drift found
+by check or diff --fail-on drift, 6 for tool name collisions and 7 for high
+severity findings of audit or registry verify.`,
+		Args: cobra.MaximumNArgs(2),
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			setupLogger()
+			if err := resolveColor(); err != nil {
+				return err
+			}
+			if err := validateClient(); err != nil {
+				return err
+			}
+			if clientName != clientClaudeCode && !cmd.Flags().Changed("config") {
+				path, err := clientConfigPath(clientName)
+				if err != nil {
+					return err
+				}
+				configPath = path
+			}
+			if err := validateConcurrency(); err != nil {
+				return err
+			}
+			if maxPages < 1 {
+				return fmt.Errorf("--max-pages must be at least 1")
+			}
+			if err := resolveTimeouts(cmd); err != nil {
+				return err
+			}
+			if err := validateRetries(); err != nil {
+				return err
+			}
+			if err := validateFailOn(); err != nil {
+				return err
+			}
+			if maxEventSize < 1 {
+				return fmt.Errorf("--max-event-size must be at least 1")
+			}
+			if err := validateSampling(); err != nil {
+				return err
+			}
+			if err := validateElicitation(); err != nil {
+				return err
+			}
+			roots, err := parseRoots(rootPaths)
+			if err != nil {
+				return err
+			}
+			clientRoots = roots
+			if extraHeaders, err = parseHeaderFlags(headerFlags); err != nil {
+				return err
+			}
+			if bearerToken != "" && basicAuthFlag != "" {
+				return fmt.Errorf("--bearer-token and --basic-auth cannot be used together")
+			}
+			if basicAuth, err = parseBasicAuth(basicAuthFlag); err != nil {
+				return err
+			}
+			if tlsKeyFlag != "" && tlsCertFlag == "" {
+				return fmt.Errorf("--tls-key requires --tls-cert")
+			}
+			if proxyFlag != "" && socks5Flag != "" {
+				return fmt.Errorf("--proxy and --socks5 cannot be used together")
+			}
+			if proxyFlag != "" {
+				if _, err := parseProxyURL(proxyFlag); err != nil {
+					return err
+				}
+			}
+			if socks5Flag != "" {
+				if err := parseSocks5(socks5Flag); err != nil {
+					return err
+				}
+			}
+			if err := validateOutputFormat(); err != nil {
+				return err
+			}
+			return validateReportOutput(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			config, err := loadConfig(configPath)
+			if listPaths {
+				if len(args) != 0 {
+					return fmt.Errorf("--list-config-paths takes no arguments")
+				}
+				return printConfigPaths()
+			}
+			filter, err := NewToolFilter(filterGlob, filterRegex)
 			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
+				return err
+			}
+
+			var config *ClaudeConfig
+			if serverURL != "" {
+				if len(args) > 1 {
+					return fmt.Errorf("--url takes at most a tool name argument")
+				}
+				adhoc, name, err := adhocURLConfig(serverURL, transportType)
+				if err != nil {
+					return err
+				}
+				// The ad-hoc server takes the place of the server-name argument
+				config = adhoc
+				args = append([]string{name}, args...)
+			} else if cmd.Flags().Changed("transport") {
+				return fmt.Errorf("--transport only applies with --url")
+			}
+
+			if filter.Active() && len(args) != 1 {
+				return fmt.Errorf("--filter only applies when inspecting a server")
+			}
+			if allClients {
+				if len(args) != 0 || schemas {
+					return fmt.Errorf("--all-clients only applies when listing servers")
+				}
+				// Unreachable servers are a result, not a usage error
+				cmd.SilenceUsage = true
+				return listAllClients(probe)
+			}
+
+			if config == nil {
+				config, err = loadConfig(configPath)
+				if err != nil {
+					return fmt.Errorf("failed to load config: %w", err)
+				}
 			}
 
 			if len(args) == 0 {
-				return listServers(config)
+				if schemas {
+					return fmt.Errorf("--schemas only applies when inspecting a server")
+				}
+				cmd.SilenceUsage = true
+				return listServers(config, probe)
 			}
-			return inspectServer(config, args[0])
+
+			if probe {
+				return fmt.Errorf("--probe only applies when listing servers")
+			}
+			if len(args) == 2 {
+				return inspectTool(config, args[0], args[1])
+			}
+			return inspectServer(config, args[0], filter, schemas)
 		},
 	}
 
 	// Get default config path
-	homeDir, err := os.UserHomeDir()
+	defaultConfig, err := clientConfigPath(clientClaudeCode)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error getting home directory: %v\n", err)
 		os.Exit(1)
 	}
-	defaultConfig := filepath.Join(homeDir, ".claude.json")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", defaultConfig, "path to the client's config file")
+	rootCmd.PersistentFlags().StringVar(&clientName, "client", clientClaudeCode, "MCP client whose config to read: "+strings.Join(clientNames, ", "))
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputTable, "output format: table, json or csv, or a CI report: junit or github (workflow annotations)")
+	rootCmd.PersistentFlags().StringVar(&formatTemplate, "format", "", "Go template applied to the JSON result instead of printing it, e.g. '{{.Name}}\\t{{len .Tools}}'")
+	rootCmd.PersistentFlags().BoolVar(&noPin, "no-pin", false, "do not pin the tools of inspected servers or warn when they changed since")
+	rootCmd.PersistentFlags().BoolVar(&noProvenance, "no-provenance", false, "do not query npm or PyPI for the package an npx, uvx or pipx server runs")
+	rootCmd.PersistentFlags().StringSliceVar(&failOn, "fail-on", nil, "only these conditions fail the command, replacing its defaults: unreachable, drift, collisions, findings (comma-separated)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", colorAuto, "color statuses and diffs: auto (on a terminal unless NO_COLOR is set), always or never")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", defaultConcurrency, "number of servers to contact in parallel")
+	rootCmd.PersistentFlags().StringVar(&protocolVersion, "protocol-version", "", "MCP protocol version to request during initialize, e.g. 2025-06-18")
+	rootCmd.PersistentFlags().DurationVar(&commandTimeout, "timeout", defaultTimeout, "default of the connect, initialize and request timeouts, e.g. 2m for servers that cold-start through npx or uvx")
+	rootCmd.PersistentFlags().DurationVar(&connectTimeout, "connect-timeout", 0, "how long to wait for a remote server's transport to open (default: --timeout)")
+	rootCmd.PersistentFlags().DurationVar(&initTimeout, "init-timeout", 0, "how long to wait for the initialize handshake, including a stdio server's start-up (default: --timeout)")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 0, "how long each request may wait for its answer (default: --timeout)")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 0, "retry connecting, initialize and list requests this many times on connection resets, 502/503 responses and SSE setup failures")
+	rootCmd.PersistentFlags().DurationVar(&retryBackoff, "retry-backoff", time.Second, "delay before the first retry, doubled for each next one")
+	rootCmd.PersistentFlags().IntVar(&maxEventSize, "max-event-size", defaultMaxEventSize, "largest SSE event line to accept from HTTP and SSE servers, in MB")
+	rootCmd.PersistentFlags().IntVar(&maxPages, "max-pages", 100, "maximum number of pages to fetch when a server paginates a list")
+	rootCmd.PersistentFlags().StringVar(&samplingMode, "sampling", samplingOff, "answer sampling/createMessage requests: off, interactive or auto")
+	rootCmd.PersistentFlags().StringVar(&samplingResponse, "sampling-response", "This is a canned response from mcpinspect.", "reply sent to sampling requests with --sampling auto")
+	rootCmd.PersistentFlags().StringVar(&elicitationMode, "elicitation", elicitationOff, "answer elicitation/create requests: off, interactive or decline")
+	rootCmd.PersistentFlags().BoolVar(&sandbox, "sandbox", false, "start stdio servers with a minimal environment in a temporary directory, isolated from your credentials and, without --sandbox-net, the network (Linux namespaces, macOS sandbox-exec)")
+	rootCmd.PersistentFlags().BoolVar(&sandboxNet, "sandbox-net", false, "let sandboxed servers use the network, e.g. for npx or uvx to download them")
+	rootCmd.PersistentFlags().StringArrayVar(&rootPaths, "root", nil, "advertise the roots capability and list this directory or URI in roots/list (repeatable)")
+	rootCmd.PersistentFlags().StringArrayVarP(&headerFlags, "header", "H", nil, "send this \"Name: value\" header to every HTTP and SSE server, overriding the config (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&bearerToken, "bearer-token", "", "send this bearer token to HTTP and SSE servers instead of the stored OAuth token (default $"+tokenEnv+")")
+	rootCmd.PersistentFlags().StringVar(&basicAuthFlag, "basic-auth", "", "send these user:password HTTP Basic credentials to HTTP and SSE servers")
+	rootCmd.PersistentFlags().StringVar(&tlsCertFlag, "tls-cert", "", "present this PEM client certificate to HTTP and SSE servers that require mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&tlsKeyFlag, "tls-key", "", "PEM private key of --tls-cert (default: read from the --tls-cert file)")
+	rootCmd.PersistentFlags().StringVar(&tlsCAFlag, "tls-ca", "", "also trust the CA certificates of this PEM bundle for HTTP and SSE servers")
+	rootCmd.PersistentFlags().BoolVar(&insecureSkipVerify, "insecure-skip-verify", false, "do not verify the TLS certificates of HTTP and SSE servers (for self-signed dev servers)")
+	rootCmd.PersistentFlags().StringVar(&proxyFlag, "proxy", "", "reach HTTP and SSE servers through this http://, https:// or socks5:// proxy (default $HTTPS_PROXY/$HTTP_PROXY)")
+	rootCmd.PersistentFlags().StringVar(&socks5Flag, "socks5", "", "reach HTTP and SSE servers through the SOCKS5 proxy at this host:port, such as an ssh -D tunnel")
+	rootCmd.PersistentFlags().StringArrayVar(&envFiles, "env-file", nil, "load KEY=VALUE lines from this dotenv file into every stdio server's environment (repeatable)")
+	rootCmd.PersistentFlags().BoolVar(&traceEnabled, "trace", false, "print every JSON-RPC message sent and received to stderr")
+	rootCmd.PersistentFlags().CountVarP(&verbosity, "verbose", "v", "log connection lifecycle, session IDs, retries and timing to stderr; -vv adds HTTP requests and headers")
+	rootCmd.Flags().BoolVar(&probe, "probe", false, "connect to every server and show live status and tool counts")
+	rootCmd.Flags().BoolVar(&allClients, "all-clients", false, "list the servers of every supported MCP client found on this machine")
+	rootCmd.Flags().StringVar(&serverURL, "url", "", "inspect the MCP server at this URL instead of a configured one")
+	rootCmd.Flags().StringVar(&transportType, "transport", "http", "transport for --url: http (Streamable HTTP) or sse")
+	rootCmd.Flags().BoolVar(&listPaths, "list-config-paths", false, "show which config files every client's loader consults and which exist")
+	rootCmd.MarkFlagsMutuallyExclusive("all-clients", "url")
+	rootCmd.MarkFlagsMutuallyExclusive("all-clients", "client")
+	rootCmd.MarkFlagsMutuallyExclusive("all-clients", "config")
+	rootCmd.Flags().BoolVar(&schemas, "schemas", false, "show each tool's input schema as a parameter table")
+	rootCmd.Flags().StringVar(&filterGlob, "filter", "", "only show tools whose names match this glob, e.g. 'git_*'")
+	rootCmd.Flags().StringVar(&filterRegex, "filter-regex", "", "only show tools whose names match this regular expression")
+	rootCmd.MarkFlagsMutuallyExclusive("filter", "filter-regex")
+
+	rootCmd.AddCommand(newResourcesCmd(), newPromptsCmd(), newDocsCmd(), newPingCmd(), newDiffCmd(), newSnapshotCmd(), newCheckCmd(), newPinsCmd(), newAuditCmd(), newRiskCmd(), newWatchCmd(), newServeCmd(), newReplCmd(), newProxyCmd(), newRPCCmd(), newBenchCmd(), newStressCmd(), newFuzzCmd(), newCapabilitiesCmd(), newCollisionsCmd(), newCostCmd(), newSubscribeCmd(), newCompleteCmd(), newLogsCmd(), newCallCmd(), newInspectCmd(), newConfigCmd(), newDoctorCmd(), newAuthCmd(), newRegistryCmd(), newInstallCmd())
 
-	rootCmd.Flags().StringVarP(&configPath, "config", "c", defaultConfig, "path to Claude config file")
+	// Invalid flags exit with the same code as an invalid config
+	validateFlags := rootCmd.PersistentPreRunE
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return withExitCode(exitConfig, validateFlags(cmd, args))
+	}
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return withExitCode(exitConfig, err)
+	})
 
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
 
 // ServerInfo holds aggregated server information
 type ServerInfo struct {
-	Name     string
-	Type     string
-	URL      string
-	Command  string
-	Args     []string
-	Projects []string
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	URL      string   `json:"url,omitempty"`
+	Command  string   `json:"command,omitempty"`
+	Args     []string `json:"args,omitempty"`
+	Projects []string `json:"projects"`
+	Scopes   []string `json:"scopes,omitempty"`
+	Clients  []string `json:"clients,omitempty"`
+
+	// Disabled and AutoApprove come from clients that keep them per server, such as Cline
+	Disabled    bool     `json:"disabled,omitempty"`
+	AutoApprove []string `json:"autoApprove,omitempty"`
+
+	// Probe is set when the server was contacted with --probe
+	Probe *ProbeResult `json:"probe,omitempty"`
+
+	// config is the client config the server came from with --all-clients
+	config *ClaudeConfig
 }
 
-func listServers(config *ClaudeConfig) error {
-	// Aggregate servers across all projects
+// aggregateServers merges servers across all projects, sorted by name
+func aggregateServers(config *ClaudeConfig) []*ServerInfo {
 	servers := make(map[string]*ServerInfo)
 
 	for projectPath, project := range config.Projects {
 		for name, server := range project.MCPServers {
 			if existing, ok := servers[name]; ok {
 				existing.Projects = append(existing.Projects, projectPath)
+				if server.Scope != "" && !slices.Contains(existing.Scopes, server.Scope) {
+					existing.Scopes = append(existing.Scopes, server.Scope)
+				}
 			} else {
 				info := &ServerInfo{
 					Name:     name,
@@ -82,30 +315,132 @@ func listServers(config *ClaudeConfig) error {
 					Command:  server.Command,
 					Args:     server.Args,
 					Projects: []string{projectPath},
+
+					Disabled:    server.Disabled,
+					AutoApprove: server.AutoApprove,
+				}
+				if server.Scope != "" {
+					info.Scopes = []string{server.Scope}
 				}
 				servers[name] = info
 			}
 		}
 	}
 
+	// Sort server names and projects for consistent output
+	infos := make([]*ServerInfo, 0, len(servers))
+	for _, info := range servers {
+		sort.Strings(info.Projects)
+		sort.Strings(info.Scopes)
+		// Describe a server defined in several scopes by the definition that is used
+		if len(info.Scopes) > 1 {
+			if server, err := findServer(config, info.Name); err == nil {
+				info.Type, info.URL, info.Command, info.Args = server.Type, server.URL, server.Command, server.Args
+				info.Disabled, info.AutoApprove = server.Disabled, server.AutoApprove
+			}
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+func listServers(config *ClaudeConfig, probe bool) error {
+	servers := aggregateServers(config)
+
+	if probe {
+		probeServers(config, servers)
+	}
+	if err := printServers(servers, probe); err != nil {
+		return err
+	}
+	return probeFailure(servers)
+}
+
+// listAllClients lists the servers of every client configured on this machine
+func listAllClients(probe bool) error {
+	configs := discoverClients()
+	if len(configs) == 0 {
+		fmt.Println("No MCP client configs found.")
+		return nil
+	}
+	servers := aggregateClients(configs)
+
+	if probe {
+		probeServers(nil, servers)
+	}
+	if err := printServers(servers, probe); err != nil {
+		return err
+	}
+	return probeFailure(servers)
+}
+
+// printServers prints the server listing in the selected output format
+func printServers(servers []*ServerInfo, probe bool) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(servers)
+	case outputJUnit:
+		return writeJUnit("mcpinspect probe", probeJUnit(servers))
+	case outputGitHub:
+		return writeAnnotations(probeAnnotations(servers))
+	case outputCSV:
+		header := []string{"NAME", "TYPE", "URL", "COMMAND", "ARGS", "PROJECTS", "SCOPE", "DISABLED", "AUTO APPROVE", "CLIENTS"}
+		if probe {
+			header = append(header, "STATUS", "TOOLS", "SERVER VERSION", "ERROR")
+		}
+		rows := make([][]string, 0, len(servers))
+		for _, info := range servers {
+			row := []string{info.Name, info.Type, info.URL, info.Command, shellJoin(info.Args), strings.Join(info.Projects, "\n"), strings.Join(info.Scopes, " "), strconv.FormatBool(info.Disabled), strings.Join(info.AutoApprove, " "), strings.Join(info.Clients, " ")}
+			if info.Probe != nil {
+				row = append(row, info.Probe.Status, strconv.Itoa(info.Probe.Tools), info.Probe.ServerVersion, info.Probe.Error)
+			}
+			rows = append(rows, row)
+		}
+		return writeCSV(header, rows)
+	}
+
 	if len(servers) == 0 {
 		fmt.Println("No MCP servers configured.")
 		return nil
 	}
 
-	// Sort server names for consistent output
-	names := make([]string, 0, len(servers))
-	for name := range servers {
-		names = append(names, name)
+	// Disabled and auto-approve columns only appear for clients that have them,
+	// and the scope column only when servers come from more than one scope
+	showClientSettings := false
+	scopes := make(map[string]bool)
+	for _, info := range servers {
+		if info.Disabled || len(info.AutoApprove) > 0 {
+			showClientSettings = true
+		}
+		for _, scope := range info.Scopes {
+			scopes[scope] = true
+		}
 	}
-	sort.Strings(names)
+	showScope := len(scopes) > 1
+	showClients := len(servers) > 0 && len(servers[0].Clients) > 0
 
 	// Print table
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tTYPE\tURL\tCOMMAND\tARGS")
+	header := []string{"NAME", "TYPE", "URL", "COMMAND", "ARGS"}
+	if probe {
+		header = []string{"NAME", "TYPE", paint("STATUS", ansiDefault), "TOOLS", "SERVER VERSION", "URL", "COMMAND", "ARGS"}
+	}
+	if showClients {
+		header = slices.Insert(header, 1, "CLIENT")
+	}
+	if showScope {
+		header = append(header, "SCOPE")
+	}
+	if showClientSettings {
+		header = append(header, "DISABLED", "AUTO-APPROVE")
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
 
-	for _, name := range names {
-		info := servers[name]
+	for _, info := range servers {
 		url := info.URL
 		if url == "" {
 			url = "[N/A]"
@@ -118,156 +453,739 @@ func listServers(config *ClaudeConfig) error {
 		if len(info.Args) > 0 {
 			args = strings.Join(info.Args, " ")
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", info.Name, info.Type, url, command, args)
+		row := []string{info.Name, info.Type, url, command, args}
+		if info.Probe != nil {
+			tools, version := "[N/A]", "[N/A]"
+			if info.Probe.Status == probeOK {
+				tools = strconv.Itoa(info.Probe.Tools)
+				version = info.Probe.ServerVersion
+			}
+			row = []string{info.Name, info.Type, paintStatus(info.Probe.Status, info.Probe.Status), tools, version, url, command, args}
+		}
+		if showClients {
+			row = slices.Insert(row, 1, strings.Join(info.Clients, ", "))
+		}
+		if showScope {
+			row = append(row, strings.Join(info.Scopes, ", "))
+		}
+		if showClientSettings {
+			disabled := "no"
+			if info.Disabled {
+				disabled = "yes"
+			}
+			autoApprove := "[N/A]"
+			if len(info.AutoApprove) > 0 {
+				autoApprove = strings.Join(info.AutoApprove, ", ")
+			}
+			row = append(row, disabled, autoApprove)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
 	}
 
 	w.Flush()
+
+	if probe {
+		printProbeFailures(servers)
+	}
 	return nil
 }
 
-func inspectServer(config *ClaudeConfig, serverName string) error {
-	var foundServer *MCPServer
+func inspectServer(config *ClaudeConfig, serverName string, filter *ToolFilter, schemas bool) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	result, err := collectInspection(ctx, config, conn)
+	if err != nil {
+		return err
+	}
+	verifyPin(conn, result.Tools)
+	result.Tools = filter.Apply(result.Tools)
+	result.Provenance = lookupProvenance(ctx, *conn.Server)
+	if schemas {
+		return printInspectionSchemas(conn, result)
+	}
+	return printInspection(conn, result)
+}
 
-	for _, project := range config.Projects {
-		if server, ok := project.MCPServers[serverName]; ok {
-			foundServer = &server
-			break
+// printInspection renders a server's tools in the selected output format
+func printInspection(conn *Connection, result *InspectResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(result)
+	case outputCSV:
+		rows := make([][]string, 0, len(result.Tools))
+		for _, tool := range result.Tools {
+			outputSchema := ""
+			if tool.OutputSchema != nil {
+				outputSchema = compactJSON(tool.OutputSchema)
+			}
+			rows = append(rows, []string{result.Name, tool.Name, strings.Join(toolHints(tool.Annotations), ", "), tool.Description, outputSchema})
 		}
+		return writeCSV([]string{"SERVER", "TOOL", "HINTS", "DESCRIPTION", "OUTPUT SCHEMA"}, rows)
 	}
 
-	if foundServer == nil {
-		return fmt.Errorf("server '%s' not found", serverName)
+	// Only show the hints and output columns when some tool uses them
+	showHints, showOutput := false, false
+	for _, tool := range result.Tools {
+		if len(toolHints(tool.Annotations)) > 0 {
+			showHints = true
+		}
+		if tool.OutputSchema != nil {
+			showOutput = true
+		}
 	}
 
-	// Connect to the server and get capabilities
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// Print tools table
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := []string{"NAME"}
+	if showHints {
+		header = append(header, "HINTS")
+	}
+	if showOutput {
+		header = append(header, "OUTPUT")
+	}
+	fmt.Fprintln(w, strings.Join(append(header, "DESCRIPTION"), "\t"))
 
-	client, cleanup, err := connectToServer(ctx, foundServer, serverName)
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
+	for _, tool := range result.Tools {
+		row := []string{tool.Name}
+		if showHints {
+			hints := "[N/A]"
+			if list := toolHints(tool.Annotations); len(list) > 0 {
+				hints = strings.Join(list, ", ")
+			}
+			row = append(row, hints)
+		}
+		if showOutput {
+			output := "text"
+			if tool.OutputSchema != nil {
+				output = "structured"
+			}
+			row = append(row, output)
+		}
+		fmt.Fprintln(w, strings.Join(append(row, tool.Description), "\t"))
 	}
-	if cleanup != nil {
-		defer cleanup()
+
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	printServerMetadata(result)
+	fmt.Printf("%d tools | %s | %s\n", len(result.Tools), conn.Server.Type, formatServerInfo(conn.Init))
+
+	return nil
+}
+
+// printInspectionSchemas renders every tool with a nested table of its input schema parameters
+func printInspectionSchemas(conn *Connection, result *InspectResult) error {
+	switch outputFormat {
+	case outputJSON:
+		// The JSON result already carries each tool's full input schema
+		return writeJSON(result)
+	case outputCSV:
+		rows := [][]string{}
+		for _, tool := range result.Tools {
+			for _, param := range schemaParams(tool.InputSchema) {
+				rows = append(rows, []string{result.Name, tool.Name, param.Name, param.Type, strconv.FormatBool(param.Required),
+					formatSchemaValue(param.Default), formatEnum(param.Enum), param.Description})
+			}
+		}
+		return writeCSV([]string{"SERVER", "TOOL", "PARAMETER", "TYPE", "REQUIRED", "DEFAULT", "ENUM", "DESCRIPTION"}, rows)
+	}
+
+	for i, tool := range result.Tools {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(tool.Name)
+		if tool.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(tool.Description), "\n") {
+				fmt.Printf("  %s\n", line)
+			}
+		}
+		fmt.Println()
+
+		params := schemaParams(tool.InputSchema)
+		if len(params) == 0 {
+			fmt.Println("  No parameters")
+		} else {
+			printSchemaTable(params)
+		}
+
+		if tool.OutputSchema != nil {
+			fmt.Println()
+			fmt.Println("  Structured output:")
+			if fields := schemaParams(tool.OutputSchema); len(fields) > 0 {
+				printSchemaTable(fields)
+			} else {
+				fmt.Println(indentBlock(indentBlock(indentJSON(tool.OutputSchema))))
+			}
+		}
 	}
 
-	// Initialize
-	initResp, err := client.Initialize(ctx)
+	// Print summary
+	fmt.Println()
+	printServerMetadata(result)
+	fmt.Printf("%d tools | %s | %s\n", len(result.Tools), conn.Server.Type, formatServerInfo(conn.Init))
+
+	return nil
+}
+
+// printServerMetadata prints what the server advertised during initialize,
+// and the provenance of the package it runs
+func printServerMetadata(result *InspectResult) {
+	if instructions := strings.TrimSpace(result.Instructions); instructions != "" {
+		fmt.Println("Instructions:")
+		fmt.Println(indentBlock(instructions))
+		fmt.Println()
+	}
+
+	var initialize struct {
+		ServerInfo json.RawMessage `json:"serverInfo"`
+	}
+	if json.Unmarshal(result.Initialize, &initialize) == nil && initialize.ServerInfo != nil {
+		fmt.Printf("Server info: %s\n", compactJSON(initialize.ServerInfo))
+	}
+	fmt.Printf("Protocol version: %s\n", formatProtocolVersion(result.ServerHeader))
+	fmt.Printf("Capabilities: %s\n", formatCapabilities(result.Capabilities))
+	printProvenance(result.Provenance)
+}
+
+// printSchemaTable prints schema parameters indented by nesting depth, with
+// nested properties shown by their own name under their parent
+func printSchemaTable(params []SchemaParam) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  PARAMETER\tTYPE\tREQUIRED\tDEFAULT\tENUM\tDESCRIPTION")
+	for _, param := range params {
+		name := param.Name[strings.LastIndex(param.Name, ".")+1:]
+		required := "optional"
+		if param.Required {
+			required = "required"
+		}
+		def, enum := "[N/A]", "[N/A]"
+		if param.Default != nil {
+			def = formatSchemaValue(param.Default)
+		}
+		if len(param.Enum) > 0 {
+			enum = formatEnum(param.Enum)
+		}
+		description := strings.ReplaceAll(param.Description, "\n", " ")
+		fmt.Fprintf(w, "  %s%s\t%s\t%s\t%s\t%s\t%s\n", strings.Repeat("  ", param.Depth), name, param.Type, required, def, enum, description)
+	}
+	w.Flush()
+}
+
+// formatSchemaValue renders a default or enum value as compact JSON, or "" when unset
+func formatSchemaValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	return compactJSON(v)
+}
+
+// formatEnum renders enum values separated by "|"
+func formatEnum(enum []interface{}) string {
+	values := make([]string, 0, len(enum))
+	for _, v := range enum {
+		values = append(values, formatSchemaValue(v))
+	}
+	return strings.Join(values, "|")
+}
+
+// collectInspection lists a connected server's tools into an InspectResult
+func collectInspection(ctx context.Context, config *ClaudeConfig, conn *Connection) (*InspectResult, error) {
+	tools, err := listTools(ctx, conn)
 	if err != nil {
-		return fmt.Errorf("failed to initialize: %w", err)
+		return nil, err
 	}
 
-	// List tools
-	tools, err := client.ListTools(ctx, nil)
+	return &InspectResult{
+		ServerHeader: newServerHeader(conn),
+		Projects:     serverProjects(config, conn.Name),
+		Tools:        tools,
+		Capabilities: conn.Capabilities(),
+		Instructions: conn.Instructions(),
+		Initialize:   conn.Session.InitializeResult(),
+	}, nil
+}
+
+// listTools requests tools/list directly rather than through the client,
+// which drops fields such as annotations and outputSchema
+func listTools(ctx context.Context, conn *Connection) ([]ToolInfo, error) {
+	tools := []ToolInfo{}
+	err := paginate(ctx, conn, "tools/list", func(raw json.RawMessage) (string, error) {
+		var result struct {
+			Tools      []ToolInfo `json:"tools"`
+			NextCursor string     `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return "", fmt.Errorf("failed to decode tools: %w", err)
+		}
+		tools = append(tools, result.Tools...)
+		return result.NextCursor, nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to list tools: %w", err)
+		return nil, err
 	}
 
 	// Sort tools alphabetically
-	sort.Slice(tools.Tools, func(i, j int) bool {
-		return tools.Tools[i].Name < tools.Tools[j].Name
+	sort.Slice(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
 	})
 
-	// Print tools table
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	fmt.Fprintln(w, "NAME\tDESCRIPTION")
+	return tools, nil
+}
 
-	for _, tool := range tools.Tools {
-		desc := ""
-		if tool.Description != nil {
-			desc = *tool.Description
+// paginate sends a list request and keeps requesting the nextCursor that
+// handlePage returns, for up to maxPages pages
+func paginate(ctx context.Context, conn *Connection, method string, handlePage func(result json.RawMessage) (string, error)) error {
+	cursor := ""
+	for page := 1; ; page++ {
+		var params interface{}
+		if cursor != "" {
+			params = map[string]string{"cursor": cursor}
 		}
-		fmt.Fprintf(w, "%s\t%s\n", tool.Name, desc)
+		raw, err := conn.Session.Request(ctx, method, params)
+		if err != nil {
+			return fmt.Errorf("failed to send %s: %w", method, err)
+		}
+
+		next, err := handlePage(raw)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		if next == cursor {
+			return fmt.Errorf("failed to send %s: server returned the same cursor %q twice", method, cursor)
+		}
+		if page >= maxPages {
+			fmt.Fprintf(os.Stderr, "Warning: %s has more results for %s after %d pages; raise --max-pages to fetch them all\n", conn.Name, method, maxPages)
+			return nil
+		}
+		cursor = next
 	}
+}
 
-	w.Flush()
+// formatProtocolVersion renders the negotiated protocol version, noting when
+// the server answered with a different version than was requested
+func formatProtocolVersion(header ServerHeader) string {
+	if header.RequestedProtocolVersion == "" || header.RequestedProtocolVersion == header.ProtocolVersion {
+		return header.ProtocolVersion
+	}
+	return fmt.Sprintf("%s (requested %s)", header.ProtocolVersion, header.RequestedProtocolVersion)
+}
 
-	// Print summary
-	fmt.Println()
+// formatServerInfo renders the server name and version from an initialize response
+func formatServerInfo(initResp *mcp.InitializeResponse) string {
 	serverInfo := initResp.ServerInfo.Name
 	if initResp.ServerInfo.Version != "" {
 		serverInfo += " v" + initResp.ServerInfo.Version
 	}
-	fmt.Printf("%d tools | %s | %s\n", len(tools.Tools), foundServer.Type, serverInfo)
+	return serverInfo
+}
+
+// findServer returns the first server configured under the given name in any project
+func findServer(config *ClaudeConfig, serverName string) (*MCPServer, error) {
+	projectPaths := make([]string, 0, len(config.Projects))
+	for projectPath := range config.Projects {
+		projectPaths = append(projectPaths, projectPath)
+	}
+	sort.Strings(projectPaths)
+	cwd, _ := os.Getwd()
 
+	// A name defined in several scopes resolves to the most specific definition,
+	// and among projects to the one containing the working directory
+	var found *MCPServer
+	for _, projectPath := range projectPaths {
+		server, ok := config.Projects[projectPath].MCPServers[serverName]
+		if !ok {
+			continue
+		}
+		if projectPath != userScope && projectPath != adhocProject {
+			server.project = projectPath
+		}
+		switch {
+		case found == nil, scopePrecedence[server.Scope] > scopePrecedence[found.Scope]:
+			found = &server
+		case scopePrecedence[server.Scope] == scopePrecedence[found.Scope] && isWithin(cwd, server.project):
+			found = &server
+		}
+	}
+	if found == nil {
+		return nil, withExitCode(exitConfig, fmt.Errorf("server '%s' not found", serverName))
+	}
+	return found, nil
+}
+
+// serverProjects returns the sorted project paths that configure the given server
+func serverProjects(config *ClaudeConfig, serverName string) []string {
+	projects := []string{}
+	for projectPath, project := range config.Projects {
+		if _, ok := project.MCPServers[serverName]; ok {
+			projects = append(projects, projectPath)
+		}
+	}
+	sort.Strings(projects)
+	return projects
+}
+
+// Connection is an initialized client session with a configured server
+type Connection struct {
+	Name    string
+	Server  *MCPServer
+	Client  *mcp.Client
+	Session *SessionTransport
+	Init    *mcp.InitializeResponse
+	cleanup func()
+}
+
+// Close releases the underlying transport and any child process
+func (c *Connection) Close() {
+	if c.cleanup != nil {
+		c.cleanup()
+	}
+}
+
+// Capabilities returns the server's capabilities exactly as advertised during initialize
+func (c *Connection) Capabilities() map[string]interface{} {
+	var result struct {
+		Capabilities map[string]interface{} `json:"capabilities"`
+	}
+	if err := json.Unmarshal(c.Session.InitializeResult(), &result); err != nil || result.Capabilities == nil {
+		return map[string]interface{}{}
+	}
+	return result.Capabilities
+}
+
+// Instructions returns the usage instructions the server gave during initialize, if any
+func (c *Connection) Instructions() string {
+	var result struct {
+		Instructions string `json:"instructions"`
+	}
+	json.Unmarshal(c.Session.InitializeResult(), &result)
+	return result.Instructions
+}
+
+// resolveTimeouts checks the timeout flags and defaults every phase left
+// unset to --timeout
+func resolveTimeouts(cmd *cobra.Command) error {
+	if commandTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	phases := []struct {
+		flag    string
+		timeout *time.Duration
+	}{
+		{"connect-timeout", &connectTimeout},
+		{"init-timeout", &initTimeout},
+		{"request-timeout", &requestTimeout},
+	}
+	for _, phase := range phases {
+		if !cmd.Flags().Changed(phase.flag) {
+			*phase.timeout = commandTimeout
+		} else if *phase.timeout <= 0 {
+			return fmt.Errorf("--%s must be positive", phase.flag)
+		}
+	}
 	return nil
 }
 
-func connectToServer(ctx context.Context, server *MCPServer, serverName string) (*mcp.Client, func(), error) {
+// openConnection looks up a server by name, connects to it and performs the
+// initialize handshake, starting over on transient failures with --retries
+func openConnection(ctx context.Context, config *ClaudeConfig, serverName string) (*Connection, error) {
+	server, err := findServer(config, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn *Connection
+	err = withRetries(ctx, "connecting to "+serverName, func() error {
+		var err error
+		conn, err = dialServer(ctx, server, serverName)
+		return err
+	})
+	return conn, withExitCode(exitConnection, err)
+}
+
+// dialServer connects to a server and performs the initialize handshake once
+func dialServer(ctx context.Context, server *MCPServer, serverName string) (*Connection, error) {
+	log := serverLogger(serverName)
+	start := time.Now()
+	tr, cleanup, err := connectToServer(ctx, server, serverName)
+	if err != nil {
+		log.Info("connecting failed", "elapsed", time.Since(start), "error", err)
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	log.Info("transport connected", "type", server.Type, "elapsed", time.Since(start))
+
+	session := NewSessionTransport(tr)
+	session.SetLogger(log)
+	session.SetProtocolVersion(protocolVersion)
+	session.SetRequestTimeout(requestTimeout)
+	if samplingMode != samplingOff {
+		enableSampling(session, serverName)
+	}
+	if elicitationMode != elicitationOff {
+		enableElicitation(session, serverName)
+	}
+	if len(clientRoots) > 0 {
+		enableRoots(session, clientRoots)
+	}
+	client := mcp.NewClient(session)
+
+	initCtx, cancel := context.WithTimeout(ctx, initTimeout)
+	defer cancel()
+	start = time.Now()
+	initResp, err := client.Initialize(initCtx)
+	if err != nil {
+		log.Info("initialize failed", "elapsed", time.Since(start), "error", err)
+		if cleanup != nil {
+			cleanup()
+		}
+		if errors.Is(initCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, fmt.Errorf("failed to initialize: no answer within %s, raise --init-timeout for slow-starting servers", initTimeout)
+		}
+		return nil, fmt.Errorf("failed to initialize: %w", err)
+	}
+	log.Info("initialized", "protocol", initResp.ProtocolVersion, "server_info", formatServerInfo(initResp), "elapsed", time.Since(start))
+	// mcp-golang does not tell the server initialization is done; Streamable
+	// HTTP servers only accept the GET stream after this
+	if err := session.Notify(initCtx, "notifications/initialized", nil); err != nil {
+		log.Info("initialized notification failed", "error", err)
+	}
+
+	return &Connection{
+		Name:    serverName,
+		Server:  server,
+		Client:  client,
+		Session: session,
+		Init:    initResp,
+		cleanup: cleanup,
+	}, nil
+}
+
+func connectToServer(ctx context.Context, server *MCPServer, serverName string) (transport.Transport, func(), error) {
+	server, err := resolveInputs(server)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The connect timeout bounds opening a remote transport, not its lifetime;
+	// a stdio server runs as long as ctx
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	var tr transport.Transport
+	var cleanup func()
 	switch server.Type {
 	case "stdio":
-		return connectStdio(ctx, server)
+		tr, cleanup, err = connectStdio(ctx, server, serverLogger(serverName))
 	case "http":
-		return connectHTTP(ctx, server, serverName)
+		tr, cleanup, err = connectHTTP(connectCtx, server, serverName)
 	case "sse":
-		return connectSSE(ctx, server, serverName)
+		tr, cleanup, err = connectSSE(connectCtx, server, serverName)
 	default:
 		return nil, nil, fmt.Errorf("unsupported server type: %s", server.Type)
 	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return traceTransport(tr, serverName), cleanup, nil
 }
 
-func connectStdio(ctx context.Context, server *MCPServer) (*mcp.Client, func(), error) {
-	cmd := exec.CommandContext(ctx, server.Command, server.Args...)
+func connectStdio(ctx context.Context, server *MCPServer, log *slog.Logger) (transport.Transport, func(), error) {
+	env, err := serverEnv(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	dir, err := serverDir(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	command, err := resolveCommand(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	cmd := exec.CommandContext(ctx, command, server.Args...)
+	cmd.Env = env
+	cmd.Dir = dir
+	removeSandbox := func() {}
+	if sandbox {
+		if removeSandbox, err = sandboxCommand(cmd); err != nil {
+			return nil, nil, err
+		}
+	}
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
+		removeSandbox()
 		return nil, nil, fmt.Errorf("failed to get stdin pipe: %w", err)
 	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
+		removeSandbox()
 		return nil, nil, fmt.Errorf("failed to get stdout pipe: %w", err)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return nil, nil, fmt.Errorf("failed to start command: %w", err)
+		removeSandbox()
+		return nil, nil, sandboxStartError(fmt.Errorf("failed to start command: %w", err))
 	}
+	log.Info("server started", "command", command, "args", server.Args, "dir", cmd.Dir, "pid", cmd.Process.Pid, "sandbox", sandbox)
 
-	innerTransport := stdio.NewStdioServerTransportWithIO(stdout, stdin)
-	transport := NewCleaningStdioTransport(innerTransport)
-	client := mcp.NewClient(transport)
+	innerTransport := NewStdioClientTransport(stdout, stdin)
+	cleaningTransport := NewCleaningStdioTransport(innerTransport)
 
 	cleanup := func() {
 		stdin.Close()
 		cmd.Process.Kill()
 		cmd.Wait()
+		removeSandbox()
+		log.Info("server stopped", "pid", cmd.Process.Pid)
 	}
 
-	return client, cleanup, nil
+	return cleaningTransport, cleanup, nil
 }
 
-func connectHTTP(ctx context.Context, server *MCPServer, serverName string) (*mcp.Client, func(), error) {
-	transport := NewSSEClientTransport(server.URL)
+// serverDir is the working directory of a stdio server: its cwd, relative to
+// the owning project, or else the project itself when it still exists.
+// Servers of no project run in our own working directory.
+func serverDir(server *MCPServer) (string, error) {
+	if server.Cwd == "" {
+		if info, err := os.Stat(server.project); err == nil && info.IsDir() {
+			return server.project, nil
+		}
+		return "", nil
+	}
 
-	// Try to get OAuth token from keychain
-	token, err := getMCPOAuthToken(serverName, server.URL)
-	if err == nil && token != "" {
-		transport.WithHeader("Authorization", "Bearer "+token)
+	dir := server.Cwd
+	if !filepath.IsAbs(dir) && server.project != "" {
+		dir = filepath.Join(server.project, dir)
 	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("working directory %s of the server does not exist", dir)
+	}
+	return dir, nil
+}
+
+// resolveCommand returns the executable a stdio server runs: ~ expands to the
+// home directory, a relative path is resolved against the owning project
+// and a bare name is looked up on PATH
+func resolveCommand(server *MCPServer) (string, error) {
+	command := server.Command
+	if command == "~" || strings.HasPrefix(command, "~/") || strings.HasPrefix(command, "~"+string(filepath.Separator)) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		command = filepath.Join(home, command[1:])
+	}
+
+	if !strings.ContainsRune(command, '/') && !strings.ContainsRune(command, filepath.Separator) {
+		path, err := exec.LookPath(command)
+		if err != nil {
+			return "", fmt.Errorf("command %q not found on PATH (%s)", command, os.Getenv("PATH"))
+		}
+		return path, nil
+	}
+
+	if !filepath.IsAbs(command) {
+		base := server.project
+		if base == "" {
+			base, _ = os.Getwd()
+		}
+		command = filepath.Join(base, command)
+	}
+	info, err := os.Stat(command)
+	if err != nil {
+		return "", fmt.Errorf("command %q not found: %s does not exist", server.Command, command)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("command %q is a directory: %s", server.Command, command)
+	}
+	if runtime.GOOS != "windows" && info.Mode()&0111 == 0 {
+		return "", fmt.Errorf("command %q is not executable: %s", server.Command, command)
+	}
+	return command, nil
+}
 
-	client := mcp.NewClient(transport)
-	return client, nil, nil
+// isWithin reports whether path is dir or below it
+func isWithin(path, dir string) bool {
+	if dir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
 
-func connectSSE(ctx context.Context, server *MCPServer, serverName string) (*mcp.Client, func(), error) {
-	transport := NewTraditionalSSETransport(server.URL)
+// serverHeaders returns the headers sent to a remote server: its explicit
+// headers plus the stored OAuth token, unless they set Authorization
+func serverHeaders(ctx context.Context, server *MCPServer, serverName string) map[string]string {
+	headers := explicitHeaders(server)
+	if _, ok := headers["Authorization"]; ok {
+		return headers
+	}
 
-	// Try to get OAuth token from keychain
-	token, err := getMCPOAuthToken(serverName, server.URL)
+	// Try to get the OAuth token of auth login or the keychain, refreshed when it has expired
+	token, err := getMCPOAuthToken(ctx, server, serverName)
 	if err == nil && token != "" {
-		transport.WithHeader("Authorization", "Bearer "+token)
+		headers["Authorization"] = "Bearer " + token
 	}
+	return headers
+}
 
-	// Start the SSE connection (GET /sse and wait for endpoint)
-	if err := transport.Start(ctx); err != nil {
-		return nil, nil, fmt.Errorf("failed to start SSE transport: %w", err)
+func connectHTTP(ctx context.Context, server *MCPServer, serverName string) (transport.Transport, func(), error) {
+	client, err := httpClient(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpTransport := NewSSEClientTransport(server.URL).WithClient(client).WithLogger(serverLogger(serverName))
+	for key, value := range serverHeaders(ctx, server, serverName) {
+		httpTransport.WithHeader(key, value)
+	}
+	if !hasAuthHeader(server) {
+		httpTransport.WithAuthRefresh(authRefresh(server, serverName))
+	}
+
+	cleanup := func() {
+		httpTransport.Close()
+	}
+
+	return httpTransport, cleanup, nil
+}
+
+func connectSSE(ctx context.Context, server *MCPServer, serverName string) (transport.Transport, func(), error) {
+	client, err := httpClient(server)
+	if err != nil {
+		return nil, nil, err
+	}
+	sseTransport := NewTraditionalSSETransport(server.URL).WithClient(client).WithLogger(serverLogger(serverName))
+	for key, value := range serverHeaders(ctx, server, serverName) {
+		sseTransport.WithHeader(key, value)
+	}
+	if !hasAuthHeader(server) {
+		sseTransport.WithAuthRefresh(authRefresh(server, serverName))
 	}
 
-	client := mcp.NewClient(transport)
+	// Start the SSE connection (GET /sse and wait for endpoint)
+	if err := sseTransport.Start(ctx); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = fmt.Errorf("no endpoint event within %s, raise --connect-timeout", connectTimeout)
+		}
+		return nil, nil, &sseSetupError{err: err}
+	}
 
 	cleanup := func() {
-		transport.Close()
+		sseTransport.Close()
 	}
 
-	return client, cleanup, nil
+	return sseTransport, cleanup, nil
 }
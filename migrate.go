@@ -0,0 +1,350 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// migrationTarget is one file a migration writes and the servers it adds,
+// keyed by project key and name
+type migrationTarget struct {
+	file    *configFile
+	servers map[string]map[string]MCPServer
+}
+
+func newConfigMigrateCmd() *cobra.Command {
+	var (
+		from   string
+		to     string
+		force  bool
+		dryRun bool
+	)
+	migrateCmd := &cobra.Command{
+		Use:   "migrate --from <client> --to <client>",
+		Short: "Copy server definitions from one MCP client's config to another's",
+		Long: `Read the servers of one client and write them into another client's config
+files, converting between their formats: field names such as Cline's
+streamableHttp type or Zed's context_servers, and env and headers maps.
+
+Client-wide servers go to the target's global file. Project servers go to the
+target's project file (.cursor/mcp.json, .vscode/mcp.json, .zed/settings.json
+or the project entry of ~/.claude.json), or to its global file when it has no
+project files. The source is read from its usual location unless --config is
+given.
+
+Existing servers are kept, and servers whose name is taken by a different
+definition are skipped unless --force is set. Every file that already existed
+is backed up next to itself with a .bak suffix. Comments in JSONC files are
+not preserved. --dry-run prints the diff of each file without writing it.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, client := range []string{from, to} {
+				if !slices.Contains(clientNames, client) {
+					return fmt.Errorf("invalid client %q, expected one of: %s", client, strings.Join(clientNames, ", "))
+				}
+			}
+			if from == to {
+				return fmt.Errorf("--from and --to are both %s", from)
+			}
+			sourcePath := configPath
+			if !cmd.Flags().Changed("config") {
+				path, err := clientConfigPath(from)
+				if err != nil {
+					return err
+				}
+				sourcePath = path
+			}
+			source, err := loadClientConfig(from, sourcePath)
+			if err != nil {
+				return fmt.Errorf("failed to load %s config: %w", from, err)
+			}
+			// Conversion problems are a result, not a usage error
+			cmd.SilenceUsage = true
+
+			targets, err := planMigration(source, to, force)
+			if err != nil {
+				return err
+			}
+			return runMigration(targets, to, dryRun)
+		},
+	}
+	migrateCmd.Flags().StringVar(&from, "from", "", "client to read servers from")
+	migrateCmd.Flags().StringVar(&to, "to", "", "client to write servers to")
+	migrateCmd.Flags().BoolVar(&force, "force", false, "replace target servers of the same name with a different definition")
+	migrateCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the diff of each target file instead of writing it")
+	migrateCmd.MarkFlagRequired("from")
+	migrateCmd.MarkFlagRequired("to")
+	return migrateCmd
+}
+
+// planMigration decides which file of the target client each source server
+// goes to, reading the target files and dropping servers they already have
+func planMigration(source *ClaudeConfig, client string, force bool) ([]*migrationTarget, error) {
+	// Client-wide servers first, so they win name clashes in flattened targets
+	projects := make([]string, 0, len(source.Projects))
+	for project := range source.Projects {
+		if project != userScope {
+			projects = append(projects, project)
+		}
+	}
+	sort.Strings(projects)
+	if _, ok := source.Projects[userScope]; ok {
+		projects = append([]string{userScope}, projects...)
+	}
+
+	byPath := make(map[string]*migrationTarget)
+	var targets []*migrationTarget
+	for _, project := range projects {
+		path, key, serversKey, err := migrationFile(client, project)
+		if err != nil {
+			return nil, err
+		}
+		if path == "" {
+			fmt.Fprintf(os.Stderr, "Warning: skipping the servers of %s, the project directory does not exist\n", project)
+			continue
+		}
+		target, ok := byPath[path]
+		if !ok {
+			file, err := readConfigFile(path, serversKey)
+			if err != nil {
+				return nil, err
+			}
+			target = &migrationTarget{file: file, servers: make(map[string]map[string]MCPServer)}
+			byPath[path] = target
+			targets = append(targets, target)
+		}
+		existing, err := target.file.servers(key)
+		if err != nil {
+			return nil, err
+		}
+		planned := target.servers[key]
+		if planned == nil {
+			planned = make(map[string]MCPServer)
+			target.servers[key] = planned
+		}
+
+		names := make([]string, 0, len(source.Projects[project].MCPServers))
+		for name := range source.Projects[project].MCPServers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			server := source.Projects[project].MCPServers[name]
+			if reason := unsupportedServer(client, server); reason != "" {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s: %s\n", name, reason)
+				continue
+			}
+			if other, ok := planned[name]; ok {
+				if serverFingerprint(&other) != serverFingerprint(&server) {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s of %s, another server of that name is migrated to %s\n", name, project, path)
+				}
+				continue
+			}
+			if raw, ok := existing[name]; ok {
+				current, err := decodeClientServer(client, raw)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse server %s of %s: %w", name, path, err)
+				}
+				if expressed := clientServer(client, server); serverFingerprint(&current) == serverFingerprint(&expressed) {
+					continue
+				}
+				if !force {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s, %s already has a different server of that name (use --force to replace it)\n", name, path)
+					continue
+				}
+			}
+			planned[name] = server
+		}
+	}
+	return targets, nil
+}
+
+// runMigration writes the planned servers into each target file
+func runMigration(targets []*migrationTarget, client string, dryRun bool) error {
+	migrated := 0
+	for _, target := range targets {
+		count := 0
+		for key, servers := range target.servers {
+			if len(servers) == 0 {
+				continue
+			}
+			existing, err := target.file.servers(key)
+			if err != nil {
+				return err
+			}
+			for name, server := range servers {
+				raw, err := encodeClientServer(client, server)
+				if err != nil {
+					return err
+				}
+				existing[name] = raw
+			}
+			if err := target.file.setServers(key, existing); err != nil {
+				return err
+			}
+			count += len(servers)
+		}
+		if count == 0 {
+			continue
+		}
+
+		target.file.backup = true
+		if target.file.hasComments && !dryRun {
+			fmt.Fprintf(os.Stderr, "Warning: comments in %s are not preserved, the original is kept in %s\n", target.file.path, target.file.backupPath())
+		}
+		migrated += count
+		err := saveConfigEdit(target.file, dryRun, func() {
+			backup := ""
+			if target.file.exists {
+				backup = " (backup in " + target.file.backupPath() + ")"
+			}
+			fmt.Printf("Wrote %d servers to %s%s\n", count, target.file.path, backup)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if migrated == 0 {
+		fmt.Println("Nothing to migrate, the target already has every server.")
+	}
+	return nil
+}
+
+// migrationFile returns the file of the target client holding the servers of
+// a source project, the project key inside it and the key of its server map.
+// The path is empty for a project whose directory does not exist.
+func migrationFile(client, project string) (path, key, serversKey string, err error) {
+	global, err := clientConfigPath(client)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	var projectFile string
+	switch client {
+	case clientClaudeCode:
+		// Claude Code keeps project servers inside ~/.claude.json
+		return global, project, "mcpServers", nil
+	case clientCursor:
+		projectFile, serversKey = filepath.Join(".cursor", "mcp.json"), "mcpServers"
+	case clientVSCode:
+		// Servers live in mcp.json next to settings.json rather than in it
+		global = filepath.Join(filepath.Dir(global), "mcp.json")
+		projectFile, serversKey = filepath.Join(".vscode", "mcp.json"), "servers"
+	case clientZed:
+		projectFile, serversKey = filepath.Join(".zed", "settings.json"), "context_servers"
+	default:
+		// Claude Desktop, Cline and Roo only have a global file
+		return global, userScope, "mcpServers", nil
+	}
+
+	if project == userScope {
+		return global, userScope, serversKey, nil
+	}
+	if info, err := os.Stat(project); err != nil || !info.IsDir() {
+		return "", "", "", nil
+	}
+	return filepath.Join(project, projectFile), userScope, serversKey, nil
+}
+
+// unsupportedServer explains why a client cannot run a server, if it cannot
+func unsupportedServer(client string, server MCPServer) string {
+	if client == clientClaudeDesktop && server.Type != "stdio" {
+		return "Claude Desktop only runs stdio servers from its config file"
+	}
+	return ""
+}
+
+// encodeClientServer renders a server the way a client's config file writes it
+func encodeClientServer(client string, server MCPServer) (json.RawMessage, error) {
+	server = clientServer(client, server)
+	var v interface{}
+	switch client {
+	case clientClaudeCode:
+		v = MCPServer{Type: server.Type, Command: server.Command, Args: server.Args, URL: server.URL, Env: server.Env, EnvFile: server.EnvFile, Cwd: server.Cwd, Headers: server.Headers, BasicAuth: server.BasicAuth,
+			TLSCert: server.TLSCert, TLSKey: server.TLSKey, TLSCA: server.TLSCA, InsecureSkipVerify: server.InsecureSkipVerify,
+			Proxy: server.Proxy}
+	case clientVSCode:
+		v = MCPServer{Type: server.Type, Command: server.Command, Args: server.Args, URL: server.URL, Env: server.Env, EnvFile: server.EnvFile, Cwd: server.Cwd, Headers: server.Headers}
+	case clientCline, clientRoo:
+		serverType := server.Type
+		if serverType == "http" {
+			serverType = "streamableHttp"
+		}
+		v = MCPServer{Type: serverType, Command: server.Command, Args: server.Args, URL: server.URL, Env: server.Env, Headers: server.Headers,
+			Disabled: server.Disabled, AutoApprove: server.AutoApprove}
+	case clientZed:
+		if server.Type == "stdio" {
+			v = struct {
+				Source  string            `json:"source"`
+				Command string            `json:"command"`
+				Args    []string          `json:"args,omitempty"`
+				Env     map[string]string `json:"env,omitempty"`
+			}{"custom", server.Command, server.Args, server.Env}
+		} else {
+			v = struct {
+				URL     string            `json:"url"`
+				Headers map[string]string `json:"headers,omitempty"`
+			}{server.URL, server.Headers}
+		}
+	default:
+		// Claude Desktop and Cursor infer the type from command or url
+		v = struct {
+			Command string            `json:"command,omitempty"`
+			Args    []string          `json:"args,omitempty"`
+			URL     string            `json:"url,omitempty"`
+			Env     map[string]string `json:"env,omitempty"`
+			Headers map[string]string `json:"headers,omitempty"`
+		}{server.Command, server.Args, server.URL, server.Env, server.Headers}
+	}
+	raw, err := marshalRaw(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode server: %w", err)
+	}
+	return raw, nil
+}
+
+// clientServer is a server as a client can express it: clients other than
+// Claude Code have no basicAuth field, so it becomes an Authorization header
+func clientServer(client string, server MCPServer) MCPServer {
+	if client == clientClaudeCode || server.BasicAuth == nil {
+		return server
+	}
+	headers := make(map[string]string, len(server.Headers)+1)
+	for key, value := range server.Headers {
+		if http.CanonicalHeaderKey(key) != "Authorization" {
+			headers[key] = value
+		}
+	}
+	headers["Authorization"] = server.BasicAuth.Authorization()
+	server.Headers = headers
+	server.BasicAuth = nil
+	return server
+}
+
+// decodeClientServer reads one server of a client's config file, the inverse of encodeClientServer
+func decodeClientServer(client string, raw json.RawMessage) (MCPServer, error) {
+	if client == clientZed {
+		var cs zedContextServer
+		if err := json.Unmarshal(raw, &cs); err != nil {
+			return MCPServer{}, err
+		}
+		return zedServers(zedSettings{ContextServers: map[string]zedContextServer{"": cs}})[""], nil
+	}
+
+	var server MCPServer
+	if err := json.Unmarshal(raw, &server); err != nil {
+		return MCPServer{}, err
+	}
+	if server.Type == "streamableHttp" {
+		server.Type = "http"
+	}
+	return inferServerTypes(map[string]MCPServer{"": server})[""], nil
+}
@@ -0,0 +1,458 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// oauthMetadata is the part of an authorization server's RFC 8414 metadata
+// the login flow uses
+type oauthMetadata struct {
+	Issuer                string   `json:"issuer"`
+	AuthorizationEndpoint string   `json:"authorization_endpoint"`
+	TokenEndpoint         string   `json:"token_endpoint"`
+	RegistrationEndpoint  string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported       []string `json:"scopes_supported,omitempty"`
+}
+
+// protectedResource is the part of RFC 9728 protected resource metadata the
+// login flow uses
+type protectedResource struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// oauthTokens is a token endpoint response
+type oauthTokens struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+}
+
+// oauthError is the error body of the token and registration endpoints
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// oauthServer is what discovery learns about a remote MCP server: the
+// resource to request tokens for and its authorization server
+type oauthServer struct {
+	Resource string
+	Scopes   []string
+	Metadata oauthMetadata
+	// client reaches the authorization server with the MCP server's TLS and
+	// proxy settings
+	client *http.Client
+}
+
+// discoverOAuth finds the authorization server of an MCP server following
+// the MCP authorization spec: the resource_metadata of a 401 challenge or the
+// well-known protected resource document, then the authorization server's
+// metadata, falling back to the default endpoints of the server's origin
+func discoverOAuth(ctx context.Context, server *MCPServer) (*oauthServer, error) {
+	serverURL, err := url.Parse(server.URL)
+	if err != nil || serverURL.Host == "" {
+		return nil, fmt.Errorf("invalid server url %q", server.URL)
+	}
+	client, err := httpClient(server)
+	if err != nil {
+		return nil, err
+	}
+	result := &oauthServer{Resource: strings.TrimSuffix(server.URL, "/"), client: client}
+
+	// Servers that need OAuth answer an unauthenticated request with a 401
+	// whose challenge points at their metadata
+	metadataURL, scopes, err := probeChallenge(ctx, client, server)
+	if err != nil {
+		return nil, err
+	}
+	if metadataURL == "" {
+		metadataURL = wellKnownURL(serverURL, "oauth-protected-resource")
+	}
+	result.Scopes = scopes
+
+	issuer := origin(serverURL)
+	var resource protectedResource
+	if err := getJSON(ctx, client, metadataURL, &resource); err == nil && len(resource.AuthorizationServers) > 0 {
+		issuer = resource.AuthorizationServers[0]
+		if resource.Resource != "" {
+			result.Resource = resource.Resource
+		}
+		if len(result.Scopes) == 0 {
+			result.Scopes = resource.ScopesSupported
+		}
+	}
+
+	issuerURL, err := url.Parse(issuer)
+	if err != nil || issuerURL.Host == "" {
+		return nil, fmt.Errorf("invalid authorization server %q", issuer)
+	}
+	for _, candidate := range []string{
+		wellKnownURL(issuerURL, "oauth-authorization-server"),
+		wellKnownURL(issuerURL, "openid-configuration"),
+	} {
+		if err := getJSON(ctx, client, candidate, &result.Metadata); err == nil && result.Metadata.TokenEndpoint != "" {
+			return result, nil
+		}
+	}
+
+	// Servers without metadata use fixed paths on the authorization server
+	base := origin(issuerURL)
+	result.Metadata = oauthMetadata{
+		Issuer:                base,
+		AuthorizationEndpoint: base + "/authorize",
+		TokenEndpoint:         base + "/token",
+		RegistrationEndpoint:  base + "/register",
+	}
+	return result, nil
+}
+
+// probeChallenge sends an unauthenticated initialize and returns the
+// resource_metadata URL and scope of the 401 challenge, if any
+func probeChallenge(ctx context.Context, client *http.Client, server *MCPServer) (string, []string, error) {
+	method, body := http.MethodPost, `{"jsonrpc":"2.0","id":0,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"mcpinspect","version":"1.0.0"}}}`
+	if server.Type == "sse" {
+		method, body = http.MethodGet, ""
+	}
+	req, err := http.NewRequestWithContext(ctx, method, server.URL, strings.NewReader(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range server.Headers {
+		if http.CanonicalHeaderKey(key) != "Authorization" {
+			req.Header.Set(key, value)
+		}
+	}
+
+	// An SSE stream never ends, so only the status line and headers are read
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to reach server: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		return "", nil, nil
+	}
+
+	params := parseChallenge(resp.Header.Get("WWW-Authenticate"))
+	var scopes []string
+	if scope := params["scope"]; scope != "" {
+		scopes = strings.Fields(scope)
+	}
+	return params["resource_metadata"], scopes, nil
+}
+
+// parseChallenge returns the auth-params of a Bearer WWW-Authenticate header
+func parseChallenge(header string) map[string]string {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return params
+	}
+	for rest = strings.TrimSpace(rest); rest != ""; rest = strings.TrimLeft(rest, ", ") {
+		key, value, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.IndexByte(value[1:], '"')
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key], rest = value[1:end+1], value[end+2:]
+		} else {
+			params[key], rest, _ = strings.Cut(value, ",")
+		}
+	}
+	return params
+}
+
+// wellKnownURL inserts a well-known suffix between the host and the path of
+// a URL, as RFC 8414 and RFC 9728 do for issuers and resources with a path
+func wellKnownURL(u *url.URL, suffix string) string {
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	return origin(u) + "/.well-known/" + suffix + path
+}
+
+// origin returns the scheme and host of a URL
+func origin(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// getJSON fetches and decodes a metadata document
+func getJSON(ctx context.Context, client *http.Client, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// postForm sends a form to an OAuth endpoint and decodes the JSON answer,
+// turning OAuth error bodies into errors
+func postForm(ctx context.Context, client *http.Client, endpoint string, form url.Values, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	return doOAuthRequest(client, req, v)
+}
+
+// doOAuthRequest sends a request to an OAuth endpoint and decodes its answer
+func doOAuthRequest(client *http.Client, req *http.Request, v interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", req.URL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var oauthErr oauthError
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Error != "" {
+			if oauthErr.Description != "" {
+				return fmt.Errorf("%s: %s (%s)", req.URL, oauthErr.Error, oauthErr.Description)
+			}
+			return fmt.Errorf("%s: %s", req.URL, oauthErr.Error)
+		}
+		return fmt.Errorf("%s returned status %d: %s", req.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse response of %s: %w", req.URL, err)
+	}
+	return nil
+}
+
+// registerClient performs RFC 7591 dynamic client registration of mcpinspect
+// as a public client with the given redirect URI
+func registerClient(ctx context.Context, client *http.Client, metadata oauthMetadata, redirectURI string) (string, string, error) {
+	if metadata.RegistrationEndpoint == "" {
+		return "", "", fmt.Errorf("the authorization server does not support dynamic client registration, pass --client-id")
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"client_name":                "mcpinspect",
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encode registration: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.RegistrationEndpoint, strings.NewReader(string(body)))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	var registered struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret,omitempty"`
+	}
+	if err := doOAuthRequest(client, req, &registered); err != nil {
+		return "", "", fmt.Errorf("failed to register client: %w", err)
+	}
+	if registered.ClientID == "" {
+		return "", "", fmt.Errorf("failed to register client: no client_id in the response")
+	}
+	return registered.ClientID, registered.ClientSecret, nil
+}
+
+// loginOptions are the knobs of the authorization code flow
+type loginOptions struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	Port         int
+	NoBrowser    bool
+}
+
+// oauthLogin runs the authorization code flow with PKCE: register a client
+// unless one is given, send the user to the authorization endpoint and wait
+// for the redirect to a localhost callback, then trade the code for tokens
+func oauthLogin(ctx context.Context, server *MCPServer, serverName string, opts loginOptions) (*MCPOAuthEntry, error) {
+	discovered, err := discoverOAuth(ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	metadata := discovered.Metadata
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", opts.Port))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start callback server: %w", err)
+	}
+	defer listener.Close()
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	clientID, clientSecret := opts.ClientID, opts.ClientSecret
+	if clientID == "" {
+		if clientID, clientSecret, err = registerClient(ctx, discovered.client, metadata, redirectURI); err != nil {
+			return nil, err
+		}
+	}
+
+	verifier := randomToken()
+	challenge := sha256.Sum256([]byte(verifier))
+	state := randomToken()
+	scopes := opts.Scopes
+	if len(scopes) == 0 {
+		scopes = discovered.Scopes
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {clientID},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"resource":              {discovered.Resource},
+	}
+	if len(scopes) > 0 {
+		query.Set("scope", strings.Join(scopes, " "))
+	}
+	authURL := metadata.AuthorizationEndpoint
+	if strings.Contains(authURL, "?") {
+		authURL += "&" + query.Encode()
+	} else {
+		authURL += "?" + query.Encode()
+	}
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+	callback := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/callback" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "Invalid state, close this window and try again.", http.StatusBadRequest)
+			errCh <- fmt.Errorf("authorization failed: state mismatch")
+		case q.Get("error") != "":
+			http.Error(w, "Authorization failed: "+q.Get("error"), http.StatusBadRequest)
+			errCh <- fmt.Errorf("authorization failed: %s %s", q.Get("error"), q.Get("error_description"))
+		default:
+			fmt.Fprintln(w, "mcpinspect is authorized. You can close this window.")
+			codeCh <- q.Get("code")
+		}
+	})}
+	go callback.Serve(listener)
+	defer callback.Close()
+
+	fmt.Fprintf(os.Stderr, "Open this URL to authorize mcpinspect for %s:\n\n  %s\n\n", serverName, authURL)
+	if !opts.NoBrowser {
+		if err := openBrowser(authURL); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to open a browser: %v\n", err)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Waiting for the redirect to %s ...\n", redirectURI)
+
+	var code string
+	select {
+	case code = <-codeCh:
+	case err := <-errCh:
+		return nil, err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timed out waiting for authorization: %w", ctx.Err())
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {clientID},
+		"code_verifier": {verifier},
+		"resource":      {discovered.Resource},
+	}
+	if clientSecret != "" {
+		form.Set("client_secret", clientSecret)
+	}
+	var tokens oauthTokens
+	if err := postForm(ctx, discovered.client, metadata.TokenEndpoint, form, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	if tokens.AccessToken == "" {
+		return nil, fmt.Errorf("failed to exchange authorization code: no access_token in the response")
+	}
+
+	entry := &MCPOAuthEntry{
+		ServerName:   serverName,
+		ServerURL:    server.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		Scope:        tokens.Scope,
+	}
+	if entry.Scope == "" {
+		entry.Scope = strings.Join(scopes, " ")
+	}
+	if tokens.ExpiresIn > 0 {
+		entry.ExpiresAt = time.Now().Add(time.Duration(tokens.ExpiresIn) * time.Second).UnixMilli()
+	}
+	return entry, nil
+}
+
+// randomToken returns 32 random bytes, base64url encoded, for PKCE verifiers and state
+func randomToken() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// openBrowser opens a URL with the platform's default handler
+func openBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
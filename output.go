@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// Output formats selectable with --output
+const (
+	outputTable  = "table"
+	outputJSON   = "json"
+	outputCSV    = "csv"
+	outputJUnit  = "junit"
+	outputGitHub = "github"
+)
+
+var outputFormat string
+
+// formatTemplate is the --format Go template, applied to the result a command
+// would print with --output json
+var formatTemplate string
+
+// outputTemplate is formatTemplate parsed, nil without --format
+var outputTemplate *template.Template
+
+// templateFuncs are the helpers available to --format on top of the text/template builtins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputTable, outputJSON, outputCSV, outputJUnit, outputGitHub:
+	default:
+		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+	if formatTemplate == "" {
+		return nil
+	}
+	if outputFormat != outputTable && outputFormat != outputJSON {
+		return fmt.Errorf("--format cannot be combined with --output %s", outputFormat)
+	}
+
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(unescapeFormat(formatTemplate))
+	if err != nil {
+		return fmt.Errorf("failed to parse --format: %w", err)
+	}
+	outputTemplate = tmpl
+	// The template runs where the JSON result would be printed
+	outputFormat = outputJSON
+	return nil
+}
+
+// reportCommands lists the commands that can write each CI report format;
+// the others would fall back to printing a table into the report
+var reportCommands = map[string][]string{
+	outputJUnit:  {"ping", "doctor", "--probe", "check"},
+	outputGitHub: {"ping", "doctor", "--probe", "check", "diff", "collisions", "config validate", "audit"},
+}
+
+// validateReportOutput rejects --output junit and github for commands that cannot write them
+func validateReportOutput(cmd *cobra.Command) error {
+	commands, ok := reportCommands[outputFormat]
+	if !ok {
+		return nil
+	}
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if cmd == cmd.Root() {
+		name = ""
+		if probe, _ := cmd.Flags().GetBool("probe"); probe {
+			name = "--probe"
+		}
+	}
+	if slices.Contains(commands, name) {
+		return nil
+	}
+	return fmt.Errorf("--output %s applies to %s", outputFormat, strings.Join(commands, ", "))
+}
+
+// writeJSON prints v to stdout as indented JSON, or through --format when set
+func writeJSON(v interface{}) error {
+	if outputTemplate != nil {
+		return writeTemplate(v)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// unescapeFormat turns the literal \t and \n a shell passes through into the
+// tab and newline docker --format users expect. Actions are left alone, so
+// string literals such as {{"\n"}} keep their Go escapes.
+func unescapeFormat(format string) string {
+	escapes := strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+	var b strings.Builder
+	for {
+		start := strings.Index(format, "{{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(format[start:], "}}")
+		if end < 0 {
+			break
+		}
+		end += start + len("}}")
+		b.WriteString(escapes.Replace(format[:start]))
+		b.WriteString(format[start:end])
+		format = format[end:]
+	}
+	b.WriteString(escapes.Replace(format))
+	return b.String()
+}
+
+// writeTemplate executes --format on v. A list is rendered one element per
+// line, like docker --format, so templates describe a single server or tool.
+func writeTemplate(v interface{}) error {
+	items := []interface{}{v}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+		items = make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = rv.Index(i).Interface()
+		}
+	}
+
+	for _, item := range items {
+		var out strings.Builder
+		if err := outputTemplate.Execute(&out, item); err != nil {
+			return fmt.Errorf("failed to execute --format: %w", err)
+		}
+		text := out.String()
+		if !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if _, err := os.Stdout.WriteString(text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeJSONFile writes v to path as indented JSON with a trailing newline
+func writeJSONFile(path string, v interface{}) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeCSV prints a header and rows to stdout as RFC 4180 CSV
+func writeCSV(header []string, rows [][]string) error {
+	w := csv.NewWriter(os.Stdout)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// shellJoin joins command arguments, quoting any that would not survive a shell round-trip
+func shellJoin(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`|&;<>()*?[]{}~#") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// ImplementationInfo identifies the server software reported during initialize
+type ImplementationInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// String renders the implementation as "name vX.Y.Z"
+func (i ImplementationInfo) String() string {
+	if i.Version == "" {
+		return i.Name
+	}
+	return i.Name + " v" + i.Version
+}
+
+// ServerHeader identifies the server a structured result came from
+type ServerHeader struct {
+	Name            string             `json:"name"`
+	Type            string             `json:"type"`
+	ServerInfo      ImplementationInfo `json:"serverInfo"`
+	ProtocolVersion string             `json:"protocolVersion"`
+
+	// RequestedProtocolVersion is set when --protocol-version chose the version offered
+	RequestedProtocolVersion string `json:"requestedProtocolVersion,omitempty"`
+}
+
+func newServerHeader(conn *Connection) ServerHeader {
+	return ServerHeader{
+		Name: conn.Name,
+		Type: conn.Server.Type,
+		ServerInfo: ImplementationInfo{
+			Name:    conn.Init.ServerInfo.Name,
+			Version: conn.Init.ServerInfo.Version,
+		},
+		ProtocolVersion:          conn.Init.ProtocolVersion,
+		RequestedProtocolVersion: protocolVersion,
+	}
+}
+
+// ToolInfo describes a single tool exposed by a server
+type ToolInfo struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description"`
+	InputSchema  interface{}            `json:"inputSchema"`
+	OutputSchema interface{}            `json:"outputSchema,omitempty"`
+	Annotations  map[string]interface{} `json:"annotations,omitempty"`
+}
+
+// InspectResult is the structured result of inspecting a server
+type InspectResult struct {
+	ServerHeader
+	Projects     []string               `json:"projects,omitempty"`
+	Tools        []ToolInfo             `json:"tools"`
+	Capabilities map[string]interface{} `json:"capabilities"`
+	Instructions string                 `json:"instructions,omitempty"`
+
+	// Provenance is the registry's record of the npx, uvx or pipx package the server runs
+	Provenance *Provenance `json:"provenance,omitempty"`
+
+	// Initialize is the raw initialize result, including fields not modeled above
+	Initialize json.RawMessage `json:"initialize,omitempty"`
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// PingResult is the outcome of health-checking a single server
+type PingResult struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Reachable bool    `json:"reachable"`
+	ConnectMs float64 `json:"connectMs,omitempty"`
+	RTTMs     float64 `json:"rttMs,omitempty"`
+	Error     string  `json:"error,omitempty"`
+
+	// err is the failure behind Error, kept for the exit code
+	err error
+
+	// scope locates the server's definition for --output github
+	scope string
+}
+
+func newPingCmd() *cobra.Command {
+	var all bool
+	pingCmd := &cobra.Command{
+		Use:   "ping [server-name]",
+		Short: "Check that servers are reachable and measure round-trip time",
+		Long: `Connect to a server, perform the initialize handshake and send an MCP ping,
+reporting whether the server is reachable and how long the ping took.
+
+Use --all to check every configured server. The command exits with an error
+if any server is unreachable.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either a server name or --all")
+			}
+			// Unreachable servers are a result, not a usage error
+			cmd.SilenceUsage = true
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			var names []string
+			if all {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			} else {
+				names = args
+			}
+			return pingServers(config, names)
+		},
+	}
+	pingCmd.Flags().BoolVar(&all, "all", false, "ping every configured server")
+	return pingCmd
+}
+
+func pingServers(config *ClaudeConfig, names []string) error {
+	results := make([]PingResult, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		results[i] = pingServer(config, names[i])
+	})
+
+	var errs []error
+	for _, result := range results {
+		if !result.Reachable {
+			errs = append(errs, result.err)
+		}
+	}
+
+	if err := printPingResults(results); err != nil {
+		return err
+	}
+
+	if len(errs) > 0 && failsOn(failUnreachable, true) {
+		return withExitCode(failureExitCode(errs), fmt.Errorf("%d of %d servers unreachable", len(errs), len(results)))
+	}
+	return nil
+}
+
+// pingServer connects, initializes and pings a server, recording failures rather than returning them
+func pingServer(config *ClaudeConfig, serverName string) PingResult {
+	result := PingResult{Name: serverName}
+	if server, err := findServer(config, serverName); err == nil {
+		result.Type, result.scope = server.Type, server.Scope
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Now()
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		result.Error, result.err = err.Error(), err
+		return result
+	}
+	defer conn.Close()
+	result.ConnectMs = durationMs(time.Since(start))
+
+	start = time.Now()
+	if err := conn.Client.Ping(ctx); err != nil {
+		result.Error, result.err = err.Error(), err
+		return result
+	}
+	result.RTTMs = durationMs(time.Since(start))
+	result.Reachable = true
+
+	return result
+}
+
+func printPingResults(results []PingResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(results)
+	case outputJUnit:
+		return writeJUnit("mcpinspect ping", pingJUnit(results))
+	case outputGitHub:
+		return writeAnnotations(pingAnnotations(results))
+	case outputCSV:
+		rows := make([][]string, 0, len(results))
+		for _, r := range results {
+			rows = append(rows, []string{r.Name, r.Type, strconv.FormatBool(r.Reachable), formatMs(r.ConnectMs), formatMs(r.RTTMs), r.Error})
+		}
+		return writeCSV([]string{"NAME", "TYPE", "REACHABLE", "CONNECT MS", "RTT MS", "ERROR"}, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "NAME\tTYPE\t%s\tCONNECT\tRTT\tERROR\n", paint("STATUS", ansiDefault))
+
+	reachable := 0
+	for _, r := range results {
+		status := "unreachable"
+		connect, rtt := "[N/A]", "[N/A]"
+		if r.Reachable {
+			status = "ok"
+			reachable++
+			connect = formatMs(r.ConnectMs) + "ms"
+			rtt = formatMs(r.RTTMs) + "ms"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, r.Type, paintStatus(status, status), connect, rtt, r.Error)
+	}
+
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d/%d servers reachable\n", reachable, len(results))
+	return nil
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// formatMs renders milliseconds with one decimal, or two below 1ms so fast local requests don't all read 0.0
+func formatMs(ms float64) string {
+	if ms == 0 {
+		return ""
+	}
+	if ms < 1 {
+		return strconv.FormatFloat(ms, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(ms, 'f', 1, 64)
+}
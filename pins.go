@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// noPin turns off recording and verifying tool pins
+var noPin bool
+
+// pinsMu serializes the read-modify-write of the pin store when --probe
+// inspects servers concurrently
+var pinsMu sync.Mutex
+
+// ToolPin records the tools a server exposed when it was first inspected, so
+// a later change to them, such as a rug pull swapping a trusted tool's
+// description, can be shown as a diff
+type ToolPin struct {
+	Server   string     `json:"server"`
+	Target   string     `json:"target"`
+	Hash     string     `json:"hash"`
+	PinnedAt string     `json:"pinnedAt"`
+	Tools    []ToolInfo `json:"tools"`
+}
+
+// pinStore is the file of pins, keyed by pinKey
+type pinStore struct {
+	Pins map[string]*ToolPin `json:"pins"`
+}
+
+func newPinsCmd() *cobra.Command {
+	pinsCmd := &cobra.Command{
+		Use:   "pins",
+		Short: "Manage the pinned tool definitions of servers",
+		Long: `Inspecting a server pins a hash of its tool names, descriptions and input
+schemas the first time. When a later inspection or --probe finds them changed,
+a warning with the diff is printed until the change is accepted, guarding
+against a server silently swapping what its tools do after you trusted it.`,
+	}
+	pinsCmd.AddCommand(newPinsListCmd(), newPinsAcceptCmd(), newPinsForgetCmd())
+	return pinsCmd
+}
+
+func newPinsListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the pinned servers",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := loadPins()
+			if err != nil {
+				return err
+			}
+			return printPins(store)
+		},
+	}
+}
+
+func newPinsAcceptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "accept <server-name>",
+		Short: "Pin a server's current tools, accepting a reviewed change",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return acceptPin(config, args[0])
+		},
+	}
+}
+
+func newPinsForgetCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "forget <server-name>",
+		Short: "Delete a server's pins; the next inspection pins it again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return forgetPins(args[0])
+		},
+	}
+}
+
+// pinsPath is where the pins are kept, next to mcpinspect's credentials
+func pinsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "mcpinspect", "pins.json"), nil
+}
+
+// loadPins reads the pin store; a missing file has no pins
+func loadPins() (*pinStore, error) {
+	store := &pinStore{Pins: make(map[string]*ToolPin)}
+	path, err := pinsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pins: %w", err)
+	}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("failed to parse pins %s: %w", path, err)
+	}
+	if store.Pins == nil {
+		store.Pins = make(map[string]*ToolPin)
+	}
+	return store, nil
+}
+
+func savePins(store *pinStore) error {
+	path, err := pinsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create pins directory: %w", err)
+	}
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(store); err != nil {
+		return fmt.Errorf("failed to encode pins: %w", err)
+	}
+
+	// Replace the store atomically, so a crash or a concurrent run never
+	// leaves a truncated file that every later run would fail to load
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write pins: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write pins: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write pins: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to write pins: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write pins: %w", err)
+	}
+	return nil
+}
+
+// pinKey keys a server's pin by its name and a hash of what it runs, like
+// credentialKey, so servers sharing a name in different projects stay apart
+func pinKey(serverName, target string) string {
+	sum := sha256.Sum256([]byte(target))
+	return serverName + "|" + hex.EncodeToString(sum[:8])
+}
+
+// pinnedTools keeps the fields a pin covers, sorted by name
+func pinnedTools(tools []ToolInfo) []ToolInfo {
+	pinned := make([]ToolInfo, len(tools))
+	for i, tool := range tools {
+		pinned[i] = ToolInfo{Name: tool.Name, Description: tool.Description, InputSchema: tool.InputSchema}
+	}
+	sort.Slice(pinned, func(i, j int) bool {
+		return pinned[i].Name < pinned[j].Name
+	})
+	return pinned
+}
+
+// toolsHash hashes the canonical JSON of pinned tools; encoding/json sorts
+// map keys, so the same schemas always hash the same
+func toolsHash(pinned []ToolInfo) string {
+	data, _ := json.Marshal(pinned)
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newToolPin records the tools a connected server exposes now
+func newToolPin(conn *Connection, tools []ToolInfo) *ToolPin {
+	pinned := pinnedTools(tools)
+	return &ToolPin{
+		Server:   conn.Name,
+		Target:   formatTarget(*conn.Server),
+		Hash:     toolsHash(pinned),
+		PinnedAt: time.Now().UTC().Format(time.RFC3339),
+		Tools:    pinned,
+	}
+}
+
+// verifyPin pins a server's tools the first time it is inspected and warns
+// with a diff when they no longer match the pin. The pin is kept until the
+// change is accepted with `pins accept`.
+func verifyPin(conn *Connection, tools []ToolInfo) {
+	if noPin {
+		return
+	}
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+
+	store, err := loadPins()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: tool pins not checked: %v\n", err)
+		return
+	}
+	current := newToolPin(conn, tools)
+	key := pinKey(current.Server, current.Target)
+	pin, ok := store.Pins[key]
+	if !ok {
+		store.Pins[key] = current
+		if err := savePins(store); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: tools of %s not pinned: %v\n", conn.Name, err)
+			return
+		}
+		logger.Info("tools pinned", "server", conn.Name, "tools", len(current.Tools), "hash", current.Hash)
+		return
+	}
+	if pin.Hash == current.Hash {
+		return
+	}
+
+	diff := diffInspections(&InspectResult{Tools: pin.Tools}, &InspectResult{Tools: current.Tools})
+	fmt.Fprintln(os.Stderr, paint(fmt.Sprintf("WARNING: the tools of %s changed since they were pinned on %s (%s -> %s)", conn.Name, pin.PinnedAt, shortHash(pin.Hash), shortHash(current.Hash)), ansiRed))
+	fmt.Fprintln(os.Stderr, "A server changing its tools after you trusted them can be a rug pull. Review the change:")
+	printToolChanges(os.Stderr, diff)
+	fmt.Fprintf(os.Stderr, "Run `mcpinspect pins accept %s` once the new tools are trusted.\n\n", conn.Name)
+}
+
+// shortHash abbreviates a pin hash for display
+func shortHash(hash string) string {
+	if len(hash) > len("sha256:")+12 {
+		return hash[:len("sha256:")+12]
+	}
+	return hash
+}
+
+// acceptPin connects to a server and pins its current tools, replacing the old pin
+func acceptPin(config *ClaudeConfig, serverName string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tools, err := listTools(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	store, err := loadPins()
+	if err != nil {
+		return err
+	}
+	pin := newToolPin(conn, tools)
+	key := pinKey(pin.Server, pin.Target)
+	if old, ok := store.Pins[key]; ok && old.Hash == pin.Hash {
+		fmt.Printf("The tools of %s already match their pin (%s)\n", serverName, shortHash(pin.Hash))
+		return nil
+	}
+	store.Pins[key] = pin
+	if err := savePins(store); err != nil {
+		return err
+	}
+	fmt.Printf("Pinned %d tools of %s (%s)\n", len(pin.Tools), serverName, shortHash(pin.Hash))
+	return nil
+}
+
+// forgetPins deletes every pin of a server name
+func forgetPins(serverName string) error {
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	store, err := loadPins()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for key, pin := range store.Pins {
+		if pin.Server == serverName {
+			delete(store.Pins, key)
+			removed++
+		}
+	}
+	if removed == 0 {
+		return fmt.Errorf("no pins stored for %s", serverName)
+	}
+	if err := savePins(store); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d pins of %s\n", removed, serverName)
+	return nil
+}
+
+func printPins(store *pinStore) error {
+	pins := make([]*ToolPin, 0, len(store.Pins))
+	for _, pin := range store.Pins {
+		pins = append(pins, pin)
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		if pins[i].Server != pins[j].Server {
+			return pins[i].Server < pins[j].Server
+		}
+		return pins[i].Target < pins[j].Target
+	})
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(pins)
+	case outputCSV:
+		rows := make([][]string, 0, len(pins))
+		for _, pin := range pins {
+			rows = append(rows, []string{pin.Server, pin.Target, fmt.Sprint(len(pin.Tools)), pin.Hash, pin.PinnedAt})
+		}
+		return writeCSV([]string{"SERVER", "TARGET", "TOOLS", "HASH", "PINNED"}, rows)
+	}
+
+	if len(pins) == 0 {
+		fmt.Println("No tools pinned.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVER\tTARGET\tTOOLS\tHASH\tPINNED")
+	for _, pin := range pins {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", pin.Server, pin.Target, len(pin.Tools), shortHash(pin.Hash), pin.PinnedAt)
+	}
+	w.Flush()
+
+	path, _ := pinsPath()
+	fmt.Println()
+	fmt.Printf("%d servers pinned | %s\n", len(pins), path)
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// Probe statuses
+const (
+	probeOK    = "ok"
+	probeError = "error"
+)
+
+// ProbeResult is the live state of a server gathered by list --probe
+type ProbeResult struct {
+	Status        string `json:"status"`
+	Tools         int    `json:"tools"`
+	ServerVersion string `json:"serverVersion,omitempty"`
+	Error         string `json:"error,omitempty"`
+
+	// err is the failure behind Error, kept for the exit code
+	err error
+}
+
+// probeServers connects to the servers concurrently and records each one's live status
+func probeServers(config *ClaudeConfig, servers []*ServerInfo) {
+	runPool(len(servers), concurrency, func(i int) {
+		serverConfig := config
+		if servers[i].config != nil {
+			serverConfig = servers[i].config
+		}
+		result := probeServer(serverConfig, servers[i].Name)
+		servers[i].Probe = &result
+	})
+}
+
+// probeServer runs initialize and tools/list against a server, recording
+// failures in the result instead of returning them
+func probeServer(config *ClaudeConfig, serverName string) ProbeResult {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return ProbeResult{Status: probeError, Error: err.Error(), err: err}
+	}
+	defer conn.Close()
+
+	tools, err := listTools(ctx, conn)
+	if err != nil {
+		return ProbeResult{Status: probeError, Error: err.Error(), err: err}
+	}
+	verifyPin(conn, tools)
+
+	return ProbeResult{
+		Status:        probeOK,
+		Tools:         len(tools),
+		ServerVersion: formatServerInfo(conn.Init),
+	}
+}
+
+// probeFailure fails a --probe listing for the servers that could not be
+// probed, which only --fail-on unreachable asks for
+func probeFailure(servers []*ServerInfo) error {
+	var errs []error
+	for _, info := range servers {
+		if info.Probe != nil && info.Probe.Status != probeOK {
+			errs = append(errs, info.Probe.err)
+		}
+	}
+	if len(errs) == 0 || !failsOn(failUnreachable, false) {
+		return nil
+	}
+	return withExitCode(failureExitCode(errs), fmt.Errorf("%d of %d servers unreachable", len(errs), len(servers)))
+}
+
+// printProbeFailures lists the error for every server that could not be probed
+func printProbeFailures(servers []*ServerInfo) {
+	first := true
+	for _, info := range servers {
+		if info.Probe == nil || info.Probe.Status == probeOK {
+			continue
+		}
+		if first {
+			fmt.Fprintln(os.Stderr)
+			first = false
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", info.Name, info.Probe.Error)
+	}
+}
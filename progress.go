@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/term"
+)
+
+// progressBarWidth is the number of cells in a rendered progress bar
+const progressBarWidth = 30
+
+// progressTokens numbers the progress tokens attached to requests
+var progressTokens atomic.Int64
+
+// ProgressUpdate is one notifications/progress sent by a server for a request
+type ProgressUpdate struct {
+	ProgressToken interface{} `json:"progressToken"`
+	Progress      float64     `json:"progress"`
+	Total         *float64    `json:"total,omitempty"`
+	Message       string      `json:"message,omitempty"`
+}
+
+// newProgressToken returns a progress token unique within this process
+func newProgressToken() string {
+	return fmt.Sprintf("mcpinspect-%d", progressTokens.Add(1))
+}
+
+// watchProgress calls onProgress for each notifications/progress carrying the
+// given token until the returned function is called
+func watchProgress(conn *Connection, token string, onProgress func(ProgressUpdate)) func() {
+	return conn.Session.OnNotification("notifications/progress", func(params json.RawMessage) {
+		var update ProgressUpdate
+		if err := json.Unmarshal(params, &update); err != nil {
+			return
+		}
+		if fmt.Sprint(update.ProgressToken) != token {
+			return
+		}
+		onProgress(update)
+	})
+}
+
+// ProgressRenderer draws progress updates on stderr: a bar redrawn in place on
+// a terminal, or one line per update otherwise
+type ProgressRenderer struct {
+	out         io.Writer
+	interactive bool
+	mu          sync.Mutex
+	drawn       bool
+}
+
+// NewProgressRenderer creates a renderer writing to stderr
+func NewProgressRenderer() *ProgressRenderer {
+	return &ProgressRenderer{
+		out:         os.Stderr,
+		interactive: term.IsTerminal(int(os.Stderr.Fd())),
+	}
+}
+
+// Update draws a progress update
+func (r *ProgressRenderer) Update(update ProgressUpdate) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.interactive {
+		fmt.Fprintf(r.out, "Progress: %s\n", formatProgress(update))
+		return
+	}
+
+	bar := ""
+	if update.Total != nil && *update.Total > 0 {
+		filled := int(update.Progress / *update.Total * progressBarWidth)
+		if filled > progressBarWidth {
+			filled = progressBarWidth
+		}
+		bar = "[" + strings.Repeat("#", filled) + strings.Repeat(".", progressBarWidth-filled) + "] "
+	}
+	// \033[K clears what is left of a longer previous line
+	fmt.Fprintf(r.out, "\r%s%s\033[K", bar, formatProgress(update))
+	r.drawn = true
+}
+
+// Done clears the progress line so the result can be printed after it
+func (r *ProgressRenderer) Done() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.drawn {
+		fmt.Fprint(r.out, "\r\033[K")
+		r.drawn = false
+	}
+}
+
+// formatProgress renders an update as "3/10 (30%) message", or "3 message" without a total
+func formatProgress(update ProgressUpdate) string {
+	text := formatNumber(update.Progress)
+	if update.Total != nil {
+		text += "/" + formatNumber(*update.Total)
+		if *update.Total > 0 {
+			text += fmt.Sprintf(" (%.0f%%)", update.Progress / *update.Total * 100)
+		}
+	}
+	if update.Message != "" {
+		text += " " + update.Message
+	}
+	return text
+}
+
+// formatNumber renders whole numbers without decimals
+func formatNumber(f float64) string {
+	if f == float64(int64(f)) {
+		return fmt.Sprintf("%d", int64(f))
+	}
+	return fmt.Sprintf("%.2f", f)
+}
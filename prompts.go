@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/spf13/cobra"
+)
+
+// PromptMessage is a single message returned by prompts/get
+type PromptMessage struct {
+	Role    string       `json:"role"`
+	Content ContentBlock `json:"content"`
+}
+
+// PromptResult is the result of prompts/get
+type PromptResult struct {
+	Description string          `json:"description,omitempty"`
+	Messages    []PromptMessage `json:"messages"`
+}
+
+// PromptsResult is the structured result of listing a server's prompts
+type PromptsResult struct {
+	ServerHeader
+	Prompts []*mcp.PromptSchema `json:"prompts"`
+}
+
+func newPromptsCmd() *cobra.Command {
+	promptsCmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Inspect prompts exposed by an MCP server",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list <server-name>",
+		Short: "List the prompts a server exposes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return listPrompts(config, args[0])
+		},
+	}
+
+	var promptArgs []string
+	getCmd := &cobra.Command{
+		Use:   "get <server-name> <prompt-name>",
+		Short: "Get a prompt and render its messages",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			arguments, err := parseKeyValues(promptArgs)
+			if err != nil {
+				return err
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return getPrompt(config, args[0], args[1], arguments)
+		},
+	}
+	getCmd.Flags().StringArrayVar(&promptArgs, "arg", nil, "prompt argument as key=value (repeatable)")
+
+	promptsCmd.AddCommand(listCmd, getCmd)
+	return promptsCmd
+}
+
+func listPrompts(config *ClaudeConfig, serverName string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return printPromptList(ctx, conn)
+}
+
+// printPromptList lists a connected server's prompts in the selected output format
+func printPromptList(ctx context.Context, conn *Connection) error {
+	prompts, err := conn.Client.ListPrompts(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list prompts: %w", err)
+	}
+
+	// Sort prompts alphabetically
+	sort.Slice(prompts.Prompts, func(i, j int) bool {
+		return prompts.Prompts[i].Name < prompts.Prompts[j].Name
+	})
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(PromptsResult{
+			ServerHeader: newServerHeader(conn),
+			Prompts:      prompts.Prompts,
+		})
+	case outputCSV:
+		rows := make([][]string, 0, len(prompts.Prompts))
+		for _, prompt := range prompts.Prompts {
+			rows = append(rows, []string{conn.Name, prompt.Name, formatPromptArgs(prompt), promptDescription(prompt)})
+		}
+		return writeCSV([]string{"SERVER", "NAME", "ARGUMENTS", "DESCRIPTION"}, rows)
+	}
+
+	// Print prompts table
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tARGUMENTS\tDESCRIPTION")
+
+	for _, prompt := range prompts.Prompts {
+		args := formatPromptArgs(prompt)
+		if args == "" {
+			args = "[N/A]"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", prompt.Name, args, promptDescription(prompt))
+	}
+
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d prompts | %s | %s\n", len(prompts.Prompts), conn.Server.Type, formatServerInfo(conn.Init))
+
+	return nil
+}
+
+// formatPromptArgs lists a prompt's argument names, optional ones in brackets
+func formatPromptArgs(prompt *mcp.PromptSchema) string {
+	names := make([]string, 0, len(prompt.Arguments))
+	for _, arg := range prompt.Arguments {
+		if arg.Required != nil && *arg.Required {
+			names = append(names, arg.Name)
+		} else {
+			names = append(names, "["+arg.Name+"]")
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func promptDescription(prompt *mcp.PromptSchema) string {
+	if prompt.Description == nil {
+		return ""
+	}
+	return *prompt.Description
+}
+
+func getPrompt(config *ClaudeConfig, serverName, promptName string, arguments map[string]string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return printPrompt(ctx, conn, promptName, arguments)
+}
+
+// printPrompt renders a prompt with the given arguments
+func printPrompt(ctx context.Context, conn *Connection, promptName string, arguments map[string]string) error {
+	// Use a raw request since mcp-golang cannot decode non-text prompt content
+	raw, err := conn.Session.Request(ctx, "prompts/get", map[string]interface{}{
+		"name":      promptName,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get prompt: %w", err)
+	}
+
+	var result PromptResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return fmt.Errorf("failed to parse prompt: %w", err)
+	}
+
+	if outputFormat == outputJSON {
+		return writeJSON(result)
+	}
+
+	if result.Description != "" {
+		fmt.Printf("Description: %s\n", result.Description)
+	}
+
+	for _, msg := range result.Messages {
+		fmt.Println()
+		fmt.Printf("[%s] %s\n", msg.Role, msg.Content.Type)
+		fmt.Println(formatContent(msg.Content))
+	}
+
+	return nil
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// noProvenance turns off looking up the packages of npx, uvx and pipx servers
+var noProvenance bool
+
+// Package ecosystems a server's command can download from
+const (
+	ecosystemNpm  = "npm"
+	ecosystemPyPI = "pypi"
+)
+
+// Thresholds of the audit's package findings
+const (
+	newReleaseAge = 7 * 24 * time.Hour
+	fewDownloads  = 100
+)
+
+// Registry endpoints queried for provenance
+var (
+	npmRegistryURL  = "https://registry.npmjs.org"
+	npmDownloadsURL = "https://api.npmjs.org/downloads/point/last-week"
+	pypiURL         = "https://pypi.org/pypi"
+	pypiStatsURL    = "https://pypistats.org/api/packages"
+)
+
+// PackageRef is the package a server's command downloads and runs
+type PackageRef struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+
+	// Requested is the version, range or tag the command asks for, if any
+	Requested string `json:"requested,omitempty"`
+}
+
+// Provenance is what the package registry tells about a server's package
+type Provenance struct {
+	PackageRef
+	Version         string   `json:"version,omitempty"`
+	Latest          string   `json:"latest,omitempty"`
+	Pinned          bool     `json:"pinned"`
+	Published       string   `json:"published,omitempty"`
+	Maintainers     []string `json:"maintainers,omitempty"`
+	WeeklyDownloads *int     `json:"weeklyDownloads,omitempty"`
+	Repository      string   `json:"repository,omitempty"`
+	Deprecated      string   `json:"deprecated,omitempty"`
+	Error           string   `json:"error,omitempty"`
+}
+
+// exactNpmVersion matches a version npx runs as is, rather than a range or tag
+var exactNpmVersion = regexp.MustCompile(`^v?\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// pypiSpec splits a requirement such as mcp-server-fetch[extra]==1.0 into its
+// name and version specifier
+var pypiSpec = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*(.*)$`)
+
+// Flags of npx, uvx and pipx run that take a value, so the value is not taken
+// for the package
+var (
+	npxValueFlags  = []string{"--package", "-p", "--call", "-c", "--registry", "--cache", "--userconfig"}
+	uvxValueFlags  = []string{"--from", "--with", "--with-editable", "--with-requirements", "--python", "-p", "--index", "--index-url", "--default-index", "--extra-index-url", "-i", "--constraints", "-c", "--overrides", "--refresh-package", "--reinstall-package", "--upgrade-package", "-P", "--cache-dir", "--env-file"}
+	pipxValueFlags = []string{"--spec", "--python", "--index-url", "-i", "--pip-args", "--fetch-python"}
+)
+
+// serverPackage returns the package a server's command downloads: the npm
+// package of npx, or the PyPI package of uvx, uv tool run or pipx run.
+// Windows' cmd /c wrapper is looked through. Local paths, URLs and git
+// sources are not registry packages.
+func serverPackage(server MCPServer) (PackageRef, bool) {
+	command, args := server.Command, server.Args
+	if commandName(command) == "cmd" && len(args) > 1 && strings.EqualFold(args[0], "/c") {
+		command, args = args[1], args[2:]
+	}
+
+	var spec, ecosystem string
+	switch commandName(command) {
+	case "npx":
+		// npx [--package pkg] <pkg|bin> runs the package, npx -c 'cmd' only --package
+		ecosystem = ecosystemNpm
+		flags, first := scanArgs(args, npxValueFlags)
+		spec = cmp.Or(flags["--package"], flags["-p"])
+		if spec == "" && first >= 0 && flags["--call"] == "" && flags["-c"] == "" {
+			spec = args[first]
+		}
+	case "uv":
+		if len(args) < 2 || args[0] != "tool" || args[1] != "run" {
+			return PackageRef{}, false
+		}
+		args = args[2:]
+		fallthrough
+	case "uvx":
+		// uvx [--from pkg] <tool>, where the tool is also the package without --from
+		ecosystem = ecosystemPyPI
+		flags, first := scanArgs(args, uvxValueFlags)
+		spec = flags["--from"]
+		if spec == "" && first >= 0 {
+			spec = args[first]
+		}
+	case "pipx":
+		if len(args) < 1 || args[0] != "run" {
+			return PackageRef{}, false
+		}
+		// pipx run [--spec pkg] <app>, like uvx
+		ecosystem = ecosystemPyPI
+		flags, first := scanArgs(args[1:], pipxValueFlags)
+		spec = flags["--spec"]
+		if spec == "" && first >= 0 {
+			spec = args[1+first]
+		}
+	default:
+		return PackageRef{}, false
+	}
+
+	if spec == "" || strings.Contains(spec, ":") || strings.HasPrefix(spec, ".") || strings.HasPrefix(spec, "/") || strings.HasPrefix(spec, "~") {
+		return PackageRef{}, false
+	}
+	if ecosystem == ecosystemNpm {
+		return npmPackage(spec)
+	}
+	return pypiPackage(spec)
+}
+
+// commandName is the base name of a command without a Windows extension
+func commandName(command string) string {
+	name := strings.ToLower(filepath.Base(strings.ReplaceAll(command, `\`, "/")))
+	for _, ext := range []string{".cmd", ".exe", ".bat"} {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return name
+}
+
+// scanArgs collects the values of valueFlags, as --flag value or --flag=value,
+// and returns the index of the first argument that is not a flag, or -1
+func scanArgs(args []string, valueFlags []string) (map[string]string, int) {
+	flags := make(map[string]string)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			if i+1 < len(args) {
+				return flags, i + 1
+			}
+			return flags, -1
+		}
+		if !strings.HasPrefix(arg, "-") {
+			return flags, i
+		}
+		if name, value, ok := strings.Cut(arg, "="); ok {
+			flags[name] = value
+			continue
+		}
+		for _, flag := range valueFlags {
+			if arg == flag && i+1 < len(args) {
+				i++
+				flags[flag] = args[i]
+				break
+			}
+		}
+	}
+	return flags, -1
+}
+
+// npmPackage parses an npm package spec such as @scope/name@1.2.3
+func npmPackage(spec string) (PackageRef, bool) {
+	name, requested := spec, ""
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		name, requested = spec[:i], spec[i+1:]
+	}
+	if strings.HasPrefix(name, "@") != strings.Contains(name, "/") {
+		// A path such as user/repo is a GitHub shorthand, not a registry package
+		return PackageRef{}, false
+	}
+	return PackageRef{Ecosystem: ecosystemNpm, Name: name, Requested: requested}, true
+}
+
+// pypiPackage parses a requirement such as mcp-server-fetch==1.0, or uv's
+// mcp-server-fetch@1.0, keeping the specifier as the requested version
+func pypiPackage(spec string) (PackageRef, bool) {
+	m := pypiSpec.FindStringSubmatch(spec)
+	if m == nil {
+		return PackageRef{}, false
+	}
+	requested := strings.ReplaceAll(m[3], " ", "")
+	if version, ok := strings.CutPrefix(requested, "@"); ok {
+		requested = "==" + version
+	}
+	return PackageRef{Ecosystem: ecosystemPyPI, Name: m[1], Requested: requested}, true
+}
+
+// pinned reports whether the command always runs the same version, rather
+// than whichever is the latest when the server starts
+func (p PackageRef) pinned() bool {
+	if p.Ecosystem == ecosystemNpm {
+		return exactNpmVersion.MatchString(p.Requested)
+	}
+	version, ok := strings.CutPrefix(p.Requested, "==")
+	return ok && version != "" && !strings.ContainsAny(version, "*,")
+}
+
+// lookupProvenance queries the registry of a server's package, or returns nil
+// when the server does not run one or --no-provenance is set. A failed lookup
+// is reported in the provenance's Error.
+func lookupProvenance(ctx context.Context, server MCPServer) *Provenance {
+	ref, ok := serverPackage(server)
+	if !ok || noProvenance {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	provenance := &Provenance{PackageRef: ref, Pinned: ref.pinned()}
+	var err error
+	if ref.Ecosystem == ecosystemNpm {
+		err = npmProvenance(ctx, provenance)
+	} else {
+		err = pypiProvenance(ctx, provenance)
+	}
+	if err != nil {
+		provenance.Error = err.Error()
+	}
+	return provenance
+}
+
+// npmProvenance fills in a package's versions, publish date, maintainers and
+// deprecation from the npm registry, and its downloads in the last week
+func npmProvenance(ctx context.Context, p *Provenance) error {
+	var doc struct {
+		DistTags    map[string]string `json:"dist-tags"`
+		Time        map[string]string `json:"time"`
+		Maintainers []struct {
+			Name string `json:"name"`
+		} `json:"maintainers"`
+		Versions map[string]struct {
+			Deprecated string          `json:"deprecated"`
+			Repository json.RawMessage `json:"repository"`
+		} `json:"versions"`
+		Repository json.RawMessage `json:"repository"`
+	}
+	// The registry takes a scoped name with its slash escaped
+	if err := getJSON(ctx, http.DefaultClient, npmRegistryURL+"/"+strings.Replace(p.Name, "/", "%2f", 1), &doc); err != nil {
+		return fmt.Errorf("failed to query the npm registry: %w", err)
+	}
+
+	p.Latest = doc.DistTags["latest"]
+	requested := strings.TrimPrefix(p.Requested, "v")
+	switch {
+	case p.Requested == "":
+		p.Version = p.Latest
+	case doc.DistTags[p.Requested] != "":
+		p.Version = doc.DistTags[p.Requested]
+	default:
+		if _, ok := doc.Versions[requested]; ok {
+			p.Version = requested
+		}
+	}
+	if p.Version != "" {
+		p.Published = doc.Time[p.Version]
+		p.Deprecated = doc.Versions[p.Version].Deprecated
+		p.Repository = repositoryURL(doc.Versions[p.Version].Repository)
+	}
+	if p.Repository == "" {
+		p.Repository = repositoryURL(doc.Repository)
+	}
+	for _, maintainer := range doc.Maintainers {
+		p.Maintainers = append(p.Maintainers, maintainer.Name)
+	}
+
+	var downloads struct {
+		Downloads int `json:"downloads"`
+	}
+	if err := getJSON(ctx, http.DefaultClient, npmDownloadsURL+"/"+p.Name, &downloads); err != nil {
+		logger.Info("download counts unavailable", "package", p.Name, "error", err)
+	} else {
+		p.WeeklyDownloads = &downloads.Downloads
+	}
+	return nil
+}
+
+// repositoryURL reads npm's repository field, either a URL or {type, url}
+func repositoryURL(raw json.RawMessage) string {
+	var repository struct {
+		URL string `json:"url"`
+	}
+	if json.Unmarshal(raw, &repository.URL) != nil {
+		json.Unmarshal(raw, &repository)
+	}
+	u := strings.TrimPrefix(repository.URL, "git+")
+	u = strings.TrimSuffix(u, ".git")
+	if rest, ok := strings.CutPrefix(u, "git://"); ok {
+		u = "https://" + rest
+	}
+	return u
+}
+
+// pypiProvenance fills in a package's versions, upload date, authors and
+// yanking from PyPI, and its downloads in the last week from pypistats.
+// PyPI does not publish who maintains a project, so its author and
+// maintainer fields are shown instead.
+func pypiProvenance(ctx context.Context, p *Provenance) error {
+	var doc struct {
+		Info struct {
+			Version         string            `json:"version"`
+			Author          string            `json:"author"`
+			AuthorEmail     string            `json:"author_email"`
+			Maintainer      string            `json:"maintainer"`
+			MaintainerEmail string            `json:"maintainer_email"`
+			ProjectURLs     map[string]string `json:"project_urls"`
+		} `json:"info"`
+		Releases map[string][]struct {
+			UploadTime   string `json:"upload_time_iso_8601"`
+			Yanked       bool   `json:"yanked"`
+			YankedReason string `json:"yanked_reason"`
+		} `json:"releases"`
+	}
+	if err := getJSON(ctx, http.DefaultClient, pypiURL+"/"+url.PathEscape(p.Name)+"/json", &doc); err != nil {
+		return fmt.Errorf("failed to query PyPI: %w", err)
+	}
+
+	p.Latest = doc.Info.Version
+	if version, ok := strings.CutPrefix(p.Requested, "=="); ok && p.Pinned {
+		if _, ok := doc.Releases[version]; ok {
+			p.Version = version
+		}
+	} else if p.Requested == "" {
+		p.Version = p.Latest
+	}
+	for _, file := range doc.Releases[p.Version] {
+		if p.Published == "" || file.UploadTime < p.Published {
+			p.Published = file.UploadTime
+		}
+		if file.Yanked {
+			p.Deprecated = cmp.Or(file.YankedReason, "yanked")
+		}
+	}
+	for _, person := range []string{
+		cmp.Or(doc.Info.Author, doc.Info.AuthorEmail),
+		cmp.Or(doc.Info.Maintainer, doc.Info.MaintainerEmail),
+	} {
+		if person != "" && !slices.Contains(p.Maintainers, person) {
+			p.Maintainers = append(p.Maintainers, person)
+		}
+	}
+	p.Repository = projectRepository(doc.Info.ProjectURLs)
+
+	var stats struct {
+		Data struct {
+			LastWeek int `json:"last_week"`
+		} `json:"data"`
+	}
+	if err := getJSON(ctx, http.DefaultClient, pypiStatsURL+"/"+strings.ToLower(p.Name)+"/recent", &stats); err != nil {
+		logger.Info("download counts unavailable", "package", p.Name, "error", err)
+	} else {
+		p.WeeklyDownloads = &stats.Data.LastWeek
+	}
+	return nil
+}
+
+// projectRepository picks the source repository among a PyPI project's URLs
+func projectRepository(urls map[string]string) string {
+	labels := make([]string, 0, len(urls))
+	for label := range urls {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, want := range []string{"source", "repository", "code", "github", "homepage"} {
+		for _, label := range labels {
+			if strings.Contains(strings.ToLower(label), want) {
+				return urls[label]
+			}
+		}
+	}
+	return ""
+}
+
+// publishedAge is how long ago a package version was published, or false
+// when its date is unknown
+func (p *Provenance) publishedAge() (time.Duration, bool) {
+	published, err := time.Parse(time.RFC3339, p.Published)
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(published), true
+}
+
+// formatProvenance renders a package on one line, e.g.
+// "npm @scope/server 1.2.3 (latest 1.4.0), published 2025-01-14 (274 days ago), 5120 downloads last week"
+func formatProvenance(p *Provenance) string {
+	name := p.Ecosystem + " " + p.Name
+	switch {
+	case p.Version != "" && p.Latest != "" && p.Version != p.Latest:
+		name += " " + p.Version + " (latest " + p.Latest + ")"
+	case p.Version != "":
+		name += " " + p.Version
+	case p.Requested != "":
+		name += " requested " + p.Requested
+	}
+	parts := []string{name}
+	if !p.Pinned {
+		parts = append(parts, "unpinned")
+	}
+	if age, ok := p.publishedAge(); ok {
+		parts = append(parts, fmt.Sprintf("published %s (%d days ago)", p.Published[:10], int(age.Hours()/24)))
+	}
+	if p.WeeklyDownloads != nil {
+		parts = append(parts, fmt.Sprintf("%d downloads last week", *p.WeeklyDownloads))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// printProvenance prints a server's package provenance under its inspection
+func printProvenance(p *Provenance) {
+	if p == nil {
+		return
+	}
+	fmt.Printf("Package: %s\n", formatProvenance(p))
+	if p.Error != "" {
+		fmt.Printf("  Lookup failed: %s\n", p.Error)
+		return
+	}
+	if len(p.Maintainers) > 0 {
+		fmt.Printf("  Maintainers: %s\n", strings.Join(p.Maintainers, ", "))
+	}
+	if p.Repository != "" {
+		fmt.Printf("  Repository: %s\n", p.Repository)
+	}
+	if p.Deprecated != "" {
+		fmt.Printf("  Deprecated: %s\n", p.Deprecated)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+	"github.com/spf13/cobra"
+)
+
+// Transcript directions
+const (
+	directionToServer = "client->server"
+	directionToClient = "server->client"
+)
+
+// TranscriptEntry is one JSON-RPC message recorded by the proxy
+type TranscriptEntry struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// transcript appends entries to a writer as JSON lines
+type transcript struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func newTranscript(w io.Writer) *transcript {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &transcript{enc: enc}
+}
+
+func (t *transcript) record(direction string, message []byte) {
+	// Keep valid JSON as-is; anything else is recorded as a string
+	raw := json.RawMessage(bytes.TrimSpace(message))
+	if !json.Valid(raw) {
+		raw, _ = json.Marshal(string(message))
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.enc.Encode(TranscriptEntry{Time: time.Now(), Direction: direction, Message: raw}); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing transcript: %v\n", err)
+	}
+}
+
+func newProxyCmd() *cobra.Command {
+	var outFile string
+	proxyCmd := &cobra.Command{
+		Use:   "proxy <server-name>",
+		Short: "Run as a stdio MCP server that forwards to a configured server and records the traffic",
+		Long: `Act as a stdio MCP server that forwards every JSON-RPC message to the
+configured server and relays its replies, recording each message with a
+timestamp and direction to a JSON-lines transcript.
+
+Point an MCP client at the proxy instead of the real server, e.g. in
+.claude.json:
+
+  "linear-debug": {"type": "stdio", "command": "mcpinspect",
+                   "args": ["proxy", "linear-server", "-o", "/tmp/linear.jsonl"]}
+
+The upstream server may be stdio, http or sse. The transcript goes to stderr
+unless --out is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			out := io.Writer(os.Stderr)
+			if outFile != "" {
+				f, err := os.OpenFile(outFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+				if err != nil {
+					return fmt.Errorf("failed to open %s: %w", outFile, err)
+				}
+				defer f.Close()
+				out = f
+			}
+
+			cmd.SilenceUsage = true
+			return runProxy(config, args[0], os.Stdin, os.Stdout, newTranscript(out))
+		},
+	}
+	proxyCmd.Flags().StringVarP(&outFile, "out", "o", "", "append the transcript to this file instead of stderr")
+	return proxyCmd
+}
+
+// runProxy relays messages between the client on in/out and the named server
+// until either side closes
+func runProxy(config *ClaudeConfig, serverName string, in io.Reader, out io.Writer, tr *transcript) error {
+	server, err := findServer(config, serverName)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	upstream, cleanup, err := connectToServer(ctx, server, serverName)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	var outMu sync.Mutex
+	upstreamClosed := make(chan struct{})
+	var closeOnce sync.Once
+
+	upstream.SetMessageHandler(func(_ context.Context, message *transport.BaseJsonRpcMessage) {
+		data, err := json.Marshal(message)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding server message: %v\n", err)
+			return
+		}
+		tr.record(directionToClient, data)
+
+		outMu.Lock()
+		defer outMu.Unlock()
+		out.Write(append(data, '\n'))
+	})
+	upstream.SetErrorHandler(func(err error) {
+		fmt.Fprintf(os.Stderr, "Upstream error: %v\n", err)
+	})
+	upstream.SetCloseHandler(func() {
+		closeOnce.Do(func() { close(upstreamClosed) })
+	})
+
+	if err := upstream.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start transport: %w", err)
+	}
+
+	clientClosed := make(chan error, 1)
+	go func() {
+		reader := bufio.NewReader(in)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				tr.record(directionToServer, line)
+				if message, decodeErr := decodeMessage(line); decodeErr != nil {
+					fmt.Fprintf(os.Stderr, "Ignoring invalid client message: %v\n", decodeErr)
+				} else if sendErr := upstream.Send(ctx, message); sendErr != nil {
+					fmt.Fprintf(os.Stderr, "Error forwarding to %s: %v\n", serverName, sendErr)
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					err = nil
+				}
+				clientClosed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case err := <-clientClosed:
+		return err
+	case <-upstreamClosed:
+		return fmt.Errorf("connection to %s closed", serverName)
+	case <-ctx.Done():
+		return nil
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultRegistryURL is the official MCP server registry
+const defaultRegistryURL = "https://registry.modelcontextprotocol.io"
+
+// registryURL is the --registry queried by the registry commands
+var registryURL string
+
+// RegistryEntry is a server version as the registry lists it
+type RegistryEntry struct {
+	Server RegistryServer `json:"server"`
+	Meta   struct {
+		Official RegistryStatus `json:"io.modelcontextprotocol.registry/official"`
+	} `json:"_meta"`
+}
+
+// RegistryStatus is the registry's own metadata about a server version
+type RegistryStatus struct {
+	Status      string `json:"status,omitempty"` // active, deprecated or deleted
+	PublishedAt string `json:"publishedAt,omitempty"`
+	UpdatedAt   string `json:"updatedAt,omitempty"`
+	IsLatest    bool   `json:"isLatest"`
+}
+
+// RegistryServer is a server.json: how to run a published server
+type RegistryServer struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Version     string `json:"version"`
+	WebsiteURL  string `json:"websiteUrl,omitempty"`
+	Repository  *struct {
+		URL    string `json:"url"`
+		Source string `json:"source,omitempty"`
+	} `json:"repository,omitempty"`
+	Packages []RegistryPackage `json:"packages,omitempty"`
+	Remotes  []RegistryRemote  `json:"remotes,omitempty"`
+}
+
+// RegistryPackage is a package a server can be run from locally
+type RegistryPackage struct {
+	RegistryType    string `json:"registryType"` // npm, pypi, oci, nuget or mcpb
+	RegistryBaseURL string `json:"registryBaseUrl,omitempty"`
+	Identifier      string `json:"identifier"`
+	Version         string `json:"version,omitempty"`
+	RuntimeHint     string `json:"runtimeHint,omitempty"`
+	Transport       struct {
+		Type string `json:"type"`
+		URL  string `json:"url,omitempty"`
+	} `json:"transport"`
+	RuntimeArguments     []RegistryArgument `json:"runtimeArguments,omitempty"`
+	PackageArguments     []RegistryArgument `json:"packageArguments,omitempty"`
+	EnvironmentVariables []RegistryInput    `json:"environmentVariables,omitempty"`
+}
+
+// RegistryRemote is a hosted endpoint of a server
+type RegistryRemote struct {
+	Type    string          `json:"type"` // streamable-http or sse
+	URL     string          `json:"url"`
+	Headers []RegistryInput `json:"headers,omitempty"`
+}
+
+// RegistryInput is a value the user supplies, such as an env var or header
+type RegistryInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	IsRequired  bool   `json:"isRequired,omitempty"`
+	IsSecret    bool   `json:"isSecret,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Value       string `json:"value,omitempty"`
+}
+
+// RegistryArgument is a command-line argument of a package: a positional
+// value, or a named flag with a value
+type RegistryArgument struct {
+	Type       string `json:"type"` // positional or named
+	Name       string `json:"name,omitempty"`
+	Value      string `json:"value,omitempty"`
+	ValueHint  string `json:"valueHint,omitempty"`
+	Default    string `json:"default,omitempty"`
+	IsRequired bool   `json:"isRequired,omitempty"`
+}
+
+// RegistryMatch is a search result with the local servers it is configured as
+type RegistryMatch struct {
+	RegistryServer
+	Status     string   `json:"status,omitempty"`
+	Configured []string `json:"configured"`
+}
+
+func newRegistryCmd() *cobra.Command {
+	registryCmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Query the MCP server registry",
+	}
+	registryCmd.PersistentFlags().StringVar(&registryURL, "registry", defaultRegistryURL, "base URL of the MCP registry or a compatible subregistry")
+	registryCmd.AddCommand(newRegistrySearchCmd(), newRegistryVerifyCmd())
+	return registryCmd
+}
+
+func newRegistrySearchCmd() *cobra.Command {
+	var limit int
+	searchCmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search the registry for servers",
+		Long: `Search the latest versions of the registry's servers by name, and show how
+each can be run: its packages (npm, PyPI, OCI images, ...) and remote URLs.
+The CONFIGURED column names the servers of your config that already run one
+of them, matched by package or URL.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 {
+				return fmt.Errorf("--limit must be at least 1")
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// An unreachable registry is not a usage error
+			cmd.SilenceUsage = true
+
+			ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+			defer cancel()
+			entries, err := searchRegistry(ctx, args[0], limit)
+			if err != nil {
+				return withExitCode(exitConnection, err)
+			}
+
+			configured := aggregateServers(config)
+			matches := make([]RegistryMatch, 0, len(entries))
+			for _, entry := range entries {
+				match := RegistryMatch{RegistryServer: entry.Server, Status: entry.Meta.Official.Status, Configured: []string{}}
+				for _, info := range configured {
+					if registryServerMatches(entry.Server, MCPServer{Command: info.Command, Args: info.Args, URL: info.URL}) {
+						match.Configured = append(match.Configured, info.Name)
+					}
+				}
+				matches = append(matches, match)
+			}
+			return printRegistrySearch(args[0], matches)
+		},
+	}
+	searchCmd.Flags().IntVar(&limit, "limit", 30, "maximum number of servers to show")
+	return searchCmd
+}
+
+// searchRegistry returns the latest versions of the servers matching query,
+// following the registry's cursor until limit servers are found
+func searchRegistry(ctx context.Context, query string, limit int) ([]RegistryEntry, error) {
+	var entries []RegistryEntry
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		params := url.Values{"search": {query}, "version": {"latest"}, "limit": {strconv.Itoa(min(limit, 100))}}
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+		var result struct {
+			Servers  []RegistryEntry `json:"servers"`
+			Metadata struct {
+				NextCursor string `json:"nextCursor"`
+			} `json:"metadata"`
+		}
+		if err := getJSON(ctx, http.DefaultClient, strings.TrimSuffix(registryURL, "/")+"/v0/servers?"+params.Encode(), &result); err != nil {
+			return nil, fmt.Errorf("failed to search the registry: %w", err)
+		}
+		entries = append(entries, result.Servers...)
+		if len(entries) >= limit {
+			return entries[:limit], nil
+		}
+		if result.Metadata.NextCursor == "" {
+			break
+		}
+		cursor = result.Metadata.NextCursor
+	}
+	return entries, nil
+}
+
+// registryServerMatches reports whether a configured server runs one of a
+// registry server's packages or remotes
+func registryServerMatches(entry RegistryServer, server MCPServer) bool {
+	if server.URL != "" {
+		for _, remote := range entry.Remotes {
+			if sameURL(remote.URL, server.URL) {
+				return true
+			}
+		}
+		return false
+	}
+	ref, isPackage := serverPackage(server)
+	for _, pkg := range entry.Packages {
+		switch pkg.RegistryType {
+		case ecosystemNpm:
+			if isPackage && ref.Ecosystem == ecosystemNpm && ref.Name == pkg.Identifier {
+				return true
+			}
+		case ecosystemPyPI:
+			if isPackage && ref.Ecosystem == ecosystemPyPI && normalizePyPIName(ref.Name) == normalizePyPIName(pkg.Identifier) {
+				return true
+			}
+		case "oci":
+			if image := serverImage(server); image != "" && imageName(image) == imageName(pkg.Identifier) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameURL compares server URLs ignoring the case of the host and a trailing slash
+func sameURL(a, b string) bool {
+	ua, errA := url.Parse(strings.TrimSuffix(a, "/"))
+	ub, errB := url.Parse(strings.TrimSuffix(b, "/"))
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host) && ua.Path == ub.Path
+}
+
+// pypiSeparators are the runs of characters PyPI treats as the same in names
+var pypiSeparators = regexp.MustCompile(`[-_.]+`)
+
+// normalizePyPIName compares PyPI project names the way PyPI does: case
+// insensitive, with -, _ and . equivalent
+func normalizePyPIName(name string) string {
+	return pypiSeparators.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// serverImage returns the image of a server run with docker or podman run
+func serverImage(server MCPServer) string {
+	name := commandName(server.Command)
+	if (name != "docker" && name != "podman") || len(server.Args) == 0 || server.Args[0] != "run" {
+		return ""
+	}
+	_, first := scanArgs(server.Args[1:], []string{"-e", "--env", "-v", "--volume", "--name", "--network", "-p", "--publish", "--env-file", "-u", "--user", "-w", "--workdir", "--entrypoint", "--platform", "--mount", "-l", "--label"})
+	if first < 0 {
+		return ""
+	}
+	return server.Args[1+first]
+}
+
+// imageName strips an image reference's tag, digest and default Docker Hub
+// registry, so docker.io/library/node:20 and node compare equal
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	image = strings.TrimPrefix(image, "docker.io/")
+	return strings.TrimPrefix(image, "library/")
+}
+
+// registryTransports lists the transports a registry server can be used over
+func registryTransports(server RegistryServer) []string {
+	var transports []string
+	add := func(transport string) {
+		if transport != "" && !slices.Contains(transports, transport) {
+			transports = append(transports, transport)
+		}
+	}
+	for _, pkg := range server.Packages {
+		add(pkg.Transport.Type)
+	}
+	for _, remote := range server.Remotes {
+		add(remote.Type)
+	}
+	return transports
+}
+
+// registryInstallHints describes how each package and remote of a server is
+// run, e.g. "npx @scope/server@1.0.0" or a remote's URL
+func registryInstallHints(server RegistryServer) []string {
+	var hints []string
+	for _, pkg := range server.Packages {
+		hints = append(hints, packageHint(pkg))
+	}
+	for _, remote := range server.Remotes {
+		hints = append(hints, remote.URL)
+	}
+	return hints
+}
+
+// packageHint is the command that runs a registry package
+func packageHint(pkg RegistryPackage) string {
+	switch pkg.RegistryType {
+	case ecosystemNpm:
+		return withVersion("npx "+pkg.Identifier, "@", pkg.Version)
+	case ecosystemPyPI:
+		return withVersion("uvx "+pkg.Identifier, "==", pkg.Version)
+	case "oci":
+		return "docker run -i " + pkg.Identifier
+	case "nuget":
+		return withVersion("dnx "+pkg.Identifier, "@", pkg.Version)
+	}
+	return pkg.RegistryType + " " + pkg.Identifier
+}
+
+// withVersion appends a package version after its separator, if there is one
+func withVersion(s, separator, version string) string {
+	if version == "" {
+		return s
+	}
+	return s + separator + version
+}
+
+// printRegistrySearch renders the servers found in the registry
+func printRegistrySearch(query string, matches []RegistryMatch) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(matches)
+	case outputCSV:
+		rows := make([][]string, 0, len(matches))
+		for _, m := range matches {
+			rows = append(rows, []string{m.Name, m.Version, strings.Join(registryTransports(m.RegistryServer), ", "),
+				strings.Join(registryInstallHints(m.RegistryServer), ", "), strings.Join(m.Configured, ", "), m.Description})
+		}
+		return writeCSV([]string{"NAME", "VERSION", "TRANSPORT", "INSTALL", "CONFIGURED", "DESCRIPTION"}, rows)
+	}
+
+	configuredCount := 0
+	if len(matches) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tVERSION\tTRANSPORT\tINSTALL\tCONFIGURED\tDESCRIPTION")
+		for _, m := range matches {
+			configured := "-"
+			if len(m.Configured) > 0 {
+				configured = strings.Join(m.Configured, ", ")
+				configuredCount++
+			}
+			version := m.Version
+			if m.Status != "" && m.Status != "active" {
+				version += " (" + m.Status + ")"
+			}
+			// A server with several packages or remotes gets a line for each
+			hints := registryInstallHints(m.RegistryServer)
+			if len(hints) == 0 {
+				hints = []string{"[N/A]"}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", m.Name, version, strings.Join(registryTransports(m.RegistryServer), ", "), hints[0], configured, m.Description)
+			for _, hint := range hints[1:] {
+				fmt.Fprintf(w, "\t\t\t%s\t\t\n", hint)
+			}
+		}
+		w.Flush()
+		fmt.Println()
+	}
+
+	// Print summary
+	fmt.Printf("%d servers found for %q | %d configured\n", len(matches), query, configuredCount)
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	headerFlags  []string
+	extraHeaders map[string]string
+	bearerToken  string
+
+	basicAuthFlag string
+	basicAuth     *BasicAuth
+
+	tlsCertFlag        string
+	tlsKeyFlag         string
+	tlsCAFlag          string
+	insecureSkipVerify bool
+
+	proxyFlag  string
+	socks5Flag string
+)
+
+// tokenEnv names the environment variable with a bearer token for servers
+// whose config sets no Authorization header, for CI jobs without a keychain
+const tokenEnv = "MCPINSPECT_TOKEN"
+
+// parseHeaderFlags turns --header "Name: value" flags into headers sent to
+// every remote server
+func parseHeaderFlags(values []string) (map[string]string, error) {
+	headers := make(map[string]string, len(values))
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid --header %q, expected \"Name: value\"", value)
+		}
+		headers[http.CanonicalHeaderKey(name)] = strings.TrimSpace(v)
+	}
+	return headers, nil
+}
+
+// parseBasicAuth validates a --basic-auth "user:password" value
+func parseBasicAuth(value string) (*BasicAuth, error) {
+	if value == "" {
+		return nil, nil
+	}
+	username, password, ok := strings.Cut(value, ":")
+	if !ok || username == "" {
+		return nil, fmt.Errorf("invalid --basic-auth, expected user:password")
+	}
+	return &BasicAuth{Username: username, Password: password}, nil
+}
+
+// Authorization returns the Basic Authorization header value
+func (b *BasicAuth) Authorization() string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(b.Username+":"+b.Password))
+}
+
+// explicitHeaders are the headers of a server's definition and its basicAuth,
+// overridden by --bearer-token or --basic-auth and then --header flags.
+// MCPINSPECT_TOKEN only applies when nothing else sets Authorization.
+func explicitHeaders(server *MCPServer) map[string]string {
+	headers := make(map[string]string, len(server.Headers)+len(extraHeaders)+1)
+	for key, value := range server.Headers {
+		headers[http.CanonicalHeaderKey(key)] = value
+	}
+	if server.BasicAuth != nil {
+		headers["Authorization"] = server.BasicAuth.Authorization()
+	}
+	token := bearerToken
+	if _, ok := headers["Authorization"]; !ok && token == "" && basicAuth == nil {
+		token = os.Getenv(tokenEnv)
+	}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	if basicAuth != nil {
+		headers["Authorization"] = basicAuth.Authorization()
+	}
+	for key, value := range extraHeaders {
+		headers[key] = value
+	}
+	return headers
+}
+
+// hasAuthHeader reports whether a server's Authorization header is set
+// explicitly, in which case no stored OAuth token is sent or refreshed
+func hasAuthHeader(server *MCPServer) bool {
+	_, ok := explicitHeaders(server)["Authorization"]
+	return ok
+}
+
+// httpClient builds the HTTP client of a remote server, with the TLS and
+// proxy settings of its definition and the flags
+func httpClient(server *MCPServer) (*http.Client, error) {
+	tlsConfig, err := serverTLSConfig(server)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := serverProxy(server)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = proxy
+	return &http.Client{Transport: transport}, nil
+}
+
+// serverProxy returns the proxy selection of a remote server: --proxy or
+// --socks5, else its proxy field, else the HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY variables. An explicit proxy is used for every request, NO_PROXY
+// does not apply.
+func serverProxy(server *MCPServer) (func(*http.Request) (*url.URL, error), error) {
+	raw := server.Proxy
+	if proxyFlag != "" {
+		raw = proxyFlag
+	}
+	if socks5Flag != "" {
+		raw = "socks5://" + socks5Flag
+	}
+	if raw == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := parseProxyURL(raw)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
+// parseProxyURL validates the URL of an HTTP or SOCKS5 proxy
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, expected http://, https:// or socks5://host:port", raw)
+	}
+	return u, nil
+}
+
+// parseSocks5 validates a --socks5 host:port value
+func parseSocks5(value string) error {
+	if _, port, err := net.SplitHostPort(value); err != nil || port == "" {
+		return fmt.Errorf("invalid --socks5 %q, expected host:port", value)
+	}
+	return nil
+}
+
+// proxyFor returns the proxy that requests to a server's URL go through, or
+// nil when they connect directly
+func proxyFor(server *MCPServer, target *url.URL) (*url.URL, error) {
+	proxy, err := serverProxy(server)
+	if err != nil {
+		return nil, err
+	}
+	return proxy(&http.Request{URL: target})
+}
+
+// serverTLSConfig returns the TLS config of a remote server: the CAs of
+// --tls-ca or its tlsCA field are trusted on top of the system's, and the
+// client certificate of --tls-cert or its tlsCert field is presented to
+// servers that require mutual TLS. Without a key file the certificate file
+// must hold the key too.
+func serverTLSConfig(server *MCPServer) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: insecureSkipVerify || server.InsecureSkipVerify}
+
+	// Paths in the definition are relative to its project, flags to our directory
+	var caFile string
+	if server.TLSCA != "" {
+		caFile = serverPath(server, server.TLSCA)
+	}
+	if tlsCAFlag != "" {
+		caFile = tlsCAFlag
+	}
+	if caFile != "" {
+		pool, err := loadCAPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	var certFile, keyFile string
+	if server.TLSCert != "" {
+		certFile = serverPath(server, server.TLSCert)
+	}
+	if server.TLSKey != "" {
+		keyFile = serverPath(server, server.TLSKey)
+	}
+	if tlsCertFlag != "" {
+		certFile, keyFile = tlsCertFlag, tlsKeyFlag
+	}
+	if certFile == "" {
+		return config, nil
+	}
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	config.Certificates = []tls.Certificate{cert}
+	return config, nil
+}
+
+// loadCAPool returns the system's CA pool with the certificates of a PEM file added
+func loadCAPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// serverPath resolves a file named in a server's definition: ~ expands to the
+// home directory and a relative path is resolved against the owning project
+func serverPath(server *MCPServer, path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	if !filepath.IsAbs(path) && server.project != "" {
+		return filepath.Join(server.project, path)
+	}
+	return path
+}
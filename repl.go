@@ -0,0 +1,410 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// replCommands are the commands understood by the REPL, in help order
+var replCommands = []struct {
+	name  string
+	usage string
+	help  string
+}{
+	{"tools", "tools", "list the server's tools"},
+	{"call", "call <tool> [json-arguments]", "call a tool, e.g. call echo {\"text\": \"hi\"}"},
+	{"resources", "resources", "list the server's resources"},
+	{"read", "read <uri>", "print a resource"},
+	{"prompts", "prompts", "list the server's prompts"},
+	{"get", "get <prompt> [key=value ...]", "render a prompt"},
+	{"ping", "ping", "check the server still responds"},
+	{"help", "help", "show this help"},
+	{"exit", "exit", "close the session (also Ctrl+D)"},
+}
+
+// lineReader reads REPL input lines
+type lineReader interface {
+	ReadLine() (string, error)
+}
+
+// terminalReader reads lines with editing, history and tab completion. The
+// terminal is only in raw mode while a line is being read, so command output
+// is printed normally.
+type terminalReader struct {
+	fd       int
+	terminal *term.Terminal
+}
+
+func (r *terminalReader) ReadLine() (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", fmt.Errorf("failed to set terminal mode: %w", err)
+	}
+	defer term.Restore(r.fd, state)
+	return r.terminal.ReadLine()
+}
+
+// scannerReader reads plain lines when stdin is not a terminal, e.g. a script piped in
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerReader) ReadLine() (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// replSession is an interactive session with one connected server
+type replSession struct {
+	ctx    context.Context
+	config *ClaudeConfig
+	conn   *Connection
+
+	// Names offered by tab completion, reloaded after list_changed notifications
+	mu       sync.Mutex
+	names    map[string][]string
+	stale    map[string]bool
+	surfaces []string
+}
+
+// replCompletions maps commands to the surface their first argument is completed from
+var replCompletions = map[string]string{
+	"call": surfaceTools,
+	"read": surfaceResources,
+	"get":  surfacePrompts,
+}
+
+func newReplCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "repl <server-name>",
+		Short: "Open an interactive session with a server",
+		Long: `Connect to a server once and run commands against the open session:
+list tools, resources and prompts, call tools, read resources and render
+prompts without reconnecting (or restarting a stdio server) each time.
+
+When stdin is a terminal, input has line editing, history (up/down arrows)
+and tab completion of commands, tool names, resource URIs and prompt names.
+Commands can also be piped in, one per line. Type "help" for the commands.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// A dropped connection is a result, not a usage error
+			cmd.SilenceUsage = true
+			return runRepl(config, args[0])
+		},
+	}
+}
+
+func runRepl(config *ClaudeConfig, serverName string) error {
+	// The session lives until the user exits, so only individual commands get a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	s := &replSession{
+		ctx:    ctx,
+		config: config,
+		conn:   conn,
+		names:  make(map[string][]string),
+		stale:  make(map[string]bool),
+	}
+
+	var reader lineReader
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		s.watchNames()
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{os.Stdin, os.Stdout}, serverName+"> ")
+		terminal.AutoCompleteCallback = s.complete
+		reader = &terminalReader{fd: fd, terminal: terminal}
+
+		fmt.Printf("Connected to %s (%s). Type \"help\" for commands, Ctrl+D to exit.\n", formatServerInfo(conn.Init), conn.Server.Type)
+	} else {
+		reader = &scannerReader{scanner: bufio.NewScanner(os.Stdin)}
+	}
+
+	for {
+		s.refreshNames()
+
+		line, err := reader.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		done, err := s.execute(strings.TrimSpace(line))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-conn.Session.Done():
+			return fmt.Errorf("connection to %s closed", serverName)
+		default:
+		}
+	}
+}
+
+// execute runs one REPL command line and reports whether the session should end
+func (s *replSession) execute(line string) (bool, error) {
+	if line == "" {
+		return false, nil
+	}
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+
+	ctx := s.ctx
+	switch command {
+	case "exit", "quit":
+		return true, nil
+
+	case "help":
+		for _, c := range replCommands {
+			fmt.Printf("  %-30s %s\n", c.usage, c.help)
+		}
+		return false, nil
+
+	case "tools":
+		result, err := collectInspection(ctx, s.config, s.conn)
+		if err != nil {
+			return false, err
+		}
+		return false, printInspection(s.conn, result)
+
+	case "call":
+		name, rawArgs, _ := strings.Cut(rest, " ")
+		if name == "" {
+			return false, fmt.Errorf("usage: call <tool> [json-arguments]")
+		}
+		var arguments map[string]interface{}
+		if rawArgs = strings.TrimSpace(rawArgs); rawArgs != "" {
+			if err := json.Unmarshal([]byte(rawArgs), &arguments); err != nil {
+				return false, fmt.Errorf("invalid arguments: %w", err)
+			}
+		}
+		// Ctrl+C cancels the call and returns to the prompt instead of exiting
+		callCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		progress := NewProgressRenderer()
+		result, err := callToolWithProgress(callCtx, s.conn, name, arguments, progress.Update)
+		progress.Done()
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return false, fmt.Errorf("call to %s interrupted, sent notifications/cancelled", name)
+			}
+			return false, err
+		}
+		return false, printToolResult(result)
+
+	case "resources":
+		return false, printResourceList(ctx, s.conn)
+
+	case "read":
+		if rest == "" {
+			return false, fmt.Errorf("usage: read <uri>")
+		}
+		return false, printResource(ctx, s.conn, rest, "")
+
+	case "prompts":
+		return false, printPromptList(ctx, s.conn)
+
+	case "get":
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return false, fmt.Errorf("usage: get <prompt> [key=value ...]")
+		}
+		arguments, err := parseKeyValues(fields[1:])
+		if err != nil {
+			return false, err
+		}
+		return false, printPrompt(ctx, s.conn, fields[0], arguments)
+
+	case "ping":
+		if err := s.conn.Client.Ping(ctx); err != nil {
+			return false, fmt.Errorf("ping failed: %w", err)
+		}
+		fmt.Println("pong")
+		return false, nil
+
+	default:
+		return false, fmt.Errorf("unknown command %q, type \"help\" for commands", command)
+	}
+}
+
+// watchNames marks the completion candidates of each surface the server
+// advertises for loading, and again whenever its list_changed notification arrives
+func (s *replSession) watchNames() {
+	capabilities := s.conn.Capabilities()
+	for _, surface := range []string{surfaceTools, surfaceResources, surfacePrompts} {
+		if _, ok := capabilities[surface]; !ok {
+			continue
+		}
+		surface := surface
+		s.surfaces = append(s.surfaces, surface)
+		s.stale[surface] = true
+		s.conn.Session.OnNotification("notifications/"+surface+"/list_changed", func(json.RawMessage) {
+			s.mu.Lock()
+			s.stale[surface] = true
+			s.mu.Unlock()
+		})
+	}
+}
+
+// refreshNames reloads the completion candidates of every surface marked stale
+func (s *replSession) refreshNames() {
+	for _, surface := range s.surfaces {
+		s.mu.Lock()
+		stale := s.stale[surface]
+		s.stale[surface] = false
+		s.mu.Unlock()
+		if !stale {
+			continue
+		}
+
+		names, err := s.listNames(surface)
+		if err != nil {
+			if errors.Is(err, context.Canceled) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Warning: failed to load %s for completion: %v\n", surface, err)
+			continue
+		}
+		sort.Strings(names)
+
+		s.mu.Lock()
+		s.names[surface] = names
+		s.mu.Unlock()
+	}
+}
+
+func (s *replSession) listNames(surface string) ([]string, error) {
+	ctx := s.ctx
+	names := []string{}
+	switch surface {
+	case surfaceTools:
+		tools, err := listTools(ctx, s.conn)
+		if err != nil {
+			return nil, err
+		}
+		for _, tool := range tools {
+			names = append(names, tool.Name)
+		}
+	case surfaceResources:
+		resources, err := s.conn.Client.ListResources(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources.Resources {
+			names = append(names, resource.Uri)
+		}
+	case surfacePrompts:
+		prompts, err := s.conn.Client.ListPrompts(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, prompt := range prompts.Prompts {
+			names = append(names, prompt.Name)
+		}
+	}
+	return names, nil
+}
+
+// complete is the terminal's tab completion callback. It completes the
+// command name, or the first argument of call, read and get, up to the
+// longest prefix shared by all candidates.
+func (s *replSession) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+
+	before := line[:pos]
+	fields := strings.Fields(before)
+	trailingSpace := strings.HasSuffix(before, " ")
+
+	var word string
+	var candidates []string
+	switch {
+	case len(fields) == 0 || (len(fields) == 1 && !trailingSpace):
+		word = strings.TrimLeft(before, " ")
+		for _, c := range replCommands {
+			candidates = append(candidates, c.name)
+		}
+	case (len(fields) == 1 && trailingSpace) || (len(fields) == 2 && !trailingSpace):
+		surface, ok := replCompletions[fields[0]]
+		if !ok {
+			return "", 0, false
+		}
+		if len(fields) == 2 {
+			word = fields[1]
+		}
+		s.mu.Lock()
+		candidates = s.names[surface]
+		s.mu.Unlock()
+	default:
+		return "", 0, false
+	}
+
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			matches = append(matches, candidate)
+		}
+	}
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	completion := commonPrefix(matches)
+	if len(matches) == 1 {
+		completion += " "
+	}
+	if completion == word {
+		return "", 0, false
+	}
+
+	newLine := before[:len(before)-len(word)] + completion + line[pos:]
+	return newLine, pos - len(word) + len(completion), true
+}
+
+// commonPrefix returns the longest prefix shared by all strings
+func commonPrefix(values []string) string {
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
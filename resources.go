@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/spf13/cobra"
+)
+
+// ResourcesResult is the structured result of listing a server's resources
+type ResourcesResult struct {
+	ServerHeader
+	Resources []*mcp.ResourceSchema `json:"resources"`
+}
+
+// ResourceTemplate describes a family of resources addressed by an RFC 6570 URI template
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplatesResult is the structured result of listing a server's resource templates
+type ResourceTemplatesResult struct {
+	ServerHeader
+	ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+}
+
+// uriTemplateExpression matches one {expression} of a URI template
+var uriTemplateExpression = regexp.MustCompile(`\{([^}]*)\}`)
+
+// templateVariables returns the variable names of a URI template in order,
+// without operators such as "+" or "?" and modifiers such as "*" or ":3"
+func templateVariables(template string) []string {
+	variables := []string{}
+	for _, match := range uriTemplateExpression.FindAllStringSubmatch(template, -1) {
+		expression := strings.TrimLeft(match[1], "+#./;?&=,!@|")
+		for _, spec := range strings.Split(expression, ",") {
+			name := strings.TrimSuffix(spec, "*")
+			if i := strings.IndexByte(name, ':'); i >= 0 {
+				name = name[:i]
+			}
+			if name != "" {
+				variables = append(variables, name)
+			}
+		}
+	}
+	return variables
+}
+
+func newResourcesCmd() *cobra.Command {
+	resourcesCmd := &cobra.Command{
+		Use:   "resources",
+		Short: "Inspect resources exposed by an MCP server",
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list <server-name>",
+		Short: "List the resources a server exposes",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return listResources(config, args[0])
+		},
+	}
+
+	var outFile string
+	readCmd := &cobra.Command{
+		Use:   "read <server-name> <uri>",
+		Short: "Read a resource and print or save its contents",
+		Long: `Read a resource from a server.
+
+Text contents are printed to stdout. Binary (blob) contents are decoded and
+must be saved with --out, since they cannot be printed safely.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return readResource(config, args[0], args[1], outFile)
+		},
+	}
+	readCmd.Flags().StringVarP(&outFile, "out", "o", "", "write the resource contents to this file")
+
+	templatesCmd := &cobra.Command{
+		Use:   "templates <server-name>",
+		Short: "List the resource templates a server exposes",
+		Long: `List a server's resource templates: URI templates describing families of
+resources, such as file:///{path}, along with the variables each template
+expects. Fill in the variables and pass the URI to "resources read".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return listResourceTemplates(config, args[0])
+		},
+	}
+
+	resourcesCmd.AddCommand(listCmd, readCmd, templatesCmd)
+	return resourcesCmd
+}
+
+func listResources(config *ClaudeConfig, serverName string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return printResourceList(ctx, conn)
+}
+
+// printResourceList lists a connected server's resources in the selected output format
+func printResourceList(ctx context.Context, conn *Connection) error {
+	resources, err := conn.Client.ListResources(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	// Sort resources by URI
+	sort.Slice(resources.Resources, func(i, j int) bool {
+		return resources.Resources[i].Uri < resources.Resources[j].Uri
+	})
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(ResourcesResult{
+			ServerHeader: newServerHeader(conn),
+			Resources:    resources.Resources,
+		})
+	case outputCSV:
+		rows := make([][]string, 0, len(resources.Resources))
+		for _, resource := range resources.Resources {
+			desc := ""
+			if resource.Description != nil {
+				desc = *resource.Description
+			}
+			rows = append(rows, []string{conn.Name, resource.Uri, resource.Name, mimeTypeOrDefault(resource.MimeType, ""), desc})
+		}
+		return writeCSV([]string{"SERVER", "URI", "NAME", "MIME TYPE", "DESCRIPTION"}, rows)
+	}
+
+	// Print resources table
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "URI\tNAME\tMIME TYPE\tDESCRIPTION")
+
+	for _, resource := range resources.Resources {
+		mimeType := mimeTypeOrDefault(resource.MimeType, "[N/A]")
+		desc := ""
+		if resource.Description != nil {
+			desc = *resource.Description
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", resource.Uri, resource.Name, mimeType, desc)
+	}
+
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d resources | %s | %s\n", len(resources.Resources), conn.Server.Type, formatServerInfo(conn.Init))
+
+	return nil
+}
+
+func listResourceTemplates(config *ClaudeConfig, serverName string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return printResourceTemplates(ctx, conn)
+}
+
+// fetchResourceTemplates lists a connected server's resource templates, sorted by URI template
+func fetchResourceTemplates(ctx context.Context, conn *Connection) ([]ResourceTemplate, error) {
+	templates := []ResourceTemplate{}
+	err := paginate(ctx, conn, "resources/templates/list", func(raw json.RawMessage) (string, error) {
+		var result struct {
+			ResourceTemplates []ResourceTemplate `json:"resourceTemplates"`
+			NextCursor        string             `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return "", fmt.Errorf("failed to decode resource templates: %w", err)
+		}
+		templates = append(templates, result.ResourceTemplates...)
+		return result.NextCursor, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort templates by URI template
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].URITemplate < templates[j].URITemplate
+	})
+	return templates, nil
+}
+
+// printResourceTemplates lists a connected server's resource templates in the selected output format
+func printResourceTemplates(ctx context.Context, conn *Connection) error {
+	templates, err := fetchResourceTemplates(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(ResourceTemplatesResult{
+			ServerHeader:      newServerHeader(conn),
+			ResourceTemplates: templates,
+		})
+	case outputCSV:
+		rows := make([][]string, 0, len(templates))
+		for _, template := range templates {
+			rows = append(rows, []string{conn.Name, template.URITemplate, template.Name,
+				strings.Join(templateVariables(template.URITemplate), ", "), template.MimeType, template.Description})
+		}
+		return writeCSV([]string{"SERVER", "URI TEMPLATE", "NAME", "VARIABLES", "MIME TYPE", "DESCRIPTION"}, rows)
+	}
+
+	// Print templates table
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "URI TEMPLATE\tNAME\tVARIABLES\tMIME TYPE\tDESCRIPTION")
+
+	for _, template := range templates {
+		variables := "[N/A]"
+		if names := templateVariables(template.URITemplate); len(names) > 0 {
+			variables = strings.Join(names, ", ")
+		}
+		mimeType := template.MimeType
+		if mimeType == "" {
+			mimeType = "[N/A]"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", template.URITemplate, template.Name, variables, mimeType, template.Description)
+	}
+
+	w.Flush()
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d resource templates | %s | %s\n", len(templates), conn.Server.Type, formatServerInfo(conn.Init))
+
+	return nil
+}
+
+func readResource(config *ClaudeConfig, serverName, uri, outFile string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return printResource(ctx, conn, uri, outFile)
+}
+
+// printResource reads a resource and prints it, or saves it to outFile when set
+func printResource(ctx context.Context, conn *Connection, uri, outFile string) error {
+	resp, err := conn.Client.ReadResource(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to read resource: %w", err)
+	}
+
+	if outputFormat == outputJSON && outFile == "" {
+		return writeJSON(resp)
+	}
+
+	if len(resp.Contents) == 0 {
+		fmt.Println("Resource has no contents.")
+		return nil
+	}
+
+	for i, content := range resp.Contents {
+		data, mimeType, isBlob, err := decodeResourceContents(content)
+		if err != nil {
+			return err
+		}
+
+		if outFile != "" {
+			path := numberedPath(outFile, i, len(resp.Contents))
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Fprintf(os.Stderr, "Saved %d bytes (%s) to %s\n", len(data), mimeType, path)
+			continue
+		}
+
+		if isBlob {
+			fmt.Printf("[binary content: %s, %d bytes; use --out to save it]\n", mimeType, len(data))
+			continue
+		}
+
+		fmt.Print(string(data))
+		if !strings.HasSuffix(string(data), "\n") {
+			fmt.Println()
+		}
+	}
+
+	return nil
+}
+
+// decodeResourceContents returns the raw bytes of a resource content entry,
+// base64-decoding blobs
+func decodeResourceContents(content *mcp.EmbeddedResource) ([]byte, string, bool, error) {
+	if content.BlobResourceContents != nil {
+		blob := content.BlobResourceContents
+		data, err := base64.StdEncoding.DecodeString(blob.Blob)
+		if err != nil {
+			return nil, "", false, fmt.Errorf("failed to decode blob for %s: %w", blob.Uri, err)
+		}
+		return data, mimeTypeOrDefault(blob.MimeType, "application/octet-stream"), true, nil
+	}
+
+	if content.TextResourceContents != nil {
+		text := content.TextResourceContents
+		return []byte(text.Text), mimeTypeOrDefault(text.MimeType, "text/plain"), false, nil
+	}
+
+	return nil, "", false, fmt.Errorf("unsupported resource content")
+}
+
+func mimeTypeOrDefault(mimeType *string, fallback string) string {
+	if mimeType == nil || *mimeType == "" {
+		return fallback
+	}
+	return *mimeType
+}
+
+// numberedPath suffixes the file name with an index when a resource has several contents
+func numberedPath(path string, index, total int) string {
+	if total <= 1 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), index+1, ext)
+}
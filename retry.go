@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRetryBackoff caps the doubling delay between retries
+const maxRetryBackoff = 30 * time.Second
+
+var retries int
+var retryBackoff time.Duration
+
+func validateRetries() error {
+	if retries < 0 {
+		return fmt.Errorf("--retries must not be negative")
+	}
+	if retryBackoff <= 0 {
+		return fmt.Errorf("--retry-backoff must be positive")
+	}
+	return nil
+}
+
+// statusError is an HTTP response with a status a remote transport did not expect
+type statusError struct {
+	what   string
+	status int
+	body   string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: %s (status: %d)", e.what, e.body, e.status)
+}
+
+// sseSetupError is a legacy SSE stream that could not be opened or sent no
+// endpoint event
+type sseSetupError struct {
+	err error
+}
+
+func (e *sseSetupError) Error() string {
+	return "failed to start SSE transport: " + e.err.Error()
+}
+
+func (e *sseSetupError) Unwrap() error {
+	return e.err
+}
+
+// retryError is an operation that failed on every attempt, keeping what
+// each attempt ran into
+type retryError struct {
+	attempts []error
+}
+
+func (e *retryError) Error() string {
+	last := len(e.attempts) - 1
+	earlier := make([]string, last)
+	for i, err := range e.attempts[:last] {
+		earlier[i] = fmt.Sprintf("#%d %v", i+1, err)
+	}
+	return fmt.Sprintf("%v (gave up after %d attempts; earlier: %s)", e.attempts[last], len(e.attempts), strings.Join(earlier, "; "))
+}
+
+func (e *retryError) Unwrap() error {
+	return e.attempts[len(e.attempts)-1]
+}
+
+// isTransient reports whether a failure may go away on its own: a connection
+// reset, a 502 or 503 from a gateway or an overloaded server, or an SSE stream
+// that could not be set up. Other HTTP statuses, such as 401, are final.
+func isTransient(err error) bool {
+	var status *statusError
+	if errors.As(err, &status) {
+		return status.status == http.StatusBadGateway || status.status == http.StatusServiceUnavailable
+	}
+	var setup *sseSetupError
+	if errors.As(err, &setup) {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// withRetries runs op until it succeeds, fails for good or --retries is used
+// up, waiting --retry-backoff before the first retry and twice as long before
+// each next one. what names the operation in the warnings.
+func withRetries(ctx context.Context, what string, op func() error) error {
+	var attempts []error
+	delay := retryBackoff
+	for {
+		start := time.Now()
+		err := op()
+		if retries > 0 {
+			logger.Info("attempt finished", "operation", what, "attempt", len(attempts)+1, "elapsed", time.Since(start), "error", err)
+		}
+		if err == nil {
+			return nil
+		}
+		attempts = append(attempts, err)
+		if len(attempts) > retries || !isTransient(err) || ctx.Err() != nil {
+			break
+		}
+
+		fmt.Fprintf(os.Stderr, "Warning: %s failed (%v), retrying in %s (attempt %d of %d)\n", what, err, delay, len(attempts)+1, retries+1)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			attempts = append(attempts, ctx.Err())
+			return &retryError{attempts: attempts}
+		}
+		delay = min(delay*2, maxRetryBackoff)
+	}
+
+	if len(attempts) == 1 {
+		return attempts[0]
+	}
+	return &retryError{attempts: attempts}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+// Risk classes of tools, from least to most dangerous
+const (
+	riskReadOnly    = "read-only"
+	riskUnknown     = "unknown"
+	riskWrite       = "write"
+	riskNetwork     = "network"
+	riskDestructive = "destructive"
+	riskExec        = "exec"
+)
+
+// riskLevels orders the risk classes; a tool takes the highest its signals reach
+var riskLevels = []string{riskReadOnly, riskUnknown, riskWrite, riskNetwork, riskDestructive, riskExec}
+
+// riskNameWords classifies the words of a tool's name, such as delete in delete_file
+var riskNameWords = map[string]string{
+	"get": riskReadOnly, "list": riskReadOnly, "read": riskReadOnly, "search": riskReadOnly, "find": riskReadOnly,
+	"query": riskReadOnly, "describe": riskReadOnly, "show": riskReadOnly, "view": riskReadOnly, "lookup": riskReadOnly,
+	"count": riskReadOnly, "status": riskReadOnly, "info": riskReadOnly, "inspect": riskReadOnly, "check": riskReadOnly,
+
+	"write": riskWrite, "create": riskWrite, "update": riskWrite, "edit": riskWrite, "set": riskWrite, "put": riskWrite,
+	"patch": riskWrite, "insert": riskWrite, "add": riskWrite, "save": riskWrite, "move": riskWrite, "rename": riskWrite,
+	"commit": riskWrite, "push": riskWrite, "merge": riskWrite, "modify": riskWrite, "append": riskWrite, "apply": riskWrite,
+	"install": riskWrite, "deploy": riskWrite, "publish": riskWrite, "close": riskWrite, "archive": riskWrite, "upsert": riskWrite,
+
+	"fetch": riskNetwork, "http": riskNetwork, "request": riskNetwork, "url": riskNetwork, "download": riskNetwork,
+	"upload": riskNetwork, "browse": riskNetwork, "navigate": riskNetwork, "curl": riskNetwork, "web": riskNetwork,
+	"scrape": riskNetwork, "crawl": riskNetwork, "webhook": riskNetwork, "send": riskNetwork, "post": riskNetwork, "email": riskNetwork,
+
+	"delete": riskDestructive, "remove": riskDestructive, "rm": riskDestructive, "drop": riskDestructive,
+	"destroy": riskDestructive, "purge": riskDestructive, "truncate": riskDestructive, "kill": riskDestructive,
+	"terminate": riskDestructive, "wipe": riskDestructive, "erase": riskDestructive, "reset": riskDestructive,
+	"revoke": riskDestructive, "uninstall": riskDestructive, "overwrite": riskDestructive, "force": riskDestructive,
+
+	"exec": riskExec, "execute": riskExec, "run": riskExec, "shell": riskExec, "bash": riskExec, "sh": riskExec,
+	"cmd": riskExec, "command": riskExec, "terminal": riskExec, "eval": riskExec, "script": riskExec,
+	"spawn": riskExec, "subprocess": riskExec, "powershell": riskExec, "python": riskExec,
+}
+
+// riskSchemaWords classifies the input properties of a tool, such as a command
+// to run or a URL to fetch, which say more than the tool's name
+var riskSchemaWords = map[string]string{
+	"command": riskExec, "cmd": riskExec, "script": riskExec, "code": riskExec, "shell": riskExec,
+	"url": riskNetwork, "uri": riskNetwork, "endpoint": riskNetwork, "host": riskNetwork, "hostname": riskNetwork, "webhook": riskNetwork,
+	"sql": riskWrite, "content": riskWrite, "contents": riskWrite, "body": riskWrite, "patch": riskWrite, "diff": riskWrite,
+}
+
+// ToolRisk is the risk class of one tool and the signals it is based on
+type ToolRisk struct {
+	Name    string   `json:"name"`
+	Risk    string   `json:"risk"`
+	Reasons []string `json:"reasons"`
+	Hints   []string `json:"hints,omitempty"`
+}
+
+// ServerRisk summarizes the risk of a server's tools. Hash identifies the
+// reviewed tool definitions, like a pin, so a sign-off applies to them only.
+type ServerRisk struct {
+	ServerHeader
+	Hash    string         `json:"hash"`
+	Summary map[string]int `json:"summary"`
+	Tools   []ToolRisk     `json:"tools"`
+}
+
+// RiskReport is the result of classifying the tools of one or more servers
+type RiskReport struct {
+	Servers []ServerRisk      `json:"servers"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+func newRiskCmd() *cobra.Command {
+	var all bool
+	riskCmd := &cobra.Command{
+		Use:   "risk [server-name]",
+		Short: "Classify a server's tools by risk for a security review",
+		Long: `Connect to a server and classify each of its tools, from least to most
+dangerous, as read-only, unknown, write, network, destructive or exec:
+
+  annotations  readOnlyHint, destructiveHint and openWorldHint, as declared
+  name         words such as get, create, fetch, delete or run in the tool name
+  schema       input properties such as command, script, url or content
+
+A tool takes the highest class any signal gives it, and each class lists the
+signals behind it. A readOnlyHint contradicted by the name or schema is
+flagged. Every server gets a summary of its classes with a hash of its tool
+definitions, so a review signed off on applies to exactly those tools.
+
+Use --all to classify every configured server.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either a server name or --all")
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			var names []string
+			if all {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			} else {
+				names = args
+			}
+			// Unreachable servers are a result, not a usage error
+			cmd.SilenceUsage = true
+			return classifyServers(config, names)
+		},
+	}
+	riskCmd.Flags().BoolVar(&all, "all", false, "classify every configured server")
+	return riskCmd
+}
+
+func classifyServers(config *ClaudeConfig, names []string) error {
+	results := make([]*ServerRisk, len(names))
+	errs := make([]error, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		results[i], errs[i] = classifyServer(config, names[i])
+	})
+
+	report := &RiskReport{Servers: []ServerRisk{}}
+	var unreachable []error
+	for i, name := range names {
+		if errs[i] != nil {
+			unreachable = append(unreachable, errs[i])
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = errs[i].Error()
+			continue
+		}
+		report.Servers = append(report.Servers, *results[i])
+	}
+
+	if err := printRisk(report); err != nil {
+		return err
+	}
+	if len(unreachable) > 0 && failsOn(failUnreachable, true) {
+		return withExitCode(failureExitCode(unreachable), fmt.Errorf("%d of %d servers unreachable", len(unreachable), len(names)))
+	}
+	return nil
+}
+
+// classifyServer connects to a server and classifies its tools
+func classifyServer(config *ClaudeConfig, serverName string) (*ServerRisk, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	tools, err := listTools(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ServerRisk{
+		ServerHeader: newServerHeader(conn),
+		Hash:         toolsHash(pinnedTools(tools)),
+		Summary:      make(map[string]int),
+		Tools:        make([]ToolRisk, 0, len(tools)),
+	}
+	for _, tool := range tools {
+		risk := classifyTool(tool)
+		result.Summary[risk.Risk]++
+		result.Tools = append(result.Tools, risk)
+	}
+	return result, nil
+}
+
+// classifyTool gives a tool the highest risk class of its annotations, name
+// and input properties
+func classifyTool(tool ToolInfo) ToolRisk {
+	risk := ToolRisk{Name: tool.Name, Risk: riskUnknown, Reasons: []string{}, Hints: toolHints(tool.Annotations)}
+	level := riskLevel(riskUnknown)
+	readOnly := false
+	signal := func(class, reason string) {
+		risk.Reasons = append(risk.Reasons, reason)
+		if l := riskLevel(class); l > level || (risk.Risk == riskUnknown && class == riskReadOnly) {
+			risk.Risk, level = class, l
+		}
+	}
+
+	if value, ok := tool.Annotations["readOnlyHint"].(bool); ok && value {
+		readOnly = true
+		signal(riskReadOnly, "readOnlyHint")
+	}
+	if value, ok := tool.Annotations["destructiveHint"].(bool); ok && value && !readOnly {
+		signal(riskDestructive, "destructiveHint")
+	}
+	if value, ok := tool.Annotations["openWorldHint"].(bool); ok && value {
+		signal(riskNetwork, "openWorldHint")
+	}
+
+	seen := make(map[string]bool)
+	for _, word := range nameWords(tool.Name) {
+		if class, ok := riskNameWords[word]; ok && !seen[word] {
+			seen[word] = true
+			signal(class, fmt.Sprintf("name %q", word))
+		}
+	}
+
+	properties, _ := schemaProperties(tool.InputSchema)
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if class, ok := riskSchemaWords[strings.ToLower(name)]; ok {
+			signal(class, fmt.Sprintf("input %q", name))
+		}
+	}
+
+	if readOnly && level > riskLevel(riskReadOnly) {
+		risk.Reasons = append(risk.Reasons, "readOnlyHint contradicted")
+	}
+	return risk
+}
+
+// riskLevel returns the rank of a risk class in riskLevels
+func riskLevel(class string) int {
+	for i, c := range riskLevels {
+		if c == class {
+			return i
+		}
+	}
+	return -1
+}
+
+// nameWords splits a tool name such as runShellCommand, HTTPRequest or
+// delete_file into lowercase words at separators and case changes
+func nameWords(name string) []string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	flush := func(end int) {
+		if end > start {
+			words = append(words, strings.ToLower(string(runes[start:end])))
+		}
+	}
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush(i)
+			start = i + 1
+		case i > start && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			flush(i)
+			start = i
+		}
+	}
+	flush(len(runes))
+	return words
+}
+
+// schemaProperties returns the top-level properties of a JSON schema
+func schemaProperties(schema interface{}) (map[string]interface{}, bool) {
+	object, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	properties, ok := object["properties"].(map[string]interface{})
+	return properties, ok
+}
+
+func printRisk(report *RiskReport) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(report)
+	case outputCSV:
+		var rows [][]string
+		for _, server := range report.Servers {
+			for _, tool := range server.Tools {
+				rows = append(rows, []string{server.Name, tool.Name, tool.Risk, strings.Join(tool.Reasons, "; "), strings.Join(tool.Hints, " ")})
+			}
+		}
+		return writeCSV([]string{"SERVER", "TOOL", "RISK", "REASONS", "HINTS"}, rows)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SERVER\tTOOL\t%s\tREASONS\n", paint("RISK", ansiDefault))
+	for _, server := range report.Servers {
+		for _, tool := range server.Tools {
+			reasons := strings.Join(tool.Reasons, ", ")
+			if reasons == "" {
+				reasons = "[N/A]"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", server.Name, tool.Name, paintStatus(tool.Risk, tool.Risk), reasons)
+		}
+	}
+	w.Flush()
+
+	// Print the per-server summary to sign off on
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "SERVER\tVERSION\tTOOLS\t%s\tHASH\n", strings.ToUpper(strings.Join(riskLevels, "\t")))
+	for _, server := range report.Servers {
+		counts := make([]string, len(riskLevels))
+		for i, class := range riskLevels {
+			counts[i] = fmt.Sprint(server.Summary[class])
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", server.Name, server.ServerInfo.Version, len(server.Tools), strings.Join(counts, "\t"), shortHash(server.Hash))
+	}
+	w.Flush()
+
+	names := make([]string, 0, len(report.Errors))
+	for name := range report.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "Warning: %s not classified: %s\n", name, report.Errors[name])
+	}
+	return nil
+}
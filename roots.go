@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	rootPaths   []string
+	clientRoots []Root
+)
+
+// Root is one entry of a roots/list result
+type Root struct {
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// parseRoots turns --root values into roots. Paths are made absolute and
+// converted to file:// URIs; values that already are URIs are kept as is.
+func parseRoots(values []string) ([]Root, error) {
+	roots := make([]Root, 0, len(values))
+	for _, value := range values {
+		if strings.Contains(value, "://") {
+			roots = append(roots, Root{URI: value})
+			continue
+		}
+		abs, err := filepath.Abs(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --root %q: %w", value, err)
+		}
+		uri := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+		roots = append(roots, Root{URI: uri.String(), Name: filepath.Base(abs)})
+	}
+	return roots, nil
+}
+
+// enableRoots advertises the roots capability and answers the server's
+// roots/list requests with the given roots
+func enableRoots(session *SessionTransport, roots []Root) {
+	session.SetCapability("roots", map[string]interface{}{"listChanged": false})
+	session.HandleRequest("roots/list", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return map[string]interface{}{"roots": roots}, nil
+	})
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func newRPCCmd() *cobra.Command {
+	var params string
+	rpcCmd := &cobra.Command{
+		Use:   "rpc <server-name> <method>",
+		Short: "Send an arbitrary JSON-RPC request and print the raw result",
+		Long: `Initialize a session with a server, send one JSON-RPC request with the given
+method and params, and print the raw result as JSON. Useful for experimental
+or vendor-specific methods mcpinspect does not model.
+
+Examples:
+  mcpinspect rpc my-server tools/list --params '{"cursor": "abc"}'
+  mcpinspect rpc my-server x-vendor/status`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var rawParams json.RawMessage
+			if params != "" {
+				if !json.Valid([]byte(params)) {
+					return fmt.Errorf("--params is not valid JSON")
+				}
+				rawParams = json.RawMessage(params)
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// Error responses are a result, not a usage error
+			cmd.SilenceUsage = true
+			return sendRPC(config, args[0], args[1], rawParams)
+		},
+	}
+	rpcCmd.Flags().StringVar(&params, "params", "", "request params as a JSON object or array")
+	return rpcCmd
+}
+
+func sendRPC(config *ClaudeConfig, serverName, method string, params json.RawMessage) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var requestParams interface{}
+	if params != nil {
+		requestParams = params
+	}
+	result, err := conn.Session.Request(ctx, method, requestParams)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		// Print the error object so its data can be inspected like a result
+		writeJSON(rpcErr)
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	// Indent the raw result rather than decoding it, so key order is preserved
+	var out bytes.Buffer
+	if err := json.Indent(&out, result, "", "  "); err != nil {
+		return fmt.Errorf("failed to format result: %w", err)
+	}
+	out.WriteByte('\n')
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
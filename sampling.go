@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// Sampling modes selected with --sampling
+const (
+	samplingOff         = "off"
+	samplingInteractive = "interactive"
+	samplingAuto        = "auto"
+)
+
+// samplingModel is reported as the model that produced sampling responses
+const samplingModel = "mcpinspect"
+
+var (
+	samplingMode     string
+	samplingResponse string
+)
+
+// promptMu serializes questions to the user when several server requests arrive at once
+var promptMu sync.Mutex
+
+// SamplingMessage is one message of a sampling/createMessage request
+type SamplingMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// SamplingRequest is the params of a sampling/createMessage request
+type SamplingRequest struct {
+	Messages      []SamplingMessage `json:"messages"`
+	SystemPrompt  string            `json:"systemPrompt,omitempty"`
+	MaxTokens     int               `json:"maxTokens"`
+	Temperature   *float64          `json:"temperature,omitempty"`
+	StopSequences []string          `json:"stopSequences,omitempty"`
+}
+
+// SamplingResult is the client's answer to a sampling/createMessage request
+type SamplingResult struct {
+	Role       string       `json:"role"`
+	Content    ContentBlock `json:"content"`
+	Model      string       `json:"model"`
+	StopReason string       `json:"stopReason"`
+}
+
+// validateSampling checks the --sampling flag
+func validateSampling() error {
+	switch samplingMode {
+	case samplingOff, samplingAuto:
+		return nil
+	case samplingInteractive:
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return fmt.Errorf("--sampling interactive needs a terminal on stdin, use --sampling auto instead")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid --sampling %q, expected off, interactive or auto", samplingMode)
+	}
+}
+
+// enableSampling advertises the sampling capability and answers the server's
+// sampling/createMessage requests according to --sampling
+func enableSampling(session *SessionTransport, serverName string) {
+	session.SetCapability("sampling", map[string]interface{}{})
+	session.HandleRequest("sampling/createMessage", func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var request SamplingRequest
+		if err := json.Unmarshal(params, &request); err != nil {
+			return nil, &RPCError{Code: -32602, Message: fmt.Sprintf("invalid sampling request: %v", err)}
+		}
+
+		text := samplingResponse
+		if samplingMode == samplingInteractive {
+			reply, err := askSampling(serverName, request)
+			if err != nil {
+				return nil, err
+			}
+			if reply == "" {
+				return nil, &RPCError{Code: -1, Message: "User rejected sampling request"}
+			}
+			text = reply
+		} else {
+			fmt.Fprintf(os.Stderr, "Answered sampling/createMessage from %s with a canned response\n", serverName)
+		}
+
+		return SamplingResult{
+			Role:       "assistant",
+			Content:    ContentBlock{Type: "text", Text: text},
+			Model:      samplingModel,
+			StopReason: "endTurn",
+		}, nil
+	})
+}
+
+// askSampling shows a sampling request on stderr and reads the reply from
+// stdin. An empty reply rejects the request.
+func askSampling(serverName string, request SamplingRequest) (string, error) {
+	promptMu.Lock()
+	defer promptMu.Unlock()
+
+	fmt.Fprintf(os.Stderr, "\nSampling request from %s (max %d tokens):\n", serverName, request.MaxTokens)
+	if request.SystemPrompt != "" {
+		fmt.Fprintf(os.Stderr, "  system:\n%s\n", indentBlock(indentBlock(request.SystemPrompt)))
+	}
+	for _, message := range request.Messages {
+		for _, block := range decodeContentBlocks(message.Content) {
+			fmt.Fprintf(os.Stderr, "  %s:\n%s\n", message.Role, indentBlock(indentBlock(formatContent(block))))
+		}
+	}
+	return promptLine("Reply (empty to reject): ")
+}
+
+// promptLine asks the user a question on stderr and reads one line from stdin
+func promptLine(question string) (string, error) {
+	fmt.Fprint(os.Stderr, question)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read answer: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// decodeContentBlocks decodes message content, which is a single block or an array of blocks
+func decodeContentBlocks(raw json.RawMessage) []ContentBlock {
+	var blocks []ContentBlock
+	if err := json.Unmarshal(raw, &blocks); err == nil {
+		return blocks
+	}
+	var block ContentBlock
+	if err := json.Unmarshal(raw, &block); err != nil {
+		return nil
+	}
+	return []ContentBlock{block}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// sandbox runs stdio servers away from the user's environment, credentials
+// and files, to probe untrusted servers
+var sandbox bool
+
+// sandboxNet keeps the network of sandboxed servers, e.g. for npx or uvx to
+// download the server's package
+var sandboxNet bool
+
+// sandboxEnvKeep are the variables of our environment a sandboxed server still gets
+var sandboxEnvKeep = []string{"PATH", "LANG", "LC_ALL", "LC_CTYPE", "TERM", "TZ"}
+
+// sandboxHiddenPaths are the files and directories under the home directory
+// holding credentials, which sandboxed servers cannot read
+var sandboxHiddenPaths = []string{
+	".ssh", ".aws", ".azure", ".gnupg", ".kube", ".docker", ".config", ".local/share/keyrings",
+	".claude", ".claude.json", ".cursor", ".netrc", ".npmrc", ".pypirc", ".git-credentials",
+	"Library/Keychains", "Library/Application Support", "Library/Cookies",
+}
+
+// sandboxBaseEnv is the environment a sandboxed server starts from instead of
+// ours: only PATH, the locale and the terminal
+func sandboxBaseEnv() []string {
+	var env []string
+	for _, key := range sandboxEnvKeep {
+		if value, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
+
+// sandboxHidden returns the credential paths that exist: those under the home
+// directory and our own config directory, which holds OAuth tokens
+func sandboxHidden() []string {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		for _, path := range sandboxHiddenPaths {
+			paths = append(paths, filepath.Join(home, path))
+		}
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		paths = append(paths, dir)
+	}
+
+	var existing []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil && !seen[path] {
+			seen[path] = true
+			existing = append(existing, path)
+		}
+	}
+	return existing
+}
+
+// sandboxCommand moves a stdio server's command into the sandbox: a new
+// temporary directory becomes its working directory, HOME and TMPDIR, and
+// the platform's isolation is applied. The returned function deletes the
+// directory once the server has stopped.
+func sandboxCommand(cmd *exec.Cmd) (func(), error) {
+	dir, err := os.MkdirTemp("", "mcpinspect-sandbox-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, "HOME="+dir, "TMPDIR="+dir)
+	if err := isolateCommand(cmd, dir); err != nil {
+		cleanup()
+		return nil, err
+	}
+	return cleanup, nil
+}
+
+// sandboxStartError explains what the platform's sandbox needs when a
+// sandboxed server could not be started
+func sandboxStartError(err error) error {
+	if !sandbox || sandboxRequirement == "" {
+		return err
+	}
+	return fmt.Errorf("%w; %s", err, sandboxRequirement)
+}
@@ -0,0 +1,46 @@
+//go:build darwin
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sandboxExec is macOS's command applying a Seatbelt profile to a process
+const sandboxExec = "/usr/bin/sandbox-exec"
+
+const sandboxRequirement = "--sandbox runs stdio servers through " + sandboxExec
+
+// isolateCommand runs cmd under sandbox-exec with a profile that denies
+// reading the credential paths, writing outside the sandbox directory and,
+// without --sandbox-net, the network
+func isolateCommand(cmd *exec.Cmd, dir string) error {
+	// Seatbelt matches real paths, and the temporary directory is under the /var symlink
+	realDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve sandbox directory: %w", err)
+	}
+
+	cmd.Args = append([]string{sandboxExec, "-p", sandboxProfile(realDir), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = sandboxExec
+	return nil
+}
+
+// sandboxProfile is the Seatbelt profile of a sandboxed server; later rules
+// take precedence over earlier ones
+func sandboxProfile(dir string) string {
+	var b strings.Builder
+	b.WriteString("(version 1)\n(allow default)\n")
+	fmt.Fprintf(&b, "(deny file-write*)\n(allow file-write* (subpath %s) (subpath \"/dev\"))\n", strconv.Quote(dir))
+	for _, path := range sandboxHidden() {
+		fmt.Fprintf(&b, "(deny file-read* file-write* (subpath %s))\n", strconv.Quote(path))
+	}
+	if !sandboxNet {
+		b.WriteString("(deny network-outbound (remote ip \"*:*\"))\n(deny network-bind (local ip \"*:*\"))\n")
+	}
+	return b.String()
+}
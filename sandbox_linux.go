@@ -0,0 +1,195 @@
+//go:build linux
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// sandboxInitArg makes mcpinspect run as the sandbox's init: started in new
+// namespaces, it makes the home directory read-only, hides the credential
+// paths and then executes the server.
+// Go cannot run code between clone and exec, so the mounts need this step.
+const sandboxInitArg = "__sandbox-init"
+
+const sandboxRequirement = "--sandbox needs unprivileged user namespaces; they may be disabled by kernel.unprivileged_userns_clone or AppArmor's kernel.apparmor_restrict_unprivileged_userns"
+
+// The sandbox's init runs before main, so no flag parsing or config loading
+// happens inside the namespaces
+func init() {
+	if len(os.Args) > 1 && os.Args[1] == sandboxInitArg {
+		if err := runSandboxInit(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "mcpinspect sandbox: %v\n", err)
+			os.Exit(126)
+		}
+	}
+}
+
+// isolateCommand runs cmd through the sandbox's init in new user, mount, PID,
+// IPC and UTS namespaces, and a network namespace without --sandbox-net. The
+// server is root in its user namespace only, with no privileges outside it,
+// and cannot write to the home directory.
+func isolateCommand(cmd *exec.Cmd, dir string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate mcpinspect for the sandbox: %w", err)
+	}
+
+	args := []string{self, sandboxInitArg, "--dir", dir}
+	if home, err := os.UserHomeDir(); err == nil {
+		args = append(args, "--readonly", home)
+	}
+	for _, path := range sandboxHidden() {
+		args = append(args, "--hide", path)
+	}
+	if !sandboxNet {
+		args = append(args, "--loopback")
+	}
+	args = append(args, "--")
+	cmd.Args = append(args, append([]string{cmd.Path}, cmd.Args[1:]...)...)
+	cmd.Path = self
+
+	flags := syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS
+	if !sandboxNet {
+		flags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  uintptr(flags),
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: os.Getgid(), Size: 1}},
+	}
+	return nil
+}
+
+// runSandboxInit makes the --readonly paths read-only except for the
+// sandbox's --dir, hides each --hide path, mounts a /proc of the new PID
+// namespace, brings up loopback with --loopback and executes the command
+// after --. The server replaces the init as PID 1 of its namespace, so
+// killing it stops every process it started.
+func runSandboxInit(args []string) error {
+	var dir string
+	var readonly, hidden []string
+	loopback := false
+	for len(args) > 0 && args[0] != "--" {
+		switch {
+		case args[0] == "--loopback":
+			loopback = true
+			args = args[1:]
+		case args[0] == "--dir" && len(args) > 1:
+			dir = args[1]
+			args = args[2:]
+		case args[0] == "--readonly" && len(args) > 1:
+			readonly = append(readonly, args[1])
+			args = args[2:]
+		case args[0] == "--hide" && len(args) > 1:
+			hidden = append(hidden, args[1])
+			args = args[2:]
+		default:
+			return fmt.Errorf("invalid arguments %q", args)
+		}
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("no command to run")
+	}
+	command := args[1:]
+
+	// Keep the mounts below from propagating back to the host
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("failed to make mounts private: %w", err)
+	}
+	for _, path := range readonly {
+		if err := syscall.Mount(path, path, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", path, err)
+		}
+		if err := syscall.Mount("", path, "", syscall.MS_BIND|syscall.MS_REMOUNT|syscall.MS_RDONLY|lockedMountFlags(path), ""); err != nil {
+			return fmt.Errorf("failed to make %s read-only: %w", path, err)
+		}
+	}
+	// The sandbox directory stays writable, even when TMPDIR is under home
+	if dir != "" {
+		if err := syscall.Mount(dir, dir, "", syscall.MS_BIND, ""); err != nil {
+			return fmt.Errorf("failed to bind %s: %w", dir, err)
+		}
+		// The bind copies the read-only flag of the home bind it is under
+		if err := syscall.Mount("", dir, "", syscall.MS_BIND|syscall.MS_REMOUNT|lockedMountFlags(dir), ""); err != nil {
+			return fmt.Errorf("failed to make %s writable: %w", dir, err)
+		}
+	}
+	for _, path := range hidden {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.IsDir() {
+			err = syscall.Mount("tmpfs", path, "tmpfs", syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV, "size=4k,mode=0500")
+		} else {
+			err = syscall.Mount("/dev/null", path, "", syscall.MS_BIND, "")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to hide %s: %w", path, err)
+		}
+	}
+	// Best effort: some container runtimes lock /proc against remounting
+	syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "")
+	if loopback {
+		if err := loopbackUp(); err != nil {
+			return fmt.Errorf("failed to bring up loopback: %w", err)
+		}
+	}
+
+	return syscall.Exec(command[0], command, os.Environ())
+}
+
+// lockedMountFlags are the flags of the mount path is on that a user namespace
+// cannot change: a remount without them fails with EPERM
+func lockedMountFlags(path string) uintptr {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0
+	}
+	var flags uintptr
+	for st, ms := range map[int64]uintptr{
+		unix.ST_NOSUID:     syscall.MS_NOSUID,
+		unix.ST_NODEV:      syscall.MS_NODEV,
+		unix.ST_NOEXEC:     syscall.MS_NOEXEC,
+		unix.ST_NOATIME:    syscall.MS_NOATIME,
+		unix.ST_NODIRATIME: syscall.MS_NODIRATIME,
+		unix.ST_RELATIME:   syscall.MS_RELATIME,
+	} {
+		if int64(stat.Flags)&st != 0 {
+			flags |= ms
+		}
+	}
+	return flags
+}
+
+// loopbackUp sets the IFF_UP flag of lo, which starts down in a new network
+// namespace, so the server can still listen on and reach 127.0.0.1
+func loopbackUp() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+
+	var req struct {
+		name  [syscall.IFNAMSIZ]byte
+		flags uint16
+		_     [22]byte
+	}
+	copy(req.name[:], "lo")
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return errno
+	}
+	req.flags |= syscall.IFF_UP
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return errno
+	}
+	return nil
+}
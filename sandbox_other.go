@@ -0,0 +1,24 @@
+//go:build !linux && !darwin
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+)
+
+const sandboxRequirement = ""
+
+var sandboxWarning sync.Once
+
+// isolateCommand has no isolation to apply on this platform: sandboxed
+// servers only get the minimal environment and the temporary directory
+func isolateCommand(cmd *exec.Cmd, dir string) error {
+	sandboxWarning.Do(func() {
+		fmt.Fprintf(os.Stderr, "Warning: --sandbox cannot isolate servers on %s; they only get a minimal environment and a temporary directory\n", runtime.GOOS)
+	})
+	return nil
+}
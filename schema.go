@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaParam is a single property of a tool's input schema, flattened so
+// nested object properties are addressed by dotted paths
+type SchemaParam struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+	Enum        []interface{}
+	Default     interface{}
+	Depth       int
+}
+
+// schemaParams flattens the properties of a JSON Schema object in name order
+func schemaParams(schema interface{}) []SchemaParam {
+	var params []SchemaParam
+	collectSchemaParams(asSchemaMap(schema), "", 0, &params)
+	return params
+}
+
+func collectSchemaParams(schema map[string]interface{}, prefix string, depth int, params *[]SchemaParam) {
+	properties := asSchemaMap(schema["properties"])
+	if len(properties) == 0 {
+		return
+	}
+
+	required := requiredSet(schema)
+
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		prop := asSchemaMap(properties[name])
+		param := SchemaParam{
+			Name:     prefix + name,
+			Type:     schemaType(prop),
+			Required: required[name],
+			Depth:    depth,
+			Default:  prop["default"],
+		}
+		if desc, ok := prop["description"].(string); ok {
+			param.Description = desc
+		}
+		if enum, ok := prop["enum"].([]interface{}); ok {
+			param.Enum = enum
+		}
+		*params = append(*params, param)
+
+		// Descend into nested objects and arrays of objects
+		collectSchemaParams(prop, param.Name+".", depth+1, params)
+		if items := asSchemaMap(prop["items"]); items != nil {
+			collectSchemaParams(items, param.Name+"[].", depth+1, params)
+		}
+	}
+}
+
+// requiredSet returns the names listed in a schema's "required" array
+func requiredSet(schema map[string]interface{}) map[string]bool {
+	required := make(map[string]bool)
+	if list, ok := schema["required"].([]interface{}); ok {
+		for _, name := range list {
+			if s, ok := name.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+	return required
+}
+
+// schemaType renders the type of a schema node, e.g. "string", "array<integer>" or "string|null"
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		if t == "array" {
+			if items := asSchemaMap(schema["items"]); items != nil {
+				return "array<" + schemaType(items) + ">"
+			}
+		}
+		return t
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, v := range t {
+			types = append(types, fmt.Sprint(v))
+		}
+		return strings.Join(types, "|")
+	}
+
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if variants, ok := schema[key].([]interface{}); ok {
+			types := make([]string, 0, len(variants))
+			for _, v := range variants {
+				types = append(types, schemaType(asSchemaMap(v)))
+			}
+			return strings.Join(types, "|")
+		}
+	}
+
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return "any"
+}
+
+// exampleValue builds a plausible value for a schema node, preferring
+// defaults and enum values over type placeholders
+func exampleValue(name string, schema map[string]interface{}) interface{} {
+	if def, ok := schema["default"]; ok {
+		return def
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	typ := strings.SplitN(schemaType(schema), "|", 2)[0]
+	switch {
+	case typ == "string":
+		return "<" + name + ">"
+	case typ == "integer", typ == "number":
+		return 0
+	case typ == "boolean":
+		return false
+	case strings.HasPrefix(typ, "array"):
+		if items := asSchemaMap(schema["items"]); items != nil {
+			return []interface{}{exampleValue(name, items)}
+		}
+		return []interface{}{}
+	case typ == "object":
+		properties := asSchemaMap(schema["properties"])
+		example := make(map[string]interface{}, len(properties))
+		for propName, prop := range properties {
+			example[propName] = exampleValue(propName, asSchemaMap(prop))
+		}
+		return example
+	default:
+		return nil
+	}
+}
+
+// asSchemaMap returns v as a JSON object, or nil if it is not one
+func asSchemaMap(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServeAddr keeps the UI on the loopback interface unless asked otherwise
+const defaultServeAddr = "127.0.0.1:7777"
+
+func newServeCmd() *cobra.Command {
+	var addr string
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Start a local web UI for browsing servers and trying their tools",
+		Long: `Start a local HTTP server with a browsable UI of all configured servers,
+their tools, input schemas, resources and prompts, and a form for invoking
+each tool with JSON arguments.
+
+The config file is read again on every page load. Servers are connected on
+demand, one session per page. Tools invoked from the UI run with the same
+privileges as mcpinspect, so the UI only accepts requests addressed to
+localhost or an IP address, and rejects cross-origin form submissions.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return serveUI(ctx, addr)
+		},
+	}
+	serveCmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "address to listen on")
+	return serveCmd
+}
+
+func serveUI(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", handleServerList)
+	mux.HandleFunc("GET /servers/{name}", handleServerPage)
+	mux.HandleFunc("POST /servers/{name}/tools/{tool}", handleToolCall)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	server := &http.Server{
+		Handler:           guardLocalRequests(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(os.Stderr, "Serving on http://%s (press Ctrl+C to stop)\n", listener.Addr())
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// guardLocalRequests rejects requests whose Host is a DNS name other than
+// localhost (DNS rebinding) and form posts from other origins (CSRF), since
+// the UI can invoke tools
+func guardLocalRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if host != "localhost" && net.ParseIP(host) == nil {
+			http.Error(w, "forbidden host", http.StatusForbidden)
+			return
+		}
+
+		if r.Method != http.MethodGet {
+			if origin := r.Header.Get("Origin"); origin != "" {
+				u, err := url.Parse(origin)
+				if err != nil || u.Host != r.Host {
+					http.Error(w, "cross-origin request rejected", http.StatusForbidden)
+					return
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		next.ServeHTTP(w, r)
+	})
+}
+
+// toolView is a tool prepared for the server page template
+type toolView struct {
+	ToolInfo
+	Params      []paramView
+	Schema      string
+	ExampleArgs string
+}
+
+// paramView is a schema parameter with its description, allowed values and default combined
+type paramView struct {
+	SchemaParam
+	Details string
+}
+
+// toolCallView is the result page for a tool invoked from the UI
+type toolCallView struct {
+	Server    string
+	Tool      string
+	Arguments string
+	Result    *ToolCallResult
+	Content   []string
+	Structure string
+	Error     string
+}
+
+func handleServerList(w http.ResponseWriter, r *http.Request) {
+	config, err := loadConfig(configPath)
+	if err != nil {
+		renderError(w, http.StatusInternalServerError, fmt.Errorf("failed to load config: %w", err))
+		return
+	}
+	renderPage(w, "servers", aggregateServers(config))
+}
+
+func handleServerPage(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	config, err := loadConfig(configPath)
+	if err != nil {
+		renderError(w, http.StatusInternalServerError, fmt.Errorf("failed to load config: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, name)
+	if err != nil {
+		renderError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer conn.Close()
+
+	docs, err := collectDocs(ctx, config, conn)
+	if err != nil {
+		renderError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	tools := make([]toolView, 0, len(docs.Inspection.Tools))
+	for _, tool := range docs.Inspection.Tools {
+		view := toolView{
+			ToolInfo:    tool,
+			Schema:      indentJSON(tool.InputSchema),
+			ExampleArgs: indentJSON(exampleValue("value", asSchemaMap(tool.InputSchema))),
+		}
+		for _, param := range schemaParams(tool.InputSchema) {
+			view.Params = append(view.Params, paramView{
+				SchemaParam: param,
+				// describeParam marks values up as Markdown code spans
+				Details: strings.ReplaceAll(describeParam(param), "`", ""),
+			})
+		}
+		tools = append(tools, view)
+	}
+
+	renderPage(w, "server", map[string]interface{}{
+		"Docs":  docs,
+		"Tools": tools,
+	})
+}
+
+func handleToolCall(w http.ResponseWriter, r *http.Request) {
+	view := &toolCallView{
+		Server:    r.PathValue("name"),
+		Tool:      r.PathValue("tool"),
+		Arguments: r.FormValue("arguments"),
+	}
+
+	var arguments map[string]interface{}
+	if view.Arguments != "" {
+		if err := json.Unmarshal([]byte(view.Arguments), &arguments); err != nil {
+			view.Error = fmt.Sprintf("invalid arguments: %v", err)
+			renderPage(w, "call", view)
+			return
+		}
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		renderError(w, http.StatusInternalServerError, fmt.Errorf("failed to load config: %w", err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, view.Server)
+	if err != nil {
+		renderError(w, http.StatusBadGateway, err)
+		return
+	}
+	defer conn.Close()
+
+	result, err := callTool(ctx, conn, view.Tool, arguments)
+	if err != nil {
+		view.Error = err.Error()
+	} else {
+		view.Result = result
+		for _, content := range result.Content {
+			view.Content = append(view.Content, formatContent(content))
+		}
+		if result.StructuredContent != nil {
+			view.Structure = indentJSON(result.StructuredContent)
+		}
+	}
+	renderPage(w, "call", view)
+}
+
+// indentJSON renders v as indented JSON for display, leaving <placeholders> unescaped
+func indentJSON(v interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func renderPage(w http.ResponseWriter, name string, data interface{}) {
+	if err := serveTemplates.ExecuteTemplate(w, name, data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering %s: %v\n", name, err)
+	}
+}
+
+func renderError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+	renderPage(w, "error", err.Error())
+}
+
+var serveTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"path": url.PathEscape,
+}).Parse(`
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>mcpinspect</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2em auto; max-width: 70em; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; margin: 0.5em 0 1em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+pre, textarea { font-family: ui-monospace, monospace; font-size: 0.9em; }
+pre { background: #f7f7f7; padding: 0.6em; overflow-x: auto; }
+textarea { width: 100%; min-height: 8em; }
+.tool { border-top: 1px solid #ddd; padding-top: 0.5em; }
+.muted { color: #777; }
+.error { color: #b00; }
+</style>
+</head>
+<body>
+<p><a href="/">All servers</a></p>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "servers"}}{{template "header"}}
+<h1>MCP servers</h1>
+{{if not .}}<p>No MCP servers configured.</p>{{else}}
+<table>
+<tr><th>Name</th><th>Type</th><th>URL / Command</th><th>Projects</th></tr>
+{{range .}}<tr>
+<td><a href="/servers/{{path .Name}}">{{.Name}}</a></td>
+<td>{{.Type}}</td>
+<td>{{if .URL}}{{.URL}}{{else}}<code>{{.Command}}{{range .Args}} {{.}}{{end}}</code>{{end}}</td>
+<td>{{range .Projects}}{{.}}<br>{{end}}</td>
+</tr>{{end}}
+</table>
+<p class="muted">{{len .}} servers</p>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "server"}}{{template "header"}}
+{{with .Docs.Inspection}}<h1>{{.Name}}</h1>
+<p>{{.ServerInfo}} | {{.Type}} | protocol {{.ProtocolVersion}}</p>{{end}}
+<h2>Tools</h2>
+{{if not .Tools}}<p>This server exposes no tools.</p>{{end}}
+{{$server := .Docs.Inspection.Name}}
+{{range .Tools}}<div class="tool">
+<h3 id="{{.Name}}"><code>{{.Name}}</code></h3>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .Params}}<table>
+<tr><th>Parameter</th><th>Type</th><th>Required</th><th>Description</th></tr>
+{{range .Params}}<tr>
+<td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Details}}</td>
+</tr>{{end}}
+</table>{{else}}<p class="muted">This tool takes no parameters.</p>{{end}}
+<details><summary>Input schema</summary><pre>{{.Schema}}</pre></details>
+<form method="post" action="/servers/{{path $server}}/tools/{{path .Name}}">
+<p><textarea name="arguments">{{.ExampleArgs}}</textarea></p>
+<p><button type="submit">Call {{.Name}}</button></p>
+</form>
+</div>{{end}}
+{{if .Docs.Resources}}<h2>Resources</h2>
+<table>
+<tr><th>URI</th><th>Name</th><th>MIME Type</th><th>Description</th></tr>
+{{range .Docs.Resources}}<tr><td><code>{{.Uri}}</code></td><td>{{.Name}}</td><td>{{if .MimeType}}{{.MimeType}}{{end}}</td><td>{{if .Description}}{{.Description}}{{end}}</td></tr>{{end}}
+</table>{{end}}
+{{if .Docs.Prompts}}<h2>Prompts</h2>
+<table>
+<tr><th>Name</th><th>Arguments</th><th>Description</th></tr>
+{{range .Docs.Prompts}}<tr><td><code>{{.Name}}</code></td><td>{{range .Arguments}}<code>{{.Name}}</code> {{end}}</td><td>{{if .Description}}{{.Description}}{{end}}</td></tr>{{end}}
+</table>{{end}}
+{{template "footer"}}{{end}}
+
+{{define "call"}}{{template "header"}}
+<p><a href="/servers/{{path .Server}}">{{.Server}}</a></p>
+<h1><code>{{.Tool}}</code></h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+{{with .Result}}{{if .IsError}}<p class="error">The tool reported an error.</p>{{end}}{{end}}
+{{range .Content}}<pre>{{.}}</pre>{{end}}
+{{if .Structure}}<h2>Structured content</h2><pre>{{.Structure}}</pre>{{end}}
+<form method="post" action="/servers/{{path .Server}}/tools/{{path .Tool}}">
+<p><textarea name="arguments">{{.Arguments}}</textarea></p>
+<p><button type="submit">Call again</button></p>
+</form>
+{{template "footer"}}{{end}}
+
+{{define "error"}}{{template "header"}}
+<h1>Error</h1>
+<p class="error">{{.}}</p>
+{{template "footer"}}{{end}}
+`))
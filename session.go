@@ -0,0 +1,573 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// sessionRequestIDBase keeps raw request IDs clear of the IDs the mcp-golang
+// client allocates (which start at 0)
+const sessionRequestIDBase transport.RequestId = 1 << 30
+
+// errCodeRequestTimeout is the JSON-RPC error code of a request that got no
+// answer within the request timeout
+const errCodeRequestTimeout = -32001
+
+// RPCError is a JSON-RPC error returned by a server for a raw request
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("RPC error %d: %s", e.Code, e.Message)
+}
+
+// RequestHandler answers a request the server sends to the client, such as
+// sampling/createMessage. Returning an *RPCError sends that error back as is.
+type RequestHandler func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// SessionTransport wraps a transport so mcpinspect can send raw JSON-RPC
+// requests alongside the mcp-golang client, for methods and result shapes
+// the client library does not model
+type SessionTransport struct {
+	inner          transport.Transport
+	mu             sync.Mutex
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	nextID         transport.RequestId
+	pending        map[transport.RequestId]chan *transport.BaseJsonRpcMessage
+	requestTimeout time.Duration
+	timers         map[transport.RequestId]*time.Timer
+	sent           map[transport.RequestId]sentRequest
+	log            *slog.Logger
+	initID         *transport.RequestId
+	initResult     json.RawMessage
+	protocol       string
+	listeners      map[string][]*notificationListener
+	handlers       map[string]RequestHandler
+	capabilities   map[string]interface{}
+	closeHandler   func()
+	done           chan struct{}
+	closeOnce      sync.Once
+}
+
+// sentRequest is a request awaiting its answer, kept to log its timing
+type sentRequest struct {
+	method string
+	at     time.Time
+}
+
+// NewSessionTransport creates a new session wrapper around the given transport
+func NewSessionTransport(inner transport.Transport) *SessionTransport {
+	t := &SessionTransport{
+		inner:     inner,
+		nextID:    sessionRequestIDBase,
+		pending:   make(map[transport.RequestId]chan *transport.BaseJsonRpcMessage),
+		timers:    make(map[transport.RequestId]*time.Timer),
+		sent:      make(map[transport.RequestId]sentRequest),
+		log:       logger,
+		listeners: make(map[string][]*notificationListener),
+		handlers:  make(map[string]RequestHandler),
+		done:      make(chan struct{}),
+	}
+	inner.SetMessageHandler(t.dispatch)
+	inner.SetCloseHandler(t.handleClose)
+	return t
+}
+
+// Request sends a raw JSON-RPC request and waits for its result, for at most
+// the request timeout unless ctx has a deadline of its own
+func (t *SessionTransport) Request(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	var rawParams json.RawMessage
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal params: %w", err)
+		}
+		rawParams = data
+	}
+
+	t.mu.Lock()
+	id := t.nextID
+	t.nextID++
+	ch := make(chan *transport.BaseJsonRpcMessage, 1)
+	t.pending[id] = ch
+	timeout := t.requestTimeout
+	t.mu.Unlock()
+
+	timed := false
+	if _, ok := ctx.Deadline(); !ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		timed = true
+	}
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, id)
+		t.mu.Unlock()
+	}()
+
+	request := &transport.BaseJSONRPCRequest{
+		Jsonrpc: "2.0",
+		Id:      id,
+		Method:  method,
+		Params:  rawParams,
+	}
+	// HTTP transports may read the answer while sending, so the deadline can
+	// pass in either place
+	timedOut := func() bool {
+		return timed && errors.Is(ctx.Err(), context.DeadlineExceeded)
+	}
+	if err := t.sendRequest(ctx, transport.NewBaseMessageRequest(request)); err != nil {
+		if timedOut() {
+			t.cancelRequest(id, ctx.Err())
+			return nil, requestTimeoutError(timeout)
+		}
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	select {
+	case msg := <-ch:
+		if msg == nil {
+			return nil, fmt.Errorf("connection closed")
+		}
+		if msg.JsonRpcError != nil {
+			return nil, &RPCError{
+				Code:    msg.JsonRpcError.Error.Code,
+				Message: msg.JsonRpcError.Error.Message,
+				Data:    msg.JsonRpcError.Error.Data,
+			}
+		}
+		return msg.JsonRpcResponse.Result, nil
+	case <-ctx.Done():
+		t.cancelRequest(id, ctx.Err())
+		if timedOut() {
+			return nil, requestTimeoutError(timeout)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// requestTimeoutError reports a request the server left unanswered
+func requestTimeoutError(timeout time.Duration) error {
+	return fmt.Errorf("no answer within %s, raise --request-timeout for slow requests", timeout)
+}
+
+// armTimeout fails a request of the mcp-golang client that the server leaves
+// unanswered for the request timeout, unless ctx has a deadline of its own.
+// The client would otherwise wait for as long as the command runs.
+func (t *SessionTransport) armTimeout(ctx context.Context, request *transport.BaseJSONRPCRequest) {
+	if _, ok := ctx.Deadline(); ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timeout := t.requestTimeout
+	if timeout <= 0 {
+		return
+	}
+	id := request.Id
+	t.timers[id] = time.AfterFunc(timeout, func() {
+		t.mu.Lock()
+		_, armed := t.timers[id]
+		delete(t.timers, id)
+		handler := t.messageHandler
+		t.mu.Unlock()
+		if !armed || handler == nil {
+			return
+		}
+
+		t.cancelRequest(id, context.DeadlineExceeded)
+		handler(context.Background(), transport.NewBaseMessageError(&transport.BaseJSONRPCError{
+			Jsonrpc: "2.0",
+			Id:      id,
+			Error: transport.BaseJSONRPCErrorInner{
+				Code:    errCodeRequestTimeout,
+				Message: requestTimeoutError(timeout).Error(),
+			},
+		}))
+	})
+}
+
+// logAnswer logs how long the server took to answer a request
+func (t *SessionTransport) logAnswer(id transport.RequestId, message *transport.BaseJsonRpcMessage) {
+	t.mu.Lock()
+	request, ok := t.sent[id]
+	delete(t.sent, id)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+	if message.JsonRpcError != nil {
+		t.log.Info("request failed", "method", request.method, "id", id, "elapsed", time.Since(request.at), "code", message.JsonRpcError.Error.Code, "error", message.JsonRpcError.Error.Message)
+		return
+	}
+	t.log.Info("request answered", "method", request.method, "id", id, "elapsed", time.Since(request.at))
+}
+
+// disarmTimeout stops the timer of a client request that got its answer
+func (t *SessionTransport) disarmTimeout(id transport.RequestId) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if timer, ok := t.timers[id]; ok {
+		timer.Stop()
+		delete(t.timers, id)
+	}
+}
+
+// Notify sends a raw JSON-RPC notification
+func (t *SessionTransport) Notify(ctx context.Context, method string, params interface{}) error {
+	var rawParams json.RawMessage
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params: %w", err)
+		}
+		rawParams = data
+	}
+	notification := &transport.BaseJSONRPCNotification{
+		Jsonrpc: "2.0",
+		Method:  method,
+		Params:  rawParams,
+	}
+	return t.inner.Send(ctx, transport.NewBaseMessageNotification(notification))
+}
+
+// cancelRequest tells the server an abandoned request is no longer wanted, so
+// it can stop working on it
+func (t *SessionTransport) cancelRequest(id transport.RequestId, cause error) {
+	reason := "interrupted"
+	if errors.Is(cause, context.DeadlineExceeded) {
+		reason = "timed out"
+	}
+	t.mu.Lock()
+	request, ok := t.sent[id]
+	delete(t.sent, id)
+	t.mu.Unlock()
+	if ok {
+		t.log.Info("request "+reason, "method", request.method, "id", id, "elapsed", time.Since(request.at))
+	}
+
+	select {
+	case <-t.done:
+		return
+	default:
+	}
+	// The request's ctx is already done, so the notification needs its own
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	t.Notify(ctx, "notifications/cancelled", map[string]interface{}{"requestId": id, "reason": reason})
+}
+
+// notificationListener wraps a listener so it can be found again for removal
+type notificationListener struct {
+	fn func(params json.RawMessage)
+}
+
+// OnNotification registers a listener for server notifications with the given
+// method and returns a function that removes it. Listeners run on the
+// transport's read loop and must not block.
+func (t *SessionTransport) OnNotification(method string, listener func(params json.RawMessage)) func() {
+	l := &notificationListener{fn: listener}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.listeners[method] = append(t.listeners[method], l)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		listeners := t.listeners[method]
+		for i, other := range listeners {
+			if other == l {
+				t.listeners[method] = append(listeners[:i:i], listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// HandleRequest answers server requests with the given method using handler.
+// Handlers run on their own goroutine, so they may block, e.g. on user input.
+func (t *SessionTransport) HandleRequest(method string, handler RequestHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[method] = handler
+}
+
+// SetCapability adds a client capability to the initialize request, for
+// features the mcp-golang client does not advertise
+func (t *SessionTransport) SetCapability(name string, value interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.capabilities == nil {
+		t.capabilities = make(map[string]interface{})
+	}
+	t.capabilities[name] = value
+}
+
+// Done returns a channel that is closed when the connection to the server closes
+func (t *SessionTransport) Done() <-chan struct{} {
+	return t.done
+}
+
+// dispatch routes responses to raw requests and passes everything else to the client
+func (t *SessionTransport) dispatch(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+	var id transport.RequestId
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		id = message.JsonRpcResponse.Id
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		id = message.JsonRpcError.Id
+	}
+
+	if message.Type == transport.BaseMessageTypeJSONRPCResponseType || message.Type == transport.BaseMessageTypeJSONRPCErrorType {
+		t.disarmTimeout(id)
+		t.logAnswer(id, message)
+	}
+
+	if message.Type == transport.BaseMessageTypeJSONRPCResponseType {
+		t.mu.Lock()
+		if t.initID != nil && *t.initID == id {
+			t.initResult = message.JsonRpcResponse.Result
+		}
+		t.mu.Unlock()
+	}
+
+	if message.Type == transport.BaseMessageTypeJSONRPCRequestType {
+		t.mu.Lock()
+		handler := t.handlers[message.JsonRpcRequest.Method]
+		t.mu.Unlock()
+		if handler != nil {
+			go t.answer(message.JsonRpcRequest, handler)
+			return
+		}
+	}
+
+	if message.Type == transport.BaseMessageTypeJSONRPCNotificationType {
+		t.mu.Lock()
+		listeners := t.listeners[message.JsonRpcNotification.Method]
+		t.mu.Unlock()
+		for _, listener := range listeners {
+			listener.fn(message.JsonRpcNotification.Params)
+		}
+	}
+
+	if id >= sessionRequestIDBase {
+		t.mu.Lock()
+		ch := t.pending[id]
+		t.mu.Unlock()
+		if ch != nil {
+			select {
+			case ch <- message:
+			default:
+			}
+			return
+		}
+	}
+
+	t.mu.Lock()
+	handler := t.messageHandler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(ctx, message)
+	}
+}
+
+// answer runs a handler for a server request and sends its result or error back
+func (t *SessionTransport) answer(request *transport.BaseJSONRPCRequest, handler RequestHandler) {
+	// The handler may wait on the user, so it is bounded only by the connection
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-t.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	result, err := handler(ctx, request.Params)
+	var data []byte
+	if err == nil {
+		data, err = json.Marshal(result)
+	}
+	if err != nil {
+		rpcErr, ok := err.(*RPCError)
+		if !ok {
+			rpcErr = &RPCError{Code: -32603, Message: err.Error()}
+		}
+		t.inner.Send(ctx, transport.NewBaseMessageError(&transport.BaseJSONRPCError{
+			Jsonrpc: "2.0",
+			Id:      request.Id,
+			Error: transport.BaseJSONRPCErrorInner{
+				Code:    rpcErr.Code,
+				Message: rpcErr.Message,
+				Data:    rpcErr.Data,
+			},
+		}))
+		return
+	}
+	t.inner.Send(ctx, transport.NewBaseMessageResponse(&transport.BaseJSONRPCResponse{
+		Jsonrpc: "2.0",
+		Id:      request.Id,
+		Result:  data,
+	}))
+}
+
+// Start implements Transport.Start
+func (t *SessionTransport) Start(ctx context.Context) error {
+	return t.inner.Start(ctx)
+}
+
+// SetProtocolVersion makes the client's initialize request offer the given
+// protocol version instead of the one mcp-golang sends
+func (t *SessionTransport) SetProtocolVersion(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.protocol = version
+}
+
+// SetLogger sets the logger of -v
+func (t *SessionTransport) SetLogger(log *slog.Logger) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.log = log
+}
+
+// SetRequestTimeout bounds how long each request waits for its answer.
+// The initialize request is left to the caller's context.
+func (t *SessionTransport) SetRequestTimeout(timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requestTimeout = timeout
+}
+
+// Send implements Transport.Send, noting the client's initialize request so its
+// raw result can be kept and rewriting its protocol version and capabilities.
+// Other client requests are bounded by the request timeout.
+func (t *SessionTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method != "initialize" {
+		t.armTimeout(ctx, message.JsonRpcRequest)
+	}
+	if message.Type == transport.BaseMessageTypeJSONRPCRequestType && message.JsonRpcRequest.Method == "initialize" {
+		t.mu.Lock()
+		id := message.JsonRpcRequest.Id
+		t.initID = &id
+		protocol := t.protocol
+		capabilities := t.capabilities
+		t.mu.Unlock()
+
+		if protocol != "" || len(capabilities) > 0 {
+			var params map[string]interface{}
+			if err := json.Unmarshal(message.JsonRpcRequest.Params, &params); err != nil {
+				return fmt.Errorf("failed to decode initialize params: %w", err)
+			}
+			if protocol != "" {
+				params["protocolVersion"] = protocol
+			}
+			if len(capabilities) > 0 {
+				merged, _ := params["capabilities"].(map[string]interface{})
+				if merged == nil {
+					merged = make(map[string]interface{})
+				}
+				for name, value := range capabilities {
+					merged[name] = value
+				}
+				params["capabilities"] = merged
+			}
+			data, err := json.Marshal(params)
+			if err != nil {
+				return fmt.Errorf("failed to encode initialize params: %w", err)
+			}
+			message.JsonRpcRequest.Params = data
+		}
+	}
+	if message.Type == transport.BaseMessageTypeJSONRPCRequestType {
+		return t.sendRequest(ctx, message)
+	}
+	return t.inner.Send(ctx, message)
+}
+
+// sendRequest sends a request, retrying list requests, which are safe to
+// repeat, on transient failures with --retries
+func (t *SessionTransport) sendRequest(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	method := message.JsonRpcRequest.Method
+	t.mu.Lock()
+	t.sent[message.JsonRpcRequest.Id] = sentRequest{method: method, at: time.Now()}
+	t.mu.Unlock()
+	if !strings.HasSuffix(method, "/list") {
+		return t.inner.Send(ctx, message)
+	}
+	return withRetries(ctx, method, func() error {
+		return t.inner.Send(ctx, message)
+	})
+}
+
+// InitializeResult returns the raw result of the initialize handshake, including
+// fields mcp-golang does not decode
+func (t *SessionTransport) InitializeResult() json.RawMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.initResult
+}
+
+// Close implements Transport.Close
+func (t *SessionTransport) Close() error {
+	return t.inner.Close()
+}
+
+// handleClose fails any raw requests still in flight and notifies the client
+func (t *SessionTransport) handleClose() {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		for id, ch := range t.pending {
+			select {
+			case ch <- nil:
+			default:
+			}
+			delete(t.pending, id)
+		}
+		for id, timer := range t.timers {
+			timer.Stop()
+			delete(t.timers, id)
+		}
+		clear(t.sent)
+		handler := t.closeHandler
+		t.mu.Unlock()
+		close(t.done)
+
+		if handler != nil {
+			handler()
+		}
+	})
+}
+
+// SetCloseHandler implements Transport.SetCloseHandler
+func (t *SessionTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeHandler = handler
+}
+
+// SetErrorHandler implements Transport.SetErrorHandler
+func (t *SessionTransport) SetErrorHandler(handler func(error)) {
+	t.inner.SetErrorHandler(handler)
+}
+
+// SetMessageHandler implements Transport.SetMessageHandler
+func (t *SessionTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messageHandler = handler
+}
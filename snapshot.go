@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultSnapshotDir is where snapshots are written unless --dir is given
+const defaultSnapshotDir = ".mcpinspect"
+
+// Snapshot is the canonical record of a server's surface, suitable for
+// committing as a baseline. It omits machine-specific fields such as project
+// paths so the file only changes when the server does, and it can be read
+// back wherever an InspectResult is expected (e.g. by diff).
+type Snapshot struct {
+	InspectResult
+}
+
+func newSnapshotCmd() *cobra.Command {
+	var dir string
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot <server-name>",
+		Short: "Write a canonical JSON snapshot of a server's tools and capabilities",
+		Long: `Inspect a server and write its tools, input schemas and capabilities to
+<dir>/<server-name>.json. The output is deterministic, so the file can be
+committed as a baseline and compared later with "mcpinspect diff".`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			return writeSnapshot(config, args[0], dir)
+		},
+	}
+	snapshotCmd.Flags().StringVar(&dir, "dir", defaultSnapshotDir, "directory to write the snapshot into")
+	return snapshotCmd
+}
+
+func writeSnapshot(config *ClaudeConfig, serverName, dir string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	snapshot, err := collectSnapshot(ctx, config, conn)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	path := filepath.Join(dir, serverName+".json")
+	if err := writeJSONFile(path, snapshot); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Printf("Wrote %s (%d tools)\n", path, len(snapshot.Tools))
+	return nil
+}
+
+// collectSnapshot inspects a connected server into its canonical snapshot
+func collectSnapshot(ctx context.Context, config *ClaudeConfig, conn *Connection) (*Snapshot, error) {
+	inspection, err := collectInspection(ctx, config, conn)
+	if err != nil {
+		return nil, err
+	}
+	inspection.Projects = nil
+	// The raw initialize result would only repeat the fields already captured
+	inspection.Initialize = nil
+
+	return &Snapshot{InspectResult: *inspection}, nil
+}
@@ -5,21 +5,62 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/metoro-io/mcp-golang/transport"
 )
 
+// Reconnection of a dropped SSE stream: the first delay is the server's retry
+// field or sseReconnectDelay, doubling up to sseMaxReconnectDelay
+const (
+	sseReconnectDelay    = 500 * time.Millisecond
+	sseMaxReconnectDelay = 30 * time.Second
+	sseMaxReconnects     = 10
+)
+
+// defaultMaxEventSize is the default of --max-event-size, in MB. Servers send
+// whole tool lists and base64 payloads as a single data line, far beyond
+// bufio.Scanner's 64KB default.
+const defaultMaxEventSize = 16
+
+// maxEventSize bounds one line of an SSE stream, in MB
+var maxEventSize int
+
+// newSSEScanner returns a line scanner for an SSE stream that accepts lines of
+// up to --max-event-size
+func newSSEScanner(reader io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize<<20)
+	return scanner
+}
+
+// sseScanError explains bufio's "token too long" in terms of the flag to raise
+func sseScanError(err error) error {
+	if errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("SSE event line larger than %d MB, raise --max-event-size: %w", maxEventSize, err)
+	}
+	return err
+}
+
 // TraditionalSSETransport implements client-side traditional SSE transport for MCP.
 // Traditional SSE flow:
 // 1. Client GETs /sse to establish SSE stream
 // 2. Server sends "endpoint" event with POST URL
 // 3. Client POSTs messages to that endpoint
 // 4. Responses come via SSE stream
+//
+// A dropped stream is reopened with backoff and a Last-Event-ID header. When
+// the server answers with a new endpoint, the session is new and the
+// initialize handshake is replayed on it.
 type TraditionalSSETransport struct {
 	sseURL         string
 	postEndpoint   string
@@ -29,8 +70,22 @@ type TraditionalSSETransport struct {
 	mu             sync.RWMutex
 	client         *http.Client
 	headers        map[string]string
+	refreshAuth    authRefresher
+	log            *slog.Logger
 	sseResp        *http.Response
+	streamCancel   context.CancelFunc
 	started        bool
+	closed         bool
+
+	lastEventID string
+	retryDelay  time.Duration
+
+	// initialize and initialized are the handshake messages sent, replayed
+	// on a new session; the response to a replayed initialize is dropped
+	initialize   []byte
+	initializeID transport.RequestId
+	initialized  []byte
+	replaying    bool
 }
 
 // NewTraditionalSSETransport creates a new traditional SSE client transport
@@ -39,6 +94,7 @@ func NewTraditionalSSETransport(sseURL string) *TraditionalSSETransport {
 		sseURL:  sseURL,
 		client:  &http.Client{},
 		headers: make(map[string]string),
+		log:     logger,
 	}
 }
 
@@ -48,6 +104,40 @@ func (t *TraditionalSSETransport) WithHeader(key, value string) *TraditionalSSET
 	return t
 }
 
+// WithClient replaces the HTTP client used for requests
+func (t *TraditionalSSETransport) WithClient(client *http.Client) *TraditionalSSETransport {
+	t.client = client
+	return t
+}
+
+// WithAuthRefresh makes requests answered with 401 retry once with a refreshed Authorization header
+func (t *TraditionalSSETransport) WithAuthRefresh(refresh authRefresher) *TraditionalSSETransport {
+	t.refreshAuth = refresh
+	return t
+}
+
+// WithLogger sets the logger of -v
+func (t *TraditionalSSETransport) WithLogger(log *slog.Logger) *TraditionalSSETransport {
+	t.log = log
+	return t
+}
+
+// setHeaders copies the transport's headers onto a request
+func (t *TraditionalSSETransport) setHeaders(req *http.Request) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// setAuth replaces the Authorization header after a refresh
+func (t *TraditionalSSETransport) setAuth(auth string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.headers["Authorization"] = auth
+}
+
 // Start establishes the SSE connection and waits for the endpoint event
 func (t *TraditionalSSETransport) Start(ctx context.Context) error {
 	t.mu.Lock()
@@ -57,56 +147,138 @@ func (t *TraditionalSSETransport) Start(ctx context.Context) error {
 	}
 	t.mu.Unlock()
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.sseURL, nil)
+	// The stream lives until Close; ctx only bounds opening it and waiting
+	// for the endpoint event
+	streamCtx, cancel := context.WithCancel(context.Background())
+	stop := context.AfterFunc(ctx, cancel)
+	body, err := t.connect(streamCtx)
 	if err != nil {
-		return fmt.Errorf("failed to create SSE request: %w", err)
+		stop()
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
-	for key, value := range t.headers {
-		req.Header.Set(key, value)
+	t.mu.Lock()
+	t.started = true
+	t.streamCancel = cancel
+	t.mu.Unlock()
+
+	// Wait for endpoint event
+	endpointCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	go t.readSSEEvents(streamCtx, body, endpointCh, errCh)
+
+	select {
+	case endpoint := <-endpointCh:
+		if !stop() {
+			return ctx.Err()
+		}
+		t.mu.Lock()
+		t.postEndpoint = t.resolveEndpoint(endpoint)
+		t.mu.Unlock()
+		t.log.Info("endpoint received", "endpoint", endpoint)
+		return nil
+	case err := <-errCh:
+		cancel()
+		return fmt.Errorf("failed to get endpoint: %w", err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connect opens the SSE stream, resuming after the last event received
+func (t *TraditionalSSETransport) connect(ctx context.Context) (io.ReadCloser, error) {
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.sseURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SSE request: %w", err)
+		}
+
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+		t.setHeaders(req)
+		t.mu.RLock()
+		if t.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", t.lastEventID)
+		}
+		t.mu.RUnlock()
+		return req, nil
 	}
 
-	resp, err := t.client.Do(req)
+	resp, err := sendWithRefresh(ctx, t.log, t.client, build, t.refreshAuth, t.setAuth)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSE endpoint: %w", err)
+		return nil, fmt.Errorf("failed to connect to SSE endpoint: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return fmt.Errorf("SSE connection failed: %s (status: %d)", string(body), resp.StatusCode)
+		return nil, &statusError{what: "SSE connection failed", status: resp.StatusCode, body: string(body)}
 	}
 
 	contentType := resp.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "text/event-stream") {
 		resp.Body.Close()
-		return fmt.Errorf("unexpected content type: %s", contentType)
+		return nil, fmt.Errorf("unexpected content type: %s", contentType)
 	}
 
 	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		resp.Body.Close()
+		return nil, errSSEClosed
+	}
 	t.sseResp = resp
-	t.started = true
-	t.mu.Unlock()
+	t.log.Info("SSE stream opened", "url", t.sseURL, "last_event_id", t.lastEventID)
+	return resp.Body, nil
+}
 
-	// Wait for endpoint event
-	endpointCh := make(chan string, 1)
-	errCh := make(chan error, 1)
+// errSSEClosed stops reconnecting once the transport is closed
+var errSSEClosed = errors.New("SSE transport closed")
 
-	go t.readSSEEvents(ctx, resp.Body, endpointCh, errCh)
+// reconnect reopens a dropped stream with exponential backoff, giving up
+// after sseMaxReconnects failed attempts
+func (t *TraditionalSSETransport) reconnect(ctx context.Context, cause error) (io.ReadCloser, error) {
+	t.mu.RLock()
+	delay := t.retryDelay
+	t.mu.RUnlock()
+	if delay <= 0 {
+		delay = sseReconnectDelay
+	}
+	if cause == nil {
+		cause = errors.New("closed by the server")
+	}
 
-	select {
-	case endpoint := <-endpointCh:
-		t.mu.Lock()
-		t.postEndpoint = t.resolveEndpoint(endpoint)
-		t.mu.Unlock()
-		return nil
-	case err := <-errCh:
-		return fmt.Errorf("failed to get endpoint: %w", err)
-	case <-ctx.Done():
-		return ctx.Err()
+	for attempt := 1; attempt <= sseMaxReconnects; attempt++ {
+		if attempt == 1 {
+			fmt.Fprintf(os.Stderr, "Warning: SSE stream %s dropped (%v), reconnecting in %s\n", t.sseURL, cause, delay)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: reconnecting failed: %v, retrying in %s\n", cause, delay)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		body, err := t.connect(ctx)
+		if err == nil || errors.Is(err, errSSEClosed) {
+			return body, err
+		}
+		cause = err
+		delay = min(delay*2, sseMaxReconnectDelay)
 	}
+	return nil, fmt.Errorf("SSE stream %s lost after %d reconnection attempts: %w", t.sseURL, sseMaxReconnects, cause)
+}
+
+// isClosed reports whether Close was called
+func (t *TraditionalSSETransport) isClosed() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.closed
 }
 
 // resolveEndpoint converts relative endpoint to absolute URL
@@ -126,12 +298,64 @@ func (t *TraditionalSSETransport) resolveEndpoint(endpoint string) string {
 	return base + "/" + endpoint
 }
 
-// readSSEEvents reads SSE events from the stream
-func (t *TraditionalSSETransport) readSSEEvents(ctx context.Context, reader io.Reader, endpointCh chan<- string, errCh chan<- error) {
-	scanner := bufio.NewScanner(reader)
+// readSSEEvents reads SSE events from the stream, reconnecting when it drops
+// after the endpoint event was received
+func (t *TraditionalSSETransport) readSSEEvents(ctx context.Context, reader io.ReadCloser, endpointCh chan<- string, errCh chan<- error) {
+	endpointSent := false
+	for {
+		err := t.readStream(ctx, reader, func(endpoint string) {
+			if !endpointSent {
+				endpointCh <- endpoint
+				endpointSent = true
+				return
+			}
+			t.resumeSession(ctx, endpoint)
+		})
+		reader.Close()
+		if t.isClosed() {
+			// Close already called the close handler
+			return
+		}
+		if !endpointSent {
+			if err == nil {
+				err = errors.New("stream closed before the endpoint event")
+			}
+			errCh <- err
+			break
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if errors.Is(err, bufio.ErrTooLong) {
+			// Resuming would only receive the same event again
+			fmt.Fprintf(os.Stderr, "Warning: SSE stream %s closed: %v\n", t.sseURL, err)
+			break
+		}
+		if reader, err = t.reconnect(ctx, err); err != nil {
+			if errors.Is(err, errSSEClosed) {
+				return
+			}
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			break
+		}
+	}
+
+	t.mu.RLock()
+	closeHandler := t.closeHandler
+	t.mu.RUnlock()
+	if closeHandler != nil {
+		closeHandler()
+	}
+}
+
+// readStream dispatches the events of one stream until it ends, recording
+// the last event id and the server's retry delay
+func (t *TraditionalSSETransport) readStream(ctx context.Context, reader io.Reader, onEndpoint func(string)) error {
+	scanner := newSSEScanner(reader)
 	var eventType string
 	var dataLines []string
-	endpointSent := false
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -140,13 +364,22 @@ func (t *TraditionalSSETransport) readSSEEvents(ctx context.Context, reader io.R
 			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
 		} else if strings.HasPrefix(line, "data:") {
 			dataLines = append(dataLines, strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		} else if strings.HasPrefix(line, "id:") {
+			t.mu.Lock()
+			t.lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			t.mu.Unlock()
+		} else if strings.HasPrefix(line, "retry:") {
+			if ms, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "retry:"))); err == nil && ms > 0 {
+				t.mu.Lock()
+				t.retryDelay = time.Duration(ms) * time.Millisecond
+				t.mu.Unlock()
+			}
 		} else if line == "" && len(dataLines) > 0 {
 			// End of event
 			data := strings.Join(dataLines, "\n")
 
-			if eventType == "endpoint" && !endpointSent {
-				endpointCh <- data
-				endpointSent = true
+			if eventType == "endpoint" {
+				onEndpoint(data)
 			} else if eventType == "message" || eventType == "" {
 				t.handleMessage(ctx, []byte(data))
 			}
@@ -155,17 +388,37 @@ func (t *TraditionalSSETransport) readSSEEvents(ctx context.Context, reader io.R
 			dataLines = nil
 		}
 	}
+	return sseScanError(scanner.Err())
+}
 
-	if err := scanner.Err(); err != nil && !endpointSent {
-		errCh <- err
+// resumeSession switches to the endpoint of a reopened stream. A different
+// endpoint means the server started a new session, which is initialized
+// again with the handshake messages sent on the first one.
+func (t *TraditionalSSETransport) resumeSession(ctx context.Context, endpoint string) {
+	endpoint = t.resolveEndpoint(endpoint)
+	t.mu.Lock()
+	if endpoint == t.postEndpoint {
+		t.mu.Unlock()
+		return
 	}
+	t.postEndpoint = endpoint
+	initialize, initialized := t.initialize, t.initialized
+	t.replaying = initialize != nil
+	t.mu.Unlock()
+	t.log.Info("server started a new session, replaying the handshake", "endpoint", endpoint)
 
-	t.mu.RLock()
-	closeHandler := t.closeHandler
-	t.mu.RUnlock()
-	if closeHandler != nil {
-		closeHandler()
-	}
+	// Posted aside, since the answers arrive on the stream being read
+	go func() {
+		for _, data := range [][]byte{initialize, initialized} {
+			if data == nil {
+				continue
+			}
+			if err := t.post(ctx, endpoint, data); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to initialize the new session of %s: %v\n", t.sseURL, err)
+				return
+			}
+		}
+	}()
 }
 
 // handleMessage processes a JSON-RPC message from SSE
@@ -178,33 +431,35 @@ func (t *TraditionalSSETransport) handleMessage(ctx context.Context, data []byte
 		return
 	}
 
-	// Try as response
-	var response transport.BaseJSONRPCResponse
-	if err := json.Unmarshal(data, &response); err == nil && response.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageResponse(&response))
+	message, err := decodeMessage(data)
+	if err != nil {
 		return
 	}
-
-	// Try as error
-	var errorResponse transport.BaseJSONRPCError
-	if err := json.Unmarshal(data, &errorResponse); err == nil && errorResponse.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageError(&errorResponse))
+	if t.replayResponse(message) {
 		return
 	}
+	handler(ctx, message)
+}
 
-	// Try as notification
-	var notification transport.BaseJSONRPCNotification
-	if err := json.Unmarshal(data, &notification); err == nil && notification.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageNotification(&notification))
-		return
+// replayResponse reports whether a message answers a replayed initialize,
+// which the client already had answered on the first session
+func (t *TraditionalSSETransport) replayResponse(message *transport.BaseJsonRpcMessage) bool {
+	var id transport.RequestId
+	switch {
+	case message.JsonRpcResponse != nil:
+		id = message.JsonRpcResponse.Id
+	case message.JsonRpcError != nil:
+		id = message.JsonRpcError.Id
+	default:
+		return false
 	}
-
-	// Try as request
-	var request transport.BaseJSONRPCRequest
-	if err := json.Unmarshal(data, &request); err == nil && request.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageRequest(&request))
-		return
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.replaying || id != t.initializeID {
+		return false
 	}
+	t.replaying = false
+	return true
 }
 
 // Send sends a JSON-RPC message via POST to the endpoint
@@ -224,20 +479,38 @@ func (t *TraditionalSSETransport) Send(ctx context.Context, message *transport.B
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
+	t.recordHandshake(message, jsonData)
+	return t.post(ctx, endpoint, jsonData)
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+// recordHandshake keeps the initialize messages for a new session
+func (t *TraditionalSSETransport) recordHandshake(message *transport.BaseJsonRpcMessage, data []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case message.JsonRpcRequest != nil && message.JsonRpcRequest.Method == "initialize":
+		t.initialize, t.initializeID = data, message.JsonRpcRequest.Id
+	case message.JsonRpcNotification != nil && message.JsonRpcNotification.Method == "notifications/initialized":
+		t.initialized = data
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	for key, value := range t.headers {
-		req.Header.Set(key, value)
+// post sends a JSON-RPC message to the endpoint
+func (t *TraditionalSSETransport) post(ctx context.Context, endpoint string, jsonData []byte) error {
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		t.setHeaders(req)
+		return req, nil
 	}
 
-	resp, err := t.client.Do(req)
+	resp, err := sendWithRefresh(ctx, t.log, t.client, build, t.refreshAuth, t.setAuth)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
@@ -245,7 +518,7 @@ func (t *TraditionalSSETransport) Send(ctx context.Context, message *transport.B
 	// Actual response comes via SSE stream
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server returned error: %s (status: %d)", string(body), resp.StatusCode)
+		return &statusError{what: "server returned error", status: resp.StatusCode, body: string(body)}
 	}
 
 	return nil
@@ -256,6 +529,10 @@ func (t *TraditionalSSETransport) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
+	t.closed = true
+	if t.streamCancel != nil {
+		t.streamCancel()
+	}
 	if t.sseResp != nil {
 		t.sseResp.Body.Close()
 		t.sseResp = nil
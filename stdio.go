@@ -1,12 +1,147 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
 
 	"github.com/metoro-io/mcp-golang/transport"
 )
 
+// StdioClientTransport speaks newline-delimited JSON-RPC to a server process
+// over its stdin and stdout. Unlike mcp-golang's stdio transport it keeps
+// notification params and reports EOF as a close, so callers waiting on a
+// server that exited fail immediately instead of timing out.
+type StdioClientTransport struct {
+	reader         io.Reader
+	writer         io.Writer
+	mu             sync.Mutex
+	writeMu        sync.Mutex
+	started        bool
+	closeOnce      sync.Once
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	errorHandler   func(error)
+	closeHandler   func()
+}
+
+// NewStdioClientTransport creates a transport reading from the server's stdout and writing to its stdin
+func NewStdioClientTransport(stdout io.Reader, stdin io.Writer) *StdioClientTransport {
+	return &StdioClientTransport{
+		reader: stdout,
+		writer: stdin,
+	}
+}
+
+// Start implements Transport.Start, reading messages in the background
+func (t *StdioClientTransport) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.started {
+		return nil // Already started, idempotent
+	}
+	t.started = true
+
+	go t.readLoop()
+	return nil
+}
+
+func (t *StdioClientTransport) readLoop() {
+	reader := bufio.NewReader(t.reader)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			t.handleLine(line)
+		}
+		if err != nil {
+			if err != io.EOF {
+				t.handleError(fmt.Errorf("read error: %w", err))
+			}
+			t.Close()
+			return
+		}
+	}
+}
+
+func (t *StdioClientTransport) handleLine(line []byte) {
+	if len(bytes.TrimSpace(line)) == 0 {
+		return
+	}
+
+	message, err := decodeMessage(line)
+	if err != nil {
+		t.handleError(fmt.Errorf("invalid message from server: %w", err))
+		return
+	}
+
+	t.mu.Lock()
+	handler := t.messageHandler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(context.Background(), message)
+	}
+}
+
+func (t *StdioClientTransport) handleError(err error) {
+	t.mu.Lock()
+	handler := t.errorHandler
+	t.mu.Unlock()
+	if handler != nil {
+		handler(err)
+	}
+}
+
+// Send implements Transport.Send
+func (t *StdioClientTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	data = append(data, '\n')
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	_, err = t.writer.Write(data)
+	return err
+}
+
+// Close implements Transport.Close. The close handler runs at most once.
+func (t *StdioClientTransport) Close() error {
+	t.closeOnce.Do(func() {
+		t.mu.Lock()
+		handler := t.closeHandler
+		t.mu.Unlock()
+		if handler != nil {
+			handler()
+		}
+	})
+	return nil
+}
+
+// SetCloseHandler implements Transport.SetCloseHandler
+func (t *StdioClientTransport) SetCloseHandler(handler func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closeHandler = handler
+}
+
+// SetErrorHandler implements Transport.SetErrorHandler
+func (t *StdioClientTransport) SetErrorHandler(handler func(error)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.errorHandler = handler
+}
+
+// SetMessageHandler implements Transport.SetMessageHandler
+func (t *StdioClientTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messageHandler = handler
+}
+
 // CleaningStdioTransport wraps a transport and removes null values from params
 type CleaningStdioTransport struct {
 	inner transport.Transport
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// StressResult is the outcome of a stress run against one tool
+type StressResult struct {
+	Server     string         `json:"server"`
+	Tool       string         `json:"tool"`
+	Parallel   int            `json:"parallel"`
+	Requests   int            `json:"requests"`
+	Errors     int            `json:"errors"`
+	ErrorRate  float64        `json:"errorRate"`
+	Throughput float64        `json:"throughput"`
+	DurationMs float64        `json:"durationMs"`
+	ErrorKinds map[string]int `json:"errorKinds,omitempty"`
+	LatencyStats
+}
+
+func newStressCmd() *cobra.Command {
+	var tool, args string
+	var parallel, n int
+	var duration time.Duration
+	stressCmd := &cobra.Command{
+		Use:   "stress <server-name> --tool <name>",
+		Short: "Fire concurrent tool calls at a server and report throughput and errors",
+		Long: `Call a tool from several workers at once over a single session, the way a
+client issues bursts of parallel tool calls, and report throughput, error
+rate and the latency distribution.
+
+The run lasts for --duration, or until -n calls have completed when -n is
+given. Press Ctrl+C to stop early and report what was measured so far.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, cmdArgs []string) error {
+			if parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1")
+			}
+			if n < 0 || duration <= 0 {
+				return fmt.Errorf("-n and --duration must be positive")
+			}
+			var arguments map[string]interface{}
+			if args != "" {
+				if err := json.Unmarshal([]byte(args), &arguments); err != nil {
+					return fmt.Errorf("invalid --args: %w", err)
+				}
+			}
+
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return runStress(config, cmdArgs[0], tool, arguments, parallel, n, duration)
+		},
+	}
+	stressCmd.Flags().StringVar(&tool, "tool", "", "tool to call")
+	stressCmd.Flags().StringVar(&args, "args", "", "tool arguments as a JSON object")
+	stressCmd.Flags().IntVar(&parallel, "parallel", 10, "number of calls in flight at once")
+	stressCmd.Flags().IntVarP(&n, "n", "n", 0, "stop after this many calls instead of after --duration")
+	stressCmd.Flags().DurationVar(&duration, "duration", 10*time.Second, "how long to keep calling")
+	stressCmd.MarkFlagRequired("tool")
+	return stressCmd
+}
+
+func runStress(config *ClaudeConfig, serverName, tool string, arguments map[string]interface{}, parallel, n int, duration time.Duration) error {
+	// Ctrl+C cancels calls in flight; the connection itself lives until the run ends
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	request := toolCallRequest(conn, tool, arguments)
+	result := &StressResult{Server: serverName, Tool: tool, Parallel: parallel, ErrorKinds: map[string]int{}}
+
+	var mu sync.Mutex
+	var latencies []time.Duration
+	started := 0
+	deadline := time.Now().Add(duration)
+
+	// next claims the next call, or reports that the run is over
+	next := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if ctx.Err() != nil {
+			return false
+		}
+		if n > 0 {
+			if started >= n {
+				return false
+			}
+		} else if time.Now().After(deadline) {
+			return false
+		}
+		started++
+		return true
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < parallel; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for next() {
+				latency, err := timeRequest(ctx, request)
+				if ctx.Err() != nil {
+					// Interrupted: the call did not fail on its own, so leave it out
+					return
+				}
+
+				mu.Lock()
+				latencies = append(latencies, latency)
+				if err != nil {
+					result.Errors++
+					result.ErrorKinds[err.Error()]++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result.Requests = len(latencies)
+	result.DurationMs = durationMs(elapsed)
+	if result.Requests > 0 {
+		result.ErrorRate = float64(result.Errors) / float64(result.Requests)
+		result.Throughput = float64(result.Requests) / elapsed.Seconds()
+	}
+	result.LatencyStats = newLatencyStats(latencies)
+
+	return printStressResult(conn, result)
+}
+
+func printStressResult(conn *Connection, r *StressResult) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(r)
+	case outputCSV:
+		return writeCSV(
+			[]string{"SERVER", "TOOL", "PARALLEL", "REQUESTS", "ERRORS", "ERROR RATE", "REQ/S", "MIN MS", "AVG MS", "P50 MS", "P95 MS", "P99 MS", "MAX MS"},
+			[][]string{{r.Server, r.Tool, strconv.Itoa(r.Parallel), strconv.Itoa(r.Requests), strconv.Itoa(r.Errors),
+				strconv.FormatFloat(r.ErrorRate, 'f', 4, 64), strconv.FormatFloat(r.Throughput, 'f', 1, 64),
+				formatMs(r.MinMs), formatMs(r.AvgMs), formatMs(r.P50Ms), formatMs(r.P95Ms), formatMs(r.P99Ms), formatMs(r.MaxMs)}},
+		)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "REQUESTS\tERRORS\tERROR RATE\tREQ/S\tMIN\tAVG\tP50\tP95\tP99\tMAX")
+	fmt.Fprintf(w, "%d\t%d\t%.1f%%\t%.1f\t%sms\t%sms\t%sms\t%sms\t%sms\t%sms\n", r.Requests, r.Errors, r.ErrorRate*100, r.Throughput,
+		formatMs(r.MinMs), formatMs(r.AvgMs), formatMs(r.P50Ms), formatMs(r.P95Ms), formatMs(r.P99Ms), formatMs(r.MaxMs))
+	w.Flush()
+
+	if len(r.ErrorKinds) > 0 {
+		kinds := make([]string, 0, len(r.ErrorKinds))
+		for kind := range r.ErrorKinds {
+			kinds = append(kinds, kind)
+		}
+		sort.Slice(kinds, func(i, j int) bool {
+			if r.ErrorKinds[kinds[i]] != r.ErrorKinds[kinds[j]] {
+				return r.ErrorKinds[kinds[i]] > r.ErrorKinds[kinds[j]]
+			}
+			return kinds[i] < kinds[j]
+		})
+
+		fmt.Println()
+		fmt.Println("Errors:")
+		for _, kind := range kinds {
+			fmt.Printf("  %6d  %s\n", r.ErrorKinds[kind], kind)
+		}
+	}
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%d calls to %s in %s with %d in flight | %s | %s\n", r.Requests, r.Tool,
+		time.Duration(r.DurationMs*float64(time.Millisecond)).Round(time.Millisecond), r.Parallel, conn.Server.Type, formatServerInfo(conn.Init))
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ResourceUpdate is one notifications/resources/updated received from a server
+type ResourceUpdate struct {
+	Time time.Time `json:"time"`
+	URI  string    `json:"uri"`
+}
+
+func newSubscribeCmd() *cobra.Command {
+	var read bool
+	subscribeCmd := &cobra.Command{
+		Use:   "subscribe <server-name> <uri>...",
+		Short: "Subscribe to resources and print update notifications as they arrive",
+		Long: `Subscribe to one or more resources with resources/subscribe and keep the
+session open, printing a timestamped line for every
+notifications/resources/updated the server sends. With --read the resource
+is read again after each update and its new contents printed. With
+--output json each update is written as a JSON object.
+
+Press Ctrl+C to unsubscribe and stop.`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// A dropped connection is a result, not a usage error
+			cmd.SilenceUsage = true
+			return subscribeResources(config, args[0], args[1:], read)
+		},
+	}
+	subscribeCmd.Flags().BoolVar(&read, "read", false, "read and print the resource again after each update")
+	return subscribeCmd
+}
+
+func subscribeResources(config *ClaudeConfig, serverName string, uris []string, read bool) error {
+	// The connection lives until interrupted, so only individual requests get a timeout
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	resources, _ := conn.Capabilities()[surfaceResources].(map[string]interface{})
+	if subscribe, _ := resources["subscribe"].(bool); !subscribe {
+		fmt.Fprintf(os.Stderr, "Warning: %s does not advertise resources.subscribe; the subscription may be rejected\n", serverName)
+	}
+
+	updates := make(chan ResourceUpdate, 64)
+	conn.Session.OnNotification("notifications/resources/updated", func(params json.RawMessage) {
+		var update ResourceUpdate
+		json.Unmarshal(params, &update)
+		update.Time = time.Now()
+		select {
+		case updates <- update:
+		default:
+		}
+	})
+
+	for _, uri := range uris {
+		if err := resourceRequest(ctx, conn, "resources/subscribe", uri); err != nil {
+			return fmt.Errorf("failed to subscribe to %s: %w", uri, err)
+		}
+	}
+	defer func() {
+		// Unsubscribe politely; the interrupted ctx can no longer be used
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		for _, uri := range uris {
+			resourceRequest(ctx, conn, "resources/unsubscribe", uri)
+		}
+	}()
+
+	fmt.Fprintf(os.Stderr, "Subscribed to %d resources on %s. Press Ctrl+C to stop.\n", len(uris), serverName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Session.Done():
+			return fmt.Errorf("connection to %s closed", serverName)
+		case update := <-updates:
+			if outputFormat == outputJSON {
+				if err := writeJSON(update); err != nil {
+					return err
+				}
+			} else {
+				fmt.Printf("[%s] %s updated\n", update.Time.Format("15:04:05"), update.URI)
+			}
+			if !read {
+				continue
+			}
+
+			if err := printResource(ctx, conn, update.URI, ""); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", update.URI, err)
+			}
+		}
+	}
+}
+
+// resourceRequest sends a request whose only param is a resource URI, such as resources/subscribe
+func resourceRequest(ctx context.Context, conn *Connection, method, uri string) error {
+	_, err := conn.Session.Request(ctx, method, map[string]string{"uri": uri})
+	return err
+}
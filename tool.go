@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ToolDetail is everything a server reports about a single tool
+type ToolDetail struct {
+	ServerHeader
+	Tool ToolInfo `json:"tool"`
+}
+
+func inspectTool(config *ClaudeConfig, serverName, toolName string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	tools, err := listTools(ctx, conn)
+	if err != nil {
+		return err
+	}
+	verifyPin(conn, tools)
+	for _, tool := range tools {
+		if tool.Name == toolName {
+			return printToolDetail(conn, &ToolDetail{ServerHeader: newServerHeader(conn), Tool: tool})
+		}
+	}
+	return fmt.Errorf("tool '%s' not found on %s", toolName, serverName)
+}
+
+func printToolDetail(conn *Connection, detail *ToolDetail) error {
+	tool := detail.Tool
+
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(detail)
+	case outputCSV:
+		outputSchema := ""
+		if tool.OutputSchema != nil {
+			outputSchema = compactJSON(tool.OutputSchema)
+		}
+		annotations := ""
+		if tool.Annotations != nil {
+			annotations = compactJSON(tool.Annotations)
+		}
+		return writeCSV(
+			[]string{"SERVER", "TOOL", "DESCRIPTION", "INPUT SCHEMA", "OUTPUT SCHEMA", "ANNOTATIONS"},
+			[][]string{{detail.Name, tool.Name, tool.Description, compactJSON(tool.InputSchema), outputSchema, annotations}},
+		)
+	}
+
+	fmt.Println(tool.Name)
+	if hints := toolHints(tool.Annotations); len(hints) > 0 {
+		fmt.Printf("[%s]\n", strings.Join(hints, "] ["))
+	}
+	if tool.Description != "" {
+		fmt.Println()
+		fmt.Println(strings.TrimSpace(tool.Description))
+	}
+
+	fmt.Println()
+	fmt.Println("Parameters:")
+	if params := schemaParams(tool.InputSchema); len(params) > 0 {
+		printSchemaTable(params)
+	} else {
+		fmt.Println("  No parameters")
+	}
+
+	fmt.Println()
+	fmt.Println("Input schema:")
+	fmt.Println(indentBlock(indentJSON(tool.InputSchema)))
+
+	fmt.Println()
+	fmt.Println("Output schema:")
+	if tool.OutputSchema != nil {
+		fmt.Println(indentBlock(indentJSON(tool.OutputSchema)))
+	} else {
+		fmt.Println("  [N/A]")
+	}
+
+	fmt.Println()
+	fmt.Println("Annotations:")
+	if len(tool.Annotations) > 0 {
+		names := make([]string, 0, len(tool.Annotations))
+		for name := range tool.Annotations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("  %s: %s\n", name, compactJSON(tool.Annotations[name]))
+		}
+	} else {
+		fmt.Println("  [N/A]")
+	}
+
+	// Print summary
+	fmt.Println()
+	fmt.Printf("%s | %s | %s\n", detail.Name, conn.Server.Type, formatServerInfo(conn.Init))
+	return nil
+}
+
+// toolHintLabels maps boolean tool annotation hints to the labels shown when they are set.
+// Hints that contradict the spec's defaults are shown when false, since that is the notable case.
+var toolHintLabels = []struct {
+	Hint  string
+	Value bool
+	Label string
+}{
+	{"readOnlyHint", true, "read-only"},
+	{"destructiveHint", true, "destructive"},
+	{"destructiveHint", false, "non-destructive"},
+	{"idempotentHint", true, "idempotent"},
+	{"openWorldHint", true, "open-world"},
+	{"openWorldHint", false, "closed-world"},
+}
+
+// toolHints returns short labels for the behavior hints a tool's annotations declare
+func toolHints(annotations map[string]interface{}) []string {
+	hints := []string{}
+	for _, h := range toolHintLabels {
+		if value, ok := annotations[h.Hint].(bool); ok && value == h.Value {
+			hints = append(hints, h.Label)
+		}
+	}
+	return hints
+}
+
+// indentBlock indents every line of s by two spaces
+func indentBlock(s string) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "  " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// traceEnabled is set by --trace
+var traceEnabled bool
+
+// traceMu serializes trace output from concurrently contacted servers
+var traceMu sync.Mutex
+
+// TracingTransport wraps a transport and prints every message sent and
+// received, pretty-printed with a timestamp and direction arrow
+type TracingTransport struct {
+	inner transport.Transport
+	name  string
+	w     io.Writer
+}
+
+// NewTracingTransport creates a tracing wrapper that writes to w, labelling messages with the server name
+func NewTracingTransport(inner transport.Transport, name string, w io.Writer) *TracingTransport {
+	return &TracingTransport{inner: inner, name: name, w: w}
+}
+
+// trace writes one message. Outgoing messages use "->", incoming "<-".
+func (t *TracingTransport) trace(arrow string, message *transport.BaseJsonRpcMessage) {
+	data, err := json.MarshalIndent(message, "", "  ")
+	if err != nil {
+		data = []byte(fmt.Sprintf("<unencodable message: %v>", err))
+	}
+
+	traceMu.Lock()
+	defer traceMu.Unlock()
+	fmt.Fprintf(t.w, "[%s] %s %s %s\n%s\n", time.Now().Format("15:04:05.000"), t.name, arrow, describeMessage(message), data)
+}
+
+// describeMessage summarizes a message's kind, method and id for the trace header
+func describeMessage(message *transport.BaseJsonRpcMessage) string {
+	switch message.Type {
+	case transport.BaseMessageTypeJSONRPCRequestType:
+		return fmt.Sprintf("request %s (id %d)", message.JsonRpcRequest.Method, message.JsonRpcRequest.Id)
+	case transport.BaseMessageTypeJSONRPCNotificationType:
+		return fmt.Sprintf("notification %s", message.JsonRpcNotification.Method)
+	case transport.BaseMessageTypeJSONRPCResponseType:
+		return fmt.Sprintf("response (id %d)", message.JsonRpcResponse.Id)
+	case transport.BaseMessageTypeJSONRPCErrorType:
+		return fmt.Sprintf("error (id %d)", message.JsonRpcError.Id)
+	default:
+		return string(message.Type)
+	}
+}
+
+// Start implements Transport.Start
+func (t *TracingTransport) Start(ctx context.Context) error {
+	return t.inner.Start(ctx)
+}
+
+// Send implements Transport.Send, tracing the outgoing message
+func (t *TracingTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	t.trace("->", message)
+	return t.inner.Send(ctx, message)
+}
+
+// Close implements Transport.Close
+func (t *TracingTransport) Close() error {
+	return t.inner.Close()
+}
+
+// SetCloseHandler implements Transport.SetCloseHandler
+func (t *TracingTransport) SetCloseHandler(handler func()) {
+	t.inner.SetCloseHandler(handler)
+}
+
+// SetErrorHandler implements Transport.SetErrorHandler
+func (t *TracingTransport) SetErrorHandler(handler func(error)) {
+	t.inner.SetErrorHandler(handler)
+}
+
+// SetMessageHandler implements Transport.SetMessageHandler, tracing each incoming message
+func (t *TracingTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.inner.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		t.trace("<-", message)
+		handler(ctx, message)
+	})
+}
+
+// traceTransport wraps tr in a TracingTransport writing to stderr when --trace is set
+func traceTransport(tr transport.Transport, name string) transport.Transport {
+	if !traceEnabled {
+		return tr
+	}
+	return NewTracingTransport(tr, name, os.Stderr)
+}
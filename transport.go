@@ -5,11 +5,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/metoro-io/mcp-golang/transport"
 )
@@ -23,15 +28,34 @@ type SSEClientTransport struct {
 	mu             sync.RWMutex
 	client         *http.Client
 	headers        map[string]string
+	refreshAuth    authRefresher
 	sessionID      string // MCP session ID from server
+	log            *slog.Logger
+
+	// The GET stream of server-initiated messages, opened after initialize
+	streamCancel context.CancelFunc
+	lastEventID  string
+	retryDelay   time.Duration
+	closed       bool
 }
 
+// sessionDeleteTimeout bounds the DELETE request that ends a session on Close
+const sessionDeleteTimeout = 5 * time.Second
+
+// errNoListenStream means the server offers no GET stream
+var errNoListenStream = errors.New("server offers no GET stream")
+
+// authRefresher returns a new Authorization header value after the server
+// rejected the current one
+type authRefresher func(ctx context.Context) (string, error)
+
 // NewSSEClientTransport creates a new SSE-aware HTTP client transport
 func NewSSEClientTransport(baseURL string) *SSEClientTransport {
 	return &SSEClientTransport{
 		baseURL: baseURL,
 		client:  &http.Client{},
 		headers: make(map[string]string),
+		log:     logger,
 	}
 }
 
@@ -41,6 +65,40 @@ func (t *SSEClientTransport) WithHeader(key, value string) *SSEClientTransport {
 	return t
 }
 
+// WithClient replaces the HTTP client used for requests
+func (t *SSEClientTransport) WithClient(client *http.Client) *SSEClientTransport {
+	t.client = client
+	return t
+}
+
+// WithAuthRefresh makes requests answered with 401 retry once with a refreshed Authorization header
+func (t *SSEClientTransport) WithAuthRefresh(refresh authRefresher) *SSEClientTransport {
+	t.refreshAuth = refresh
+	return t
+}
+
+// WithLogger sets the logger of -v
+func (t *SSEClientTransport) WithLogger(log *slog.Logger) *SSEClientTransport {
+	t.log = log
+	return t
+}
+
+// setHeaders copies the transport's headers onto a request
+func (t *SSEClientTransport) setHeaders(req *http.Request) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// setAuth replaces the Authorization header after a refresh
+func (t *SSEClientTransport) setAuth(auth string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.headers["Authorization"] = auth
+}
+
 // Start implements Transport.Start
 func (t *SSEClientTransport) Start(ctx context.Context) error {
 	return nil
@@ -56,41 +114,57 @@ func (t *SSEClientTransport) Send(ctx context.Context, message *transport.BaseJs
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL, bytes.NewBuffer(jsonData))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "application/json, text/event-stream")
-	for key, value := range t.headers {
-		req.Header.Set(key, value)
-	}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+		t.setHeaders(req)
 
-	// Add session ID if we have one
-	t.mu.RLock()
-	sessionID := t.sessionID
-	t.mu.RUnlock()
-	if sessionID != "" {
-		req.Header.Set("Mcp-Session-Id", sessionID)
+		// Add session ID if we have one
+		t.mu.RLock()
+		sessionID := t.sessionID
+		t.mu.RUnlock()
+		if sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", sessionID)
+		}
+		return req, nil
 	}
 
-	resp, err := t.client.Do(req)
+	resp, err := sendWithRefresh(ctx, t.log, t.client, build, t.refreshAuth, t.setAuth)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
 	// Capture session ID from response
 	if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" {
 		t.mu.Lock()
+		changed := t.sessionID != sid
 		t.sessionID = sid
 		t.mu.Unlock()
+		if changed {
+			t.log.Info("session started", "session", sid)
+		}
+	}
+
+	// Once the initialized notification is posted, listen for messages the
+	// server sends on its own; servers reject the GET stream before that
+	if message.JsonRpcNotification != nil && message.JsonRpcNotification.Method == "notifications/initialized" &&
+		(resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusOK) {
+		defer t.listen()
 	}
 
+	// Notifications and responses to server requests are only acknowledged
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("server returned error: %s (status: %d)", string(body), resp.StatusCode)
+		return &statusError{what: "server returned error", status: resp.StatusCode, body: string(body)}
 	}
 
 	// Check if response is SSE
@@ -112,15 +186,181 @@ func (t *SSEClientTransport) Send(ctx context.Context, message *transport.BaseJs
 	return nil
 }
 
+// sendWithRefresh sends the request build returns. When the server answers
+// 401 and refresh renews the Authorization header, setAuth stores the new
+// value and the request is built and sent once more.
+func sendWithRefresh(ctx context.Context, log *slog.Logger, client *http.Client, build func() (*http.Request, error), refresh authRefresher, setAuth func(string)) (*http.Response, error) {
+	req, err := build()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doLogged(log, client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusUnauthorized || refresh == nil {
+		return resp, nil
+	}
+
+	log.Info("server rejected the token, refreshing it")
+	auth, err := refresh(ctx)
+	if err != nil {
+		log.Info("token refresh failed", "error", err)
+		// The 401 is the more useful error
+		return resp, nil
+	}
+	resp.Body.Close()
+	setAuth(auth)
+	if req, err = build(); err != nil {
+		return nil, err
+	}
+	resp, err = doLogged(log, client, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// doLogged sends an HTTP request, logging it with its headers and the
+// response status at -vv. For event streams the time is until the headers.
+func doLogged(log *slog.Logger, client *http.Client, req *http.Request) (*http.Response, error) {
+	log.Debug("HTTP request", "method", req.Method, "url", req.URL.Redacted(), headersAttr(req.Header))
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Debug("HTTP request failed", "method", req.Method, "url", req.URL.Redacted(), "elapsed", time.Since(start), "error", err)
+		return nil, err
+	}
+	log.Debug("HTTP response", "method", req.Method, "status", resp.StatusCode, "content_type", resp.Header.Get("Content-Type"), "elapsed", time.Since(start))
+	return resp, nil
+}
+
+// listen opens the GET stream on which the server sends requests and
+// notifications outside of any POST, such as list_changed notifications, log
+// messages and sampling requests. The stream is reopened with backoff and
+// Last-Event-ID when it drops; a server answering 405 offers none.
+func (t *SSEClientTransport) listen() {
+	t.mu.Lock()
+	if t.streamCancel != nil || t.closed {
+		t.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.streamCancel = cancel
+	t.mu.Unlock()
+
+	go func() {
+		failures := 0
+		for {
+			connected, err := t.readListenStream(ctx)
+			if ctx.Err() != nil || errors.Is(err, errNoListenStream) {
+				return
+			}
+			if errors.Is(err, bufio.ErrTooLong) {
+				// Resuming would only receive the same event again
+				fmt.Fprintf(os.Stderr, "Warning: GET stream of %s closed: %v\n", t.baseURL, err)
+				return
+			}
+			t.log.Info("GET stream dropped, reconnecting", "error", err)
+			if connected {
+				failures = 0
+			} else if failures++; failures >= sseMaxReconnects {
+				fmt.Fprintf(os.Stderr, "Warning: GET stream of %s lost after %d reconnection attempts: %v\n", t.baseURL, sseMaxReconnects, err)
+				return
+			}
+
+			t.mu.RLock()
+			delay := t.retryDelay
+			t.mu.RUnlock()
+			if delay <= 0 {
+				delay = sseReconnectDelay
+			}
+			for i := 1; i < failures; i++ {
+				delay = min(delay*2, sseMaxReconnectDelay)
+			}
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// readListenStream opens the GET stream and dispatches its events until it
+// ends, reporting whether it was opened
+func (t *SSEClientTransport) readListenStream(ctx context.Context) (bool, error) {
+	build := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.baseURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		t.setHeaders(req)
+		t.mu.RLock()
+		if t.sessionID != "" {
+			req.Header.Set("Mcp-Session-Id", t.sessionID)
+		}
+		if t.lastEventID != "" {
+			req.Header.Set("Last-Event-ID", t.lastEventID)
+		}
+		t.mu.RUnlock()
+		return req, nil
+	}
+
+	resp, err := sendWithRefresh(ctx, t.log, t.client, build, t.refreshAuth, t.setAuth)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		t.log.Info("server offers no GET stream")
+		return false, errNoListenStream
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("GET stream failed (status: %d)", resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.Contains(contentType, "text/event-stream") {
+		t.log.Info("server offers no GET stream", "content_type", contentType)
+		return false, errNoListenStream
+	}
+	t.log.Info("GET stream opened")
+
+	scanner := newSSEScanner(resp.Body)
+	var dataLines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "id:"):
+			t.mu.Lock()
+			t.lastEventID = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+			t.mu.Unlock()
+		case strings.HasPrefix(line, "retry:"):
+			if ms, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "retry:"))); err == nil && ms > 0 {
+				t.mu.Lock()
+				t.retryDelay = time.Duration(ms) * time.Millisecond
+				t.mu.Unlock()
+			}
+		case line == "" && len(dataLines) > 0:
+			// A malformed message must not end the stream
+			t.handleJSONResponse(ctx, []byte(strings.Join(dataLines, "\n")))
+			dataLines = nil
+		}
+	}
+	return true, sseScanError(scanner.Err())
+}
+
 func (t *SSEClientTransport) parseSSEResponse(ctx context.Context, reader io.Reader) error {
-	scanner := bufio.NewScanner(reader)
+	scanner := newSSEScanner(reader)
 	var dataLines []string
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		if strings.HasPrefix(line, "data: ") {
-			dataLines = append(dataLines, strings.TrimPrefix(line, "data: "))
+		if strings.HasPrefix(line, "data:") {
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
 		} else if line == "" && len(dataLines) > 0 {
 			// End of event, process accumulated data
 			data := strings.Join(dataLines, "\n")
@@ -137,7 +377,7 @@ func (t *SSEClientTransport) parseSSEResponse(ctx context.Context, reader io.Rea
 		return t.handleJSONResponse(ctx, []byte(data))
 	}
 
-	return scanner.Err()
+	return sseScanError(scanner.Err())
 }
 
 func (t *SSEClientTransport) handleJSONResponse(ctx context.Context, body []byte) error {
@@ -149,35 +389,61 @@ func (t *SSEClientTransport) handleJSONResponse(ctx context.Context, body []byte
 		return nil
 	}
 
-	// Try to unmarshal as response
-	var response transport.BaseJSONRPCResponse
-	if err := json.Unmarshal(body, &response); err == nil && response.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageResponse(&response))
-		return nil
+	message, err := decodeMessage(body)
+	if err != nil {
+		return fmt.Errorf("received invalid response: %s", string(body))
 	}
+	handler(ctx, message)
+	return nil
+}
 
-	// Try as error
-	var errorResponse transport.BaseJSONRPCError
-	if err := json.Unmarshal(body, &errorResponse); err == nil && errorResponse.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageError(&errorResponse))
-		return nil
+// decodeMessage classifies a raw JSON-RPC message by the members it carries.
+// Requests have a method and id, notifications a method only, and responses
+// a result or error. Notification params are kept, unlike mcp-golang's decoder.
+func decodeMessage(data []byte) (*transport.BaseJsonRpcMessage, error) {
+	var probe struct {
+		Jsonrpc string           `json:"jsonrpc"`
+		ID      *json.RawMessage `json:"id"`
+		Method  *string          `json:"method"`
+		Params  json.RawMessage  `json:"params"`
+		Result  *json.RawMessage `json:"result"`
+		Error   *json.RawMessage `json:"error"`
 	}
-
-	// Try as notification
-	var notification transport.BaseJSONRPCNotification
-	if err := json.Unmarshal(body, &notification); err == nil && notification.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageNotification(&notification))
-		return nil
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
 	}
-
-	// Try as request
-	var request transport.BaseJSONRPCRequest
-	if err := json.Unmarshal(body, &request); err == nil && request.Jsonrpc != "" {
-		handler(ctx, transport.NewBaseMessageRequest(&request))
-		return nil
+	if probe.Jsonrpc == "" {
+		return nil, fmt.Errorf("missing jsonrpc version")
 	}
 
-	return fmt.Errorf("received invalid response: %s", string(body))
+	switch {
+	case probe.Method != nil && probe.ID != nil:
+		var request transport.BaseJSONRPCRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, err
+		}
+		return transport.NewBaseMessageRequest(&request), nil
+	case probe.Method != nil:
+		return transport.NewBaseMessageNotification(&transport.BaseJSONRPCNotification{
+			Jsonrpc: probe.Jsonrpc,
+			Method:  *probe.Method,
+			Params:  probe.Params,
+		}), nil
+	case probe.Error != nil:
+		var errorResponse transport.BaseJSONRPCError
+		if err := json.Unmarshal(data, &errorResponse); err != nil {
+			return nil, err
+		}
+		return transport.NewBaseMessageError(&errorResponse), nil
+	case probe.Result != nil:
+		var response transport.BaseJSONRPCResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		return transport.NewBaseMessageResponse(&response), nil
+	default:
+		return nil, fmt.Errorf("unrecognized JSON-RPC message")
+	}
 }
 
 // cleanMessage removes null values from request params
@@ -210,14 +476,48 @@ func (t *SSEClientTransport) cleanMessage(msg *transport.BaseJsonRpcMessage) *tr
 	return msg
 }
 
-// Close implements Transport.Close
+// Close implements Transport.Close, terminating the server's session
 func (t *SSEClientTransport) Close() error {
-	if t.closeHandler != nil {
-		t.closeHandler()
+	t.mu.Lock()
+	wasClosed := t.closed
+	t.closed = true
+	if t.streamCancel != nil {
+		t.streamCancel()
+	}
+	sessionID := t.sessionID
+	closeHandler := t.closeHandler
+	t.mu.Unlock()
+
+	if sessionID != "" && !wasClosed {
+		t.endSession(sessionID)
+	}
+	if closeHandler != nil {
+		closeHandler()
 	}
 	return nil
 }
 
+// endSession sends the DELETE request that tells the server to drop a
+// session. Servers may refuse it with 405, and failures are ignored since
+// the session expires on the server eventually.
+func (t *SSEClientTransport) endSession(sessionID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), sessionDeleteTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.baseURL, nil)
+	if err != nil {
+		return
+	}
+	t.setHeaders(req)
+	req.Header.Set("Mcp-Session-Id", sessionID)
+	resp, err := doLogged(t.log, t.client, req)
+	if err != nil {
+		t.log.Info("failed to end session", "session", sessionID, "error", err)
+		return
+	}
+	resp.Body.Close()
+	t.log.Info("session ended", "session", sessionID, "status", resp.StatusCode)
+}
+
 // SetCloseHandler implements Transport.SetCloseHandler
 func (t *SSEClientTransport) SetCloseHandler(handler func()) {
 	t.mu.Lock()
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// verbosity counts the -v flags: -v logs the connection lifecycle, session
+// IDs, retries and request timing, -vv adds every HTTP request and the
+// headers sent
+var verbosity int
+
+// logger is the root logger of -v. Transports get a child naming their server;
+// without -v everything is discarded.
+var logger = slog.New(slog.DiscardHandler)
+
+// setupLogger points logger at stderr at the level -v selects
+func setupLogger() {
+	if verbosity == 0 {
+		return
+	}
+	level := slog.LevelInfo
+	if verbosity > 1 {
+		level = slog.LevelDebug
+	}
+	logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// The time of day is enough next to the durations that are logged
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.String(slog.TimeKey, a.Value.Time().Format("15:04:05.000"))
+			}
+			return a
+		},
+	}))
+}
+
+// serverLogger returns the logger of one server's connection
+func serverLogger(serverName string) *slog.Logger {
+	return logger.With("server", serverName)
+}
+
+// secretHeaders are logged with their value redacted
+var secretHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// headersAttr renders the headers of a request for the debug log. Credentials
+// keep only their scheme, e.g. "Bearer [redacted]".
+func headersAttr(header http.Header) slog.Attr {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	attrs := make([]any, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if slices.Contains(secretHeaders, http.CanonicalHeaderKey(name)) {
+			value = redactCredential(value)
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// redactCredential hides a credential but keeps its scheme
+func redactCredential(value string) string {
+	if scheme, _, ok := strings.Cut(value, " "); ok {
+		return scheme + " [redacted]"
+	}
+	return "[redacted]"
+}
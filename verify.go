@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// osvQueryURL is the OSV.dev endpoint listing the advisories of a package
+// version, including the malicious packages reported to OSV as MAL-* entries
+var osvQueryURL = "https://api.osv.dev/v1/query"
+
+// osvEcosystems are OSV's names of the package ecosystems
+var osvEcosystems = map[string]string{ecosystemNpm: "npm", ecosystemPyPI: "PyPI"}
+
+// Statuses of a verified server
+const (
+	verifyVerified     = "verified"
+	verifyUnregistered = "unregistered"
+	verifyLocal        = "local"
+)
+
+// RegistryVerification is how a configured server compares with the registry
+type RegistryVerification struct {
+	Server   string        `json:"server"`
+	Status   string        `json:"status"`
+	Registry string        `json:"registry,omitempty"`
+	Package  string        `json:"package,omitempty"`
+	Version  string        `json:"version,omitempty"`
+	Issues   []VerifyIssue `json:"issues"`
+}
+
+// VerifyIssue is a problem found with a server's package or registry entry
+type VerifyIssue struct {
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// VerifyReport is the result of verifying one or more servers
+type VerifyReport struct {
+	Servers []RegistryVerification `json:"servers"`
+	Errors  map[string]string      `json:"errors,omitempty"`
+}
+
+func newRegistryVerifyCmd() *cobra.Command {
+	var all bool
+	verifyCmd := &cobra.Command{
+		Use:   "verify [server-name]",
+		Short: "Check configured servers against the registry and known-malicious packages",
+		Long: `Look up configured servers in the registry, by their name and package name,
+and match them by npm or PyPI package, OCI image or remote URL. Each server is
+checked for:
+
+  high    a registry entry that was deleted, a package one or two characters
+          off a registry server's package (a possible typosquat), or a
+          version OSV.dev reports as malicious
+  medium  a deprecated registry entry, or a version with known vulnerabilities
+  low     a pinned version the registry never published for the server
+
+Servers run from a local command are not looked up. Unpinned packages are
+checked at their latest version, unless --no-provenance is given. Use --all
+to verify every configured server. The command exits with code 7 if any
+high issue is found, unless --fail-on leaves out findings.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("specify either a server name or --all")
+			}
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+
+			var names []string
+			if all {
+				for _, info := range aggregateServers(config) {
+					names = append(names, info.Name)
+				}
+			} else {
+				names = args
+			}
+			// Issues are a result, not a usage error
+			cmd.SilenceUsage = true
+			return verifyServers(config, names)
+		},
+	}
+	verifyCmd.Flags().BoolVar(&all, "all", false, "verify every configured server")
+	return verifyCmd
+}
+
+func verifyServers(config *ClaudeConfig, names []string) error {
+	results := make([]RegistryVerification, len(names))
+	errs := make([]error, len(names))
+	runPool(len(names), concurrency, func(i int) {
+		results[i], errs[i] = verifyServer(config, names[i])
+	})
+
+	report := &VerifyReport{Servers: []RegistryVerification{}}
+	var failed []error
+	high := 0
+	for i, name := range names {
+		if errs[i] != nil {
+			failed = append(failed, errs[i])
+			if report.Errors == nil {
+				report.Errors = make(map[string]string)
+			}
+			report.Errors[name] = errs[i].Error()
+			continue
+		}
+		report.Servers = append(report.Servers, results[i])
+		for _, issue := range results[i].Issues {
+			if issue.Severity == auditHigh {
+				high++
+			}
+		}
+	}
+
+	if err := printVerify(report); err != nil {
+		return err
+	}
+	if high > 0 && failsOn(failFindings, true) {
+		return withExitCode(exitFindings, fmt.Errorf("%d high severity issues", high))
+	}
+	if len(failed) > 0 && failsOn(failUnreachable, true) {
+		return withExitCode(failureExitCode(failed), fmt.Errorf("%d of %d servers not verified", len(failed), len(names)))
+	}
+	return nil
+}
+
+// verifyServer finds the registry entry a server runs and checks it, its
+// package version and the packages of similarly named registry servers
+func verifyServer(config *ClaudeConfig, serverName string) (RegistryVerification, error) {
+	result := RegistryVerification{Server: serverName, Status: verifyLocal, Issues: []VerifyIssue{}}
+	server, err := findServer(config, serverName)
+	if err != nil {
+		return result, err
+	}
+	ref, isPackage := serverPackage(*server)
+	image := serverImage(*server)
+	if !isPackage && image == "" && server.URL == "" {
+		return result, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	result.Status = verifyUnregistered
+	terms := []string{serverName}
+	switch {
+	case isPackage:
+		result.Package = ref.Ecosystem + " " + ref.Name
+		terms = append(terms, installName(ref.Name[strings.LastIndex(ref.Name, "/")+1:]))
+	case image != "":
+		result.Package = "oci " + image
+		terms = append(terms, installName(imageName(image)[strings.LastIndex(imageName(image), "/")+1:]))
+	default:
+		terms = append(terms, hostTerm(server.URL))
+	}
+
+	candidates, err := registryCandidates(ctx, terms)
+	if err != nil {
+		return result, withExitCode(exitConnection, err)
+	}
+	if i := slices.IndexFunc(candidates, func(entry RegistryEntry) bool { return registryServerMatches(entry.Server, *server) }); i >= 0 {
+		entry := candidates[i]
+		result.Status = verifyVerified
+		result.Registry = entry.Server.Name
+		switch entry.Meta.Official.Status {
+		case "deleted":
+			result.Issues = append(result.Issues, VerifyIssue{auditHigh, "deleted", fmt.Sprintf("%s was deleted from the registry", entry.Server.Name)})
+		case "deprecated":
+			result.Issues = append(result.Issues, VerifyIssue{auditMedium, "deprecated", fmt.Sprintf("%s is deprecated in the registry", entry.Server.Name)})
+		}
+	} else if isPackage {
+		if issue, ok := typosquatIssue(ref, candidates); ok {
+			result.Issues = append(result.Issues, issue)
+		}
+	}
+	if !isPackage {
+		return result, nil
+	}
+
+	// Unpinned packages run whatever is the latest version when they start
+	version := strings.TrimPrefix(strings.TrimPrefix(ref.Requested, "=="), "v")
+	if !ref.pinned() {
+		version = ""
+		if provenance := lookupProvenance(ctx, *server); provenance != nil {
+			version = provenance.Version
+		}
+	}
+	result.Version = version
+	if version == "" {
+		return result, nil
+	}
+	if result.Registry != "" && ref.pinned() {
+		published, err := registryPublishes(ctx, result.Registry, ref, version)
+		if err != nil {
+			return result, withExitCode(exitConnection, err)
+		}
+		if !published {
+			result.Issues = append(result.Issues, VerifyIssue{auditLow, "unpublished-version",
+				fmt.Sprintf("%s publishes no version %s of %s", result.Registry, version, ref.Name)})
+		}
+	}
+	issues, err := osvIssues(ctx, ref, version)
+	if err != nil {
+		return result, withExitCode(exitConnection, err)
+	}
+	result.Issues = append(result.Issues, issues...)
+	return result, nil
+}
+
+// hostTerm is the registry search term of a remote server: the main label of
+// its host, such as linear for mcp.linear.app
+func hostTerm(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || net.ParseIP(u.Hostname()) != nil {
+		return ""
+	}
+	labels := strings.Split(u.Hostname(), ".")
+	for i := len(labels) - 2; i >= 0; i-- {
+		if !slices.Contains([]string{"www", "mcp", "api", "co", "com"}, labels[i]) {
+			return labels[i]
+		}
+	}
+	return ""
+}
+
+// registryCandidates searches the registry for each term, keeping each server once
+func registryCandidates(ctx context.Context, terms []string) ([]RegistryEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var candidates []RegistryEntry
+	seen := make(map[string]bool)
+	for _, term := range terms {
+		if term == "" || seen["term:"+term] {
+			continue
+		}
+		seen["term:"+term] = true
+		entries, err := searchRegistry(ctx, term, 50)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !seen[entry.Server.Name] {
+				seen[entry.Server.Name] = true
+				candidates = append(candidates, entry)
+			}
+		}
+	}
+	return candidates, nil
+}
+
+// typosquatIssue reports a package whose name is one or two edits away from
+// the package of a registry server, as typosquatting packages are
+func typosquatIssue(ref PackageRef, candidates []RegistryEntry) (VerifyIssue, bool) {
+	name := ref.Name
+	if ref.Ecosystem == ecosystemPyPI {
+		name = normalizePyPIName(name)
+	}
+	for _, entry := range candidates {
+		for _, pkg := range entry.Server.Packages {
+			if pkg.RegistryType != ref.Ecosystem {
+				continue
+			}
+			identifier := pkg.Identifier
+			if ref.Ecosystem == ecosystemPyPI {
+				identifier = normalizePyPIName(identifier)
+			}
+			if distance := editDistance(name, identifier); distance > 0 && distance <= 2 && len(identifier) > 4 {
+				return VerifyIssue{auditHigh, "typosquat", fmt.Sprintf("runs %s %s, but %s publishes %s", ref.Ecosystem, ref.Name, entry.Server.Name, pkg.Identifier)}, true
+			}
+		}
+	}
+	return VerifyIssue{}, false
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+// registryPublishes reports whether any version of a registry server runs the
+// given version of a package
+func registryPublishes(ctx context.Context, serverName string, ref PackageRef, version string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var result struct {
+		Servers []RegistryEntry `json:"servers"`
+	}
+	target := fmt.Sprintf("%s/v0/servers/%s/versions", strings.TrimSuffix(registryURL, "/"), url.PathEscape(serverName))
+	if err := getJSON(ctx, http.DefaultClient, target, &result); err != nil {
+		return false, fmt.Errorf("failed to list the versions of %s: %w", serverName, err)
+	}
+	for _, entry := range result.Servers {
+		for _, pkg := range entry.Server.Packages {
+			if pkg.RegistryType == ref.Ecosystem && normalizePyPIName(pkg.Identifier) == normalizePyPIName(ref.Name) && pkg.Version == version {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// osvIssues asks OSV.dev for the advisories of a package version: a MAL-*
+// entry marks a malicious release, any other a vulnerability
+func osvIssues(ctx context.Context, ref PackageRef, version string) ([]VerifyIssue, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	query := map[string]interface{}{
+		"version": version,
+		"package": map[string]string{"name": ref.Name, "ecosystem": osvEcosystems[ref.Ecosystem]},
+	}
+	var result struct {
+		Vulns []struct {
+			ID      string `json:"id"`
+			Summary string `json:"summary"`
+		} `json:"vulns"`
+	}
+	if err := postJSON(ctx, osvQueryURL, query, &result); err != nil {
+		return nil, fmt.Errorf("failed to query OSV: %w", err)
+	}
+
+	var issues []VerifyIssue
+	var vulnerabilities []string
+	for _, vuln := range result.Vulns {
+		if strings.HasPrefix(vuln.ID, "MAL-") {
+			issues = append(issues, VerifyIssue{auditHigh, "compromised", fmt.Sprintf("%s %s is reported as malicious (%s)", ref.Name, version, vuln.ID)})
+		} else {
+			vulnerabilities = append(vulnerabilities, vuln.ID)
+		}
+	}
+	if len(vulnerabilities) > 0 {
+		sort.Strings(vulnerabilities)
+		issues = append(issues, VerifyIssue{auditMedium, "vulnerable", fmt.Sprintf("%s %s has known vulnerabilities: %s", ref.Name, version, strings.Join(vulnerabilities, ", "))})
+	}
+	return issues, nil
+}
+
+// postJSON sends a JSON request and decodes the JSON answer
+func postJSON(ctx context.Context, target string, body, v interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// printVerify renders each server's registry match and issues
+func printVerify(report *VerifyReport) error {
+	switch outputFormat {
+	case outputJSON:
+		return writeJSON(report)
+	case outputCSV:
+		rows := [][]string{}
+		for _, r := range report.Servers {
+			if len(r.Issues) == 0 {
+				rows = append(rows, []string{r.Server, r.Status, r.Registry, r.Package, r.Version, "", "", ""})
+			}
+			for _, issue := range r.Issues {
+				rows = append(rows, []string{r.Server, r.Status, r.Registry, r.Package, r.Version, issue.Severity, issue.Rule, issue.Message})
+			}
+		}
+		return writeCSV([]string{"SERVER", "STATUS", "REGISTRY", "PACKAGE", "VERSION", "SEVERITY", "RULE", "MESSAGE"}, rows)
+	}
+
+	counts := make(map[string]int)
+	issues := 0
+	if len(report.Servers) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVER\tSTATUS\tREGISTRY\tPACKAGE\tVERSION\tISSUES")
+		for _, r := range report.Servers {
+			counts[r.Status]++
+			issues += len(r.Issues)
+			messages := make([]string, 0, len(r.Issues))
+			for _, issue := range r.Issues {
+				messages = append(messages, paintStatus(issue.Severity, issue.Severity)+": "+issue.Message)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Server, r.Status, cmp.Or(r.Registry, "-"), cmp.Or(r.Package, "-"), cmp.Or(r.Version, "-"), strings.Join(messages, "; "))
+		}
+		w.Flush()
+		fmt.Println()
+	}
+	names := make([]string, 0, len(report.Errors))
+	for name := range report.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "Warning: %s not verified: %s\n", name, report.Errors[name])
+	}
+
+	// Print summary
+	fmt.Printf("%d servers: %d in the registry, %d unregistered, %d local | %d issues\n", len(report.Servers),
+		counts[verifyVerified], counts[verifyUnregistered], counts[verifyLocal], issues)
+	return nil
+}
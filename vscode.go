@@ -0,0 +1,303 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"golang.org/x/term"
+)
+
+// vscodeVariablePattern matches VS Code's ${...} variables, e.g. ${input:api-key}
+var vscodeVariablePattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// VSCodeInput is an entry of the "inputs" list: a value VS Code asks the user
+// for when a server that references it as ${input:id} starts
+type VSCodeInput struct {
+	Type        string   `json:"type"`
+	ID          string   `json:"id"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Password    bool     `json:"password,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// vscodeMCPConfig is the layout of .vscode/mcp.json, and of the "mcp" key in settings.json
+type vscodeMCPConfig struct {
+	Servers map[string]MCPServer `json:"servers"`
+	Inputs  []VSCodeInput        `json:"inputs"`
+}
+
+var (
+	inputValuesMu sync.Mutex
+	// inputValues caches answers so each input is asked for once per run
+	inputValues = make(map[string]string)
+)
+
+// loadVSCodeConfig reads the "mcp" section of the user settings.json, the user
+// mcp.json next to it and the .vscode/mcp.json of the project containing the
+// working directory. Any of them may be missing.
+func loadVSCodeConfig(settingsPath string) (*ClaudeConfig, error) {
+	config := &ClaudeConfig{Projects: make(map[string]ProjectConfig)}
+	home, _ := os.UserHomeDir()
+
+	var settings struct {
+		MCP vscodeMCPConfig `json:"mcp"`
+	}
+	found, err := readJSONCFile(settingsPath, &settings)
+	if err != nil {
+		return nil, err
+	}
+	var user vscodeMCPConfig
+	userFound, err := readJSONCFile(filepath.Join(filepath.Dir(settingsPath), "mcp.json"), &user)
+	if err != nil {
+		return nil, err
+	}
+	if found || userFound {
+		servers := vscodeServers(settings.MCP, "", home)
+		for name, server := range vscodeServers(user, "", home) {
+			servers[name] = server
+		}
+		config.Projects[userScope] = ProjectConfig{MCPServers: withScope(servers, scopeUser)}
+	}
+
+	if projectFile, ok := findUp(filepath.Join(".vscode", "mcp.json")); ok {
+		var project vscodeMCPConfig
+		if _, err := readJSONCFile(projectFile, &project); err != nil {
+			return nil, err
+		}
+		projectPath := filepath.Dir(filepath.Dir(projectFile))
+		config.Projects[projectPath] = ProjectConfig{MCPServers: withScope(vscodeServers(project, projectPath, home), scopeProject)}
+	}
+
+	if len(config.Projects) == 0 {
+		return nil, fmt.Errorf("%w: %s has no mcp section and no .vscode/mcp.json was found above the working directory", errNoClientConfig, settingsPath)
+	}
+	return config, nil
+}
+
+// vscodeServers expands the variables VS Code resolves itself and attaches the
+// inputs each server references, which are only asked for when it is started
+func vscodeServers(file vscodeMCPConfig, workspace, home string) map[string]MCPServer {
+	inputs := make(map[string]VSCodeInput, len(file.Inputs))
+	for _, input := range file.Inputs {
+		inputs[input.ID] = input
+	}
+
+	servers := make(map[string]MCPServer, len(file.Servers))
+	for name, server := range file.Servers {
+		var referenced []VSCodeInput
+		expand := func(s string) string {
+			return vscodeVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+				variable := match[2 : len(match)-1]
+				switch {
+				case variable == "workspaceFolder" && workspace != "":
+					return workspace
+				case variable == "userHome" && home != "":
+					return home
+				case strings.HasPrefix(variable, "env:"):
+					return os.Getenv(strings.TrimPrefix(variable, "env:"))
+				case strings.HasPrefix(variable, "input:"):
+					if input, ok := inputs[strings.TrimPrefix(variable, "input:")]; ok {
+						referenced = append(referenced, input)
+					}
+				}
+				return match
+			})
+		}
+		server.Command = expand(server.Command)
+		server.URL = expand(server.URL)
+		server.EnvFile = expand(server.EnvFile)
+		server.Cwd = expand(server.Cwd)
+		args := make([]string, len(server.Args))
+		for i, arg := range server.Args {
+			args[i] = expand(arg)
+		}
+		server.Args = args
+		if server.Env != nil {
+			env := make(map[string]string, len(server.Env))
+			for key, value := range server.Env {
+				env[key] = expand(value)
+			}
+			server.Env = env
+		}
+		if server.Headers != nil {
+			headers := make(map[string]string, len(server.Headers))
+			for key, value := range server.Headers {
+				headers[key] = expand(value)
+			}
+			server.Headers = headers
+		}
+		server.inputs = referenced
+		servers[name] = server
+	}
+	return inferServerTypes(servers)
+}
+
+// resolveInputs replaces the ${input:id} placeholders of a VS Code server with
+// values typed by the user, the way VS Code asks for them on first start
+func resolveInputs(server *MCPServer) (*MCPServer, error) {
+	if len(server.inputs) == 0 {
+		return server, nil
+	}
+
+	values := make(map[string]string, len(server.inputs))
+	for _, input := range server.inputs {
+		value, err := askInput(input)
+		if err != nil {
+			return nil, err
+		}
+		values[input.ID] = value
+	}
+	replace := func(s string) string {
+		return vscodeVariablePattern.ReplaceAllStringFunc(s, func(match string) string {
+			if value, ok := values[strings.TrimPrefix(match[2:len(match)-1], "input:")]; ok {
+				return value
+			}
+			return match
+		})
+	}
+
+	resolved := *server
+	resolved.Command = replace(server.Command)
+	resolved.URL = replace(server.URL)
+	resolved.Args = make([]string, len(server.Args))
+	for i, arg := range server.Args {
+		resolved.Args[i] = replace(arg)
+	}
+	if server.Env != nil {
+		resolved.Env = make(map[string]string, len(server.Env))
+		for key, value := range server.Env {
+			resolved.Env[key] = replace(value)
+		}
+	}
+	if server.Headers != nil {
+		resolved.Headers = make(map[string]string, len(server.Headers))
+		for key, value := range server.Headers {
+			resolved.Headers[key] = replace(value)
+		}
+	}
+	resolved.inputs = nil
+	return &resolved, nil
+}
+
+// askInput prompts for an input value on the terminal, hiding passwords
+func askInput(input VSCodeInput) (string, error) {
+	inputValuesMu.Lock()
+	defer inputValuesMu.Unlock()
+	if value, ok := inputValues[input.ID]; ok {
+		return value, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("server needs input %q (%s) but stdin is not a terminal", input.ID, input.Description)
+	}
+
+	promptMu.Lock()
+	defer promptMu.Unlock()
+	question := input.Description
+	if question == "" {
+		question = input.ID
+	}
+	if len(input.Options) > 0 {
+		question += " (" + strings.Join(input.Options, "|") + ")"
+	}
+	if input.Default != "" && !input.Password {
+		question += " [" + input.Default + "]"
+	}
+	question += ": "
+
+	var value string
+	if input.Password {
+		fmt.Fprint(os.Stderr, question)
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input %q: %w", input.ID, err)
+		}
+		value = string(data)
+	} else {
+		line, err := promptLine(question)
+		if err != nil {
+			return "", err
+		}
+		value = line
+	}
+	if value == "" {
+		value = input.Default
+	}
+
+	inputValues[input.ID] = value
+	return value, nil
+}
+
+// readJSONCFile reads a JSON-with-comments file such as VS Code's settings.json.
+// A missing file is reported as not found rather than as an error.
+func readJSONCFile(path string, v interface{}) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := json.Unmarshal(stripJSONC(data), v); err != nil {
+		return false, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return true, nil
+}
+
+// stripJSONC removes // and /* */ comments and trailing commas, leaving strings intact
+func stripJSONC(data []byte) []byte {
+	return scanJSONC(scanJSONC(data, false), true)
+}
+
+// scanJSONC copies data outside of strings while dropping comments, or with
+// commas set, dropping commas followed only by whitespace and a closing bracket
+func scanJSONC(data []byte, commas bool) []byte {
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			// Copy the whole string, including escaped quotes
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				i = len(data) - 1
+			}
+			out = append(out, data[start:i+1]...)
+		case !commas && c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case !commas && c == '/' && i+1 < len(data) && data[i+1] == '*':
+			for i += 2; i+1 < len(data) && !(data[i] == '*' && data[i+1] == '/'); i++ {
+			}
+			i++
+		case commas && c == ',':
+			j := i + 1
+			for j < len(data) && strings.ContainsRune(" \t\r\n", rune(data[j])) {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Surfaces a server can announce changes to with a list_changed notification
+const (
+	surfaceTools     = "tools"
+	surfaceResources = "resources"
+	surfacePrompts   = "prompts"
+)
+
+// WatchEvent is one observed change to a server's surface
+type WatchEvent struct {
+	Time    time.Time   `json:"time"`
+	Surface string      `json:"surface"`
+	Tools   *DiffResult `json:"tools,omitempty"`
+	Added   []string    `json:"added,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+}
+
+// Empty reports whether the event carries no differences
+func (e *WatchEvent) Empty() bool {
+	if e.Tools != nil {
+		return e.Tools.Empty()
+	}
+	return len(e.Added) == 0 && len(e.Removed) == 0
+}
+
+// watchState is the last observed surface of a watched server
+type watchState struct {
+	tools     *InspectResult
+	resources []string
+	prompts   []string
+}
+
+func newWatchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "watch <server-name>",
+		Short: "Keep a connection open and report changes to a server's surface",
+		Long: `Connect to a server and keep the session open, listening for the
+notifications/{tools,resources,prompts}/list_changed notifications. Each time
+one arrives the affected list is fetched again and a timestamped diff is
+printed. With --output json each change is written as a JSON object.
+
+Press Ctrl+C to stop watching.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(configPath)
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			// A dropped connection is a result, not a usage error
+			cmd.SilenceUsage = true
+			return watchServer(config, args[0])
+		},
+	}
+}
+
+func watchServer(config *ClaudeConfig, serverName string) error {
+	// The connection lives until interrupted, so only individual requests get a timeout
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	conn, err := openConnection(ctx, config, serverName)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	capabilities := conn.Capabilities()
+	surfaces := []string{}
+	for _, surface := range []string{surfaceTools, surfaceResources, surfacePrompts} {
+		capability, ok := capabilities[surface].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		surfaces = append(surfaces, surface)
+		if listChanged, _ := capability["listChanged"].(bool); !listChanged {
+			fmt.Fprintf(os.Stderr, "Warning: %s does not advertise %s.listChanged; changes may not be reported\n", serverName, surface)
+		}
+	}
+
+	changed := make(chan string, 16)
+	for _, surface := range surfaces {
+		surface := surface
+		conn.Session.OnNotification("notifications/"+surface+"/list_changed", func(json.RawMessage) {
+			select {
+			case changed <- surface:
+			default:
+			}
+		})
+	}
+
+	state := &watchState{}
+	for _, surface := range surfaces {
+		if _, err := refreshSurface(ctx, config, conn, state, surface); err != nil {
+			return err
+		}
+	}
+
+	toolCount := 0
+	if state.tools != nil {
+		toolCount = len(state.tools.Tools)
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s (%d tools, %d resources, %d prompts). Press Ctrl+C to stop.\n",
+		serverName, toolCount, len(state.resources), len(state.prompts))
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-conn.Session.Done():
+			return fmt.Errorf("connection to %s closed", serverName)
+		case surface := <-changed:
+			event, err := refreshSurface(ctx, config, conn, state, surface)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Fprintf(os.Stderr, "Error refreshing %s: %v\n", surface, err)
+				continue
+			}
+			if err := printWatchEvent(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// refreshSurface fetches one surface again, records it in state and returns
+// how it differs from what was seen before
+func refreshSurface(ctx context.Context, config *ClaudeConfig, conn *Connection, state *watchState, surface string) (*WatchEvent, error) {
+	event := &WatchEvent{Time: time.Now(), Surface: surface}
+	switch surface {
+	case surfaceTools:
+		tools, err := collectInspection(ctx, config, conn)
+		if err != nil {
+			return nil, err
+		}
+		if state.tools != nil {
+			event.Tools = diffInspections(state.tools, tools)
+		}
+		state.tools = tools
+
+	case surfaceResources:
+		resources, err := conn.Client.ListResources(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+		uris := make([]string, 0, len(resources.Resources))
+		for _, resource := range resources.Resources {
+			uris = append(uris, resource.Uri)
+		}
+		event.Added, event.Removed = diffNames(state.resources, uris)
+		state.resources = uris
+
+	case surfacePrompts:
+		prompts, err := conn.Client.ListPrompts(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list prompts: %w", err)
+		}
+		names := make([]string, 0, len(prompts.Prompts))
+		for _, prompt := range prompts.Prompts {
+			names = append(names, prompt.Name)
+		}
+		event.Added, event.Removed = diffNames(state.prompts, names)
+		state.prompts = names
+	}
+
+	return event, nil
+}
+
+// diffNames returns the sorted names only in newNames and only in oldNames
+func diffNames(oldNames, newNames []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(oldNames))
+	for _, name := range oldNames {
+		oldSet[name] = true
+	}
+	newSet := make(map[string]bool, len(newNames))
+	for _, name := range newNames {
+		newSet[name] = true
+		if !oldSet[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range oldNames {
+		if !newSet[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+func printWatchEvent(event *WatchEvent) error {
+	if outputFormat == outputJSON {
+		return writeJSON(event)
+	}
+
+	timestamp := event.Time.Format("15:04:05")
+	if event.Empty() {
+		fmt.Printf("[%s] %s list changed (no differences)\n", timestamp, event.Surface)
+		return nil
+	}
+
+	fmt.Printf("[%s] %s list changed\n", timestamp, event.Surface)
+	if event.Tools != nil {
+		printToolChanges(os.Stdout, event.Tools)
+	}
+	for _, name := range event.Added {
+		fmt.Println(paint("+ "+name, ansiGreen))
+	}
+	for _, name := range event.Removed {
+		fmt.Println(paint("- "+name, ansiRed))
+	}
+	fmt.Println()
+	return nil
+}
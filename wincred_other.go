@@ -0,0 +1,13 @@
+//go:build !windows
+
+package main
+
+// loadWincredCredentials only exists on Windows
+func loadWincredCredentials() (*MCPCredentials, error) {
+	return nil, errNoCredentials
+}
+
+// saveWincredCredentials only exists on Windows
+func saveWincredCredentials(creds *MCPCredentials) error {
+	return errNoCredentials
+}
@@ -0,0 +1,118 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32      = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead  = advapi32.NewProc("CredReadW")
+	procCredWrite = advapi32.NewProc("CredWriteW")
+	procCredFree  = advapi32.NewProc("CredFree")
+)
+
+// Credential Manager constants from wincred.h
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+	credMaxBlobSize         = 5 * 512
+	errorNotFound           = syscall.Errno(1168)
+)
+
+// winCredential mirrors CREDENTIALW
+type winCredential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// wincredTargets are the names Claude Code's generic credential may have:
+// the service alone, or service/account as keytar writes it
+func wincredTargets() []string {
+	return []string{keychainService, keychainService + "/" + credentialAccount()}
+}
+
+// loadWincredCredentials reads Claude Code's credentials from the Windows Credential Manager
+func loadWincredCredentials() (*MCPCredentials, error) {
+	for _, target := range wincredTargets() {
+		blob, err := credRead(target)
+		if errors.Is(err, errorNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read credential %s: %w", target, err)
+		}
+		return parseCredentials(blob)
+	}
+	return nil, errNoCredentials
+}
+
+// saveWincredCredentials replaces Claude Code's generic credential, under
+// the target name it already has
+func saveWincredCredentials(creds *MCPCredentials) error {
+	data, err := creds.encode()
+	if err != nil {
+		return err
+	}
+	if len(data) > credMaxBlobSize {
+		return fmt.Errorf("failed to update Credential Manager: credentials are %d bytes, the limit is %d", len(data), credMaxBlobSize)
+	}
+
+	targets := wincredTargets()
+	target := targets[0]
+	for _, candidate := range targets {
+		if _, err := credRead(candidate); err == nil {
+			target = candidate
+			break
+		}
+	}
+	targetName, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return err
+	}
+	userName, err := syscall.UTF16PtrFromString(credentialAccount())
+	if err != nil {
+		return err
+	}
+	cred := winCredential{
+		Type:               credTypeGeneric,
+		TargetName:         targetName,
+		CredentialBlobSize: uint32(len(data)),
+		CredentialBlob:     &data[0],
+		Persist:            credPersistLocalMachine,
+		UserName:           userName,
+	}
+	if ret, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return fmt.Errorf("failed to update Credential Manager: %w", err)
+	}
+	return nil
+}
+
+// credRead returns the blob of a generic credential
+func credRead(target string) ([]byte, error) {
+	targetName, err := syscall.UTF16PtrFromString(target)
+	if err != nil {
+		return nil, err
+	}
+	var cred *winCredential
+	ret, _, err := procCredRead.Call(uintptr(unsafe.Pointer(targetName)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ret == 0 {
+		return nil, err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	return append([]byte(nil), unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)...), nil
+}